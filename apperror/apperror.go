@@ -0,0 +1,156 @@
+// Package apperror holds the errors the service reports to its clients.
+// Every error has a kind, which decides the HTTP status, and a stable code
+// clients can switch on instead of matching messages. Messages are meant for
+// people and may change.
+package apperror
+
+import "errors"
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	// KindBadRequest is a request that cannot be read, e.g. malformed JSON
+	KindBadRequest
+	// KindValidation is a readable request with invalid values
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	// KindConflict is a request the current state of a resource does not
+	// allow, e.g. a duplicate email or paying a cancelled booking
+	KindConflict
+	KindTooLarge
+	// KindUnprocessable is a valid request that does not apply, e.g. a
+	// ticket scanned at the wrong destination
+	KindUnprocessable
+	// KindTooManyRequests is a client going over a rate limit or locked out
+	// after repeated failures
+	KindTooManyRequests
+	// KindUpstream is a failure of a service we depend on, e.g. the payment
+	// gateway
+	KindUpstream
+	KindUnavailable
+)
+
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+
+	// Fields maps request fields to what is wrong with them
+	Fields map[string]string
+
+	// Err is the cause, it is logged but never shown to clients
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors of the same kind and code, so a copy made by Wrap or
+// WithField still matches the error it was made from
+func (e *Error) Is(target error) bool {
+	var other *Error
+
+	return errors.As(target, &other) && other.Kind == e.Kind && other.Code == e.Code
+}
+
+// Wrap returns a copy of e caused by err
+func (e *Error) Wrap(err error) *Error {
+	copied := *e
+	copied.Err = err
+
+	return &copied
+}
+
+// WithMessage returns a copy of e with another message
+func (e *Error) WithMessage(message string) *Error {
+	copied := *e
+	copied.Message = message
+
+	return &copied
+}
+
+// WithField returns a copy of e reporting what is wrong with a field
+func (e *Error) WithField(field string, message string) *Error {
+	copied := *e
+	copied.Fields = make(map[string]string, len(e.Fields)+1)
+
+	for name, fieldMessage := range e.Fields {
+		copied.Fields[name] = fieldMessage
+	}
+
+	copied.Fields[field] = message
+
+	return &copied
+}
+
+func New(kind Kind, code string, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func BadRequest(code string, message string) *Error {
+	return New(KindBadRequest, code, message)
+}
+
+func Validation(code string, message string) *Error {
+	return New(KindValidation, code, message)
+}
+
+func Unauthorized(code string, message string) *Error {
+	return New(KindUnauthorized, code, message)
+}
+
+func Forbidden(code string, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+func NotFound(code string, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+func Conflict(code string, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+func TooLarge(code string, message string) *Error {
+	return New(KindTooLarge, code, message)
+}
+
+func Unprocessable(code string, message string) *Error {
+	return New(KindUnprocessable, code, message)
+}
+
+func TooManyRequests(code string, message string) *Error {
+	return New(KindTooManyRequests, code, message)
+}
+
+func Upstream(code string, message string) *Error {
+	return New(KindUpstream, code, message)
+}
+
+// Internal reports an unexpected failure. Its cause is logged and clients
+// only learn that something went wrong.
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Code: "internal_error", Message: "Internal Server Error", Err: err}
+}
+
+// From returns the *Error in err's chain, or an internal error caused by err
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	return Internal(err)
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"bufio"
+	_ "embed"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+//go:embed breached_passwords.txt
+var builtinBreachedPasswords string
+
+var breachedPasswords = struct {
+	sync.RWMutex
+	passwords map[string]struct{}
+}{
+	passwords: make(map[string]struct{}),
+}
+
+func init() {
+	addBreachedPasswords(strings.NewReader(builtinBreachedPasswords))
+}
+
+// LoadBreachedPasswords adds the passwords listed in a file, one per line, to
+// the ones refused by IsBreachedPassword. Blank lines and lines starting with
+// # are skipped.
+func LoadBreachedPasswords(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	return addBreachedPasswords(file)
+}
+
+func addBreachedPasswords(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+
+	breachedPasswords.Lock()
+	defer breachedPasswords.Unlock()
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		breachedPasswords.passwords[strings.ToLower(line)] = struct{}{}
+	}
+
+	return scanner.Err()
+}
+
+// IsBreachedPassword reports whether a password is too common to be used.
+// The comparison ignores case, "Password1" is as guessable as "password1".
+func IsBreachedPassword(password string) bool {
+	breachedPasswords.RLock()
+	defer breachedPasswords.RUnlock()
+
+	_, found := breachedPasswords.passwords[strings.ToLower(password)]
+
+	return found
+}
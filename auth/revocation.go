@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// revocationCacheTTL bounds how long a "not revoked" answer is trusted before
+// asking the database again. Revocations made by this process are visible
+// immediately, revocations made by other instances after at most this long.
+const revocationCacheTTL = 30 * time.Second
+
+// revocationCacheSize is the number of entries after which stale entries are
+// swept out of the cache
+const revocationCacheSize = 10000
+
+type revocationEntry struct {
+	revoked  bool
+	deadline time.Time
+}
+
+type generationEntry struct {
+	generation int
+	deadline   time.Time
+}
+
+var revocationCache = struct {
+	sync.RWMutex
+	tokens map[string]revocationEntry
+	users  map[int]generationEntry
+}{
+	tokens: make(map[string]revocationEntry),
+	users:  make(map[int]generationEntry),
+}
+
+// IsRevoked reports whether the token was revoked through logout, or issued
+// before its user logged out everywhere
+func IsRevoked(ctx context.Context, claims Claims) (bool, error) {
+	now := time.Now()
+
+	generation, err := userGeneration(ctx, claims.Subject, now)
+	if err != nil {
+		return false, err
+	}
+
+	if claims.Generation < generation {
+		return true, nil
+	}
+
+	revocationCache.RLock()
+	entry, ok := revocationCache.tokens[claims.TokenID]
+	revocationCache.RUnlock()
+
+	if ok && now.Before(entry.deadline) {
+		return entry.revoked, nil
+	}
+
+	revoked, err := tokens.IsRevoked(ctx, claims.TokenID)
+	if err != nil {
+		return false, err
+	}
+
+	entry = revocationEntry{revoked: revoked, deadline: now.Add(revocationCacheTTL)}
+	if revoked {
+		// A revoked token stays revoked, keep it until it expires anyway
+		entry.deadline = claims.ExpiresAt
+	}
+
+	cacheToken(claims.TokenID, entry, now)
+
+	return revoked, nil
+}
+
+// Revoke invalidates a single access token
+func Revoke(ctx context.Context, claims Claims) error {
+	if err := tokens.Revoke(ctx, claims.TokenID, claims.Subject, claims.ExpiresAt); err != nil {
+		return err
+	}
+
+	cacheToken(claims.TokenID, revocationEntry{revoked: true, deadline: claims.ExpiresAt}, time.Now())
+
+	return nil
+}
+
+// RevokeAll invalidates every token issued to the user so far. It moves
+// the user on to a new token generation rather than recording a time, so a
+// login right after it is never caught by it.
+func RevokeAll(ctx context.Context, userID int) error {
+	if err := tokens.RevokeAll(ctx, userID); err != nil {
+		return err
+	}
+
+	revocationCache.Lock()
+	delete(revocationCache.users, userID)
+	revocationCache.Unlock()
+
+	return nil
+}
+
+func userGeneration(ctx context.Context, userID int, now time.Time) (int, error) {
+	revocationCache.RLock()
+	entry, ok := revocationCache.users[userID]
+	revocationCache.RUnlock()
+
+	if ok && now.Before(entry.deadline) {
+		return entry.generation, nil
+	}
+
+	generation, err := tokens.Generation(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	revocationCache.Lock()
+	if len(revocationCache.users) >= revocationCacheSize {
+		for id, e := range revocationCache.users {
+			if now.After(e.deadline) {
+				delete(revocationCache.users, id)
+			}
+		}
+	}
+	revocationCache.users[userID] = generationEntry{generation: generation, deadline: now.Add(revocationCacheTTL)}
+	revocationCache.Unlock()
+
+	return generation, nil
+}
+
+func cacheToken(jti string, entry revocationEntry, now time.Time) {
+	revocationCache.Lock()
+	defer revocationCache.Unlock()
+
+	if len(revocationCache.tokens) >= revocationCacheSize {
+		for id, e := range revocationCache.tokens {
+			if now.After(e.deadline) {
+				delete(revocationCache.tokens, id)
+			}
+		}
+	}
+
+	revocationCache.tokens[jti] = entry
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bryansamperura/ticket-booking/repository"
+)
+
+func TestRevokeAllKeepsLaterTokensValid(t *testing.T) {
+	ctx := context.Background()
+
+	Init(repository.NewMemory().Tokens)
+
+	_, before, err := GenerateAccessToken(ctx, 1, 10, "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = RevokeAll(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Issued within the same second as the revocation
+	_, after, err := GenerateAccessToken(ctx, 1, 10, "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, other, err := GenerateAccessToken(ctx, 2, 20, "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		claims  Claims
+		revoked bool
+	}{
+		{"issued before", before, true},
+		{"issued after", after, false},
+		{"other user", other, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revoked, err := IsRevoked(ctx, tt.claims)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if revoked != tt.revoked {
+				t.Errorf("IsRevoked() = %v, want %v", revoked, tt.revoked)
+			}
+		})
+	}
+}
+
+func TestGenerationSurvivesTheToken(t *testing.T) {
+	ctx := context.Background()
+
+	tokenRepository := repository.NewMemory().Tokens
+	Init(tokenRepository)
+
+	if err := tokenRepository.RevokeAll(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	token, _, err := GenerateAccessToken(ctx, 3, 30, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := ParseAccessToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if claims.Generation != 1 {
+		t.Errorf("Generation = %d, want 1", claims.Generation)
+	}
+}
@@ -0,0 +1,233 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/config"
+	"github.com/bryansamperura/ticket-booking/helper"
+	"github.com/bryansamperura/ticket-booking/repository"
+	"github.com/o1egl/paseto"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims are the values carried by an access token
+type Claims struct {
+	TokenID   string    `json:"jti"`
+	Subject   int       `json:"sub"`
+	UserID    int       `json:"uid"`
+	Role      string    `json:"role"`
+	IssuedAt  time.Time `json:"iat"`
+	NotBefore time.Time `json:"nbf"`
+	ExpiresAt time.Time `json:"exp"`
+
+	// Generation is the token generation of the user when the token was
+	// issued, tokens of older generations are revoked by RevokeAll
+	Generation int `json:"gen"`
+}
+
+var (
+	// tokens stores the revocations
+	tokens repository.TokenRepository
+
+	activeKey       []byte
+	acceptedKeys    [][]byte
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+)
+
+// Init loads the token keys from the configuration and keeps revocations
+// in the given repository
+func Init(tokenRepository repository.TokenRepository) {
+	conf := config.GetConfig()
+
+	tokens = tokenRepository
+
+	err := validateKey(conf.Auth.TokenKey)
+	helper.PanicIfError(err)
+
+	activeKey = []byte(conf.Auth.TokenKey)
+	acceptedKeys = [][]byte{activeKey}
+
+	for _, key := range conf.Auth.OldTokenKeys {
+		err = validateKey(key)
+		helper.PanicIfError(err)
+
+		acceptedKeys = append(acceptedKeys, []byte(key))
+	}
+
+	accessTokenTTL = conf.Auth.AccessTokenTTL.Duration
+	refreshTokenTTL = conf.Auth.RefreshTokenTTL.Duration
+
+	if conf.Auth.BreachedPasswordsFile != "" {
+		err = LoadBreachedPasswords(conf.Auth.BreachedPasswordsFile)
+		helper.PanicIfError(err)
+	}
+}
+
+func validateKey(key string) error {
+	if len(key) != 32 {
+		return fmt.Errorf("token key must be exactly 32 bytes, got %d", len(key))
+	}
+
+	return nil
+}
+
+// AccessTokenTTL returns how long a newly issued access token is valid
+func AccessTokenTTL() time.Duration {
+	return accessTokenTTL
+}
+
+// RefreshTokenTTL returns how long a newly issued refresh token is valid
+func RefreshTokenTTL() time.Duration {
+	return refreshTokenTTL
+}
+
+// GenerateAccessToken issues a short-lived access token for the user with
+// the given users.id, account id and role, signed with the active key
+func GenerateAccessToken(ctx context.Context, subject int, userID int, role string) (string, Claims, error) {
+	now := time.Now().Truncate(time.Second)
+
+	jti, err := randomString(16)
+	if err != nil {
+		return "", Claims{}, err
+	}
+
+	// Read past the cache, a generation cached before another instance
+	// revoked the user's tokens would issue a token that is revoked already
+	generation, err := tokens.Generation(ctx, subject)
+	if err != nil {
+		return "", Claims{}, err
+	}
+
+	claims := Claims{
+		TokenID:    jti,
+		Subject:    subject,
+		UserID:     userID,
+		Role:       role,
+		Generation: generation,
+		IssuedAt:   now,
+		NotBefore:  now,
+		ExpiresAt:  now.Add(accessTokenTTL),
+	}
+
+	jsonToken := paseto.JSONToken{
+		Jti:        claims.TokenID,
+		Subject:    strconv.Itoa(claims.Subject),
+		IssuedAt:   claims.IssuedAt,
+		NotBefore:  claims.NotBefore,
+		Expiration: claims.ExpiresAt,
+	}
+	jsonToken.Set("uid", strconv.Itoa(claims.UserID))
+	jsonToken.Set("role", claims.Role)
+	jsonToken.Set("gen", strconv.Itoa(claims.Generation))
+
+	token, err := paseto.NewV2().Encrypt(activeKey, jsonToken, nil)
+	if err != nil {
+		return "", Claims{}, err
+	}
+
+	return token, claims, nil
+}
+
+// ParseAccessToken decrypts the token with the active or any of the old keys
+// and checks its iat, nbf and exp claims
+func ParseAccessToken(token string) (Claims, error) {
+	var jsonToken paseto.JSONToken
+
+	decrypted := false
+
+	for _, key := range acceptedKeys {
+		if err := paseto.NewV2().Decrypt(token, key, &jsonToken, nil); err == nil {
+			decrypted = true
+			break
+		}
+	}
+
+	if !decrypted {
+		return Claims{}, ErrInvalidToken
+	}
+
+	// Tokens without an expiry were issued before expiring tokens were
+	// introduced and are no longer accepted
+	if jsonToken.Jti == "" || jsonToken.Expiration.IsZero() {
+		return Claims{}, ErrInvalidToken
+	}
+
+	if err := jsonToken.Validate(paseto.ValidAt(time.Now())); err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+
+	subject, err := strconv.Atoi(jsonToken.Subject)
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+
+	userID, err := strconv.Atoi(jsonToken.Get("uid"))
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+
+	// Tokens issued before generations were introduced have none and count
+	// as generation 0
+	generation, _ := strconv.Atoi(jsonToken.Get("gen"))
+
+	return Claims{
+		TokenID:    jsonToken.Jti,
+		Subject:    subject,
+		UserID:     userID,
+		Role:       jsonToken.Get("role"),
+		Generation: generation,
+		IssuedAt:   jsonToken.IssuedAt,
+		NotBefore:  jsonToken.NotBefore,
+		ExpiresAt:  jsonToken.Expiration,
+	}, nil
+}
+
+// GenerateRefreshToken returns a new opaque refresh token together with the
+// hash that is persisted in the database
+func GenerateRefreshToken() (string, string, error) {
+	token, err := randomString(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	return token, HashToken(token), nil
+}
+
+// GenerateAccountToken returns a single-use token to mail to the owner of
+// an account, e.g. in a password reset link, and its hash for storage
+func GenerateAccountToken() (string, string, error) {
+	token, err := randomString(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	return token, HashToken(token), nil
+}
+
+// HashToken hashes an opaque token for storage so a database leak does not
+// expose usable tokens
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -1,19 +1,272 @@
+// Package config loads the service configuration. Values are layered, each
+// source overriding the previous one:
+//
+//  1. built-in defaults of the selected profile (dev, staging or prod)
+//  2. the config file, config/config.json, then config/config.<profile>.json
+//  3. PEPPERWAVE_* environment variables, e.g. PEPPERWAVE_DATABASE_PASSWORD
+//  4. command line flags, e.g. -set server.port=8080
+//
+// The result is validated once at startup so a misconfigured service fails
+// fast instead of on the first request that needs a setting.
 package config
 
-import "github.com/tkanos/gonfig"
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	ProfileDev     = "dev"
+	ProfileStaging = "staging"
+	ProfileProd    = "prod"
+)
 
 type Configuration struct {
-	DB_USERNAME string
-	DB_PASSWORD string
-	DB_HOST     string
-	DB_PORT     string
-	DB_NAME     string
+	// Profile is dev, staging or prod. It picks the defaults, the profile
+	// config file and how strict validation is.
+	Profile string `json:"-"`
+
+	Server    ServerConfig    `json:"server"`
+	Database  DatabaseConfig  `json:"database"`
+	Auth      AuthConfig      `json:"auth"`
+	Booking   BookingConfig   `json:"booking"`
+	Payment   PaymentConfig   `json:"payment"`
+	Tickets   TicketsConfig   `json:"tickets"`
+	Uploads   UploadsConfig   `json:"uploads"`
+	Documents DocumentsConfig `json:"documents"`
+	Mail      MailConfig      `json:"mail"`
+	CORS      CORSConfig      `json:"cors"`
+	Logging   LoggingConfig   `json:"logging"`
+}
+
+type ServerConfig struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+
+	ReadTimeout  Duration `json:"read_timeout"`
+	WriteTimeout Duration `json:"write_timeout"`
+	IdleTimeout  Duration `json:"idle_timeout"`
+
+	// ShutdownTimeout is how long in-flight requests may take to finish
+	// once the server is asked to stop
+	ShutdownTimeout Duration `json:"shutdown_timeout"`
+
+	TLS TLSConfig `json:"tls"`
+
+	// TrustedProxies are the CIDR ranges of the proxies in front of the
+	// service. Client IPs are read from X-Forwarded-For only when the
+	// request comes from one of them, otherwise the peer address is used.
+	TrustedProxies []string `json:"trusted_proxies"`
 }
 
+// Address is the address the HTTP server listens on
+func (s ServerConfig) Address() string {
+	return fmt.Sprintf("%s:%d", s.Host, s.Port)
+}
+
+type TLSConfig struct {
+	// CertFile and KeyFile are PEM files, HTTPS is served when both are set
+	CertFile string `json:"cert_file"`
+	KeyFile  string `json:"key_file"`
+}
+
+// Enabled tells whether the server should serve HTTPS
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != "" && t.KeyFile != ""
+}
+
+type DatabaseConfig struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Name     string `json:"name"`
+
+	// Location is the time zone DATETIME columns are read and written in,
+	// the session time zone is set to match so NOW() agrees with it
+	Location       string   `json:"location"`
+	ConnectTimeout Duration `json:"connect_timeout"`
+	ReadTimeout    Duration `json:"read_timeout"`
+	WriteTimeout   Duration `json:"write_timeout"`
+
+	MaxOpenConns    int      `json:"max_open_conns"`
+	MaxIdleConns    int      `json:"max_idle_conns"`
+	ConnMaxLifetime Duration `json:"conn_max_lifetime"`
+	ConnMaxIdleTime Duration `json:"conn_max_idle_time"`
+
+	// StartupTimeout is how long the first connection is retried while
+	// MySQL comes up, zero tries once
+	StartupTimeout Duration `json:"startup_timeout"`
+}
+
+type AuthConfig struct {
+	// TokenKey is the 32 byte symmetric key used to sign new tokens.
+	// OldTokenKeys are rotated-out keys that are still accepted when
+	// verifying tokens issued before the rotation.
+	TokenKey        string   `json:"token_key"`
+	OldTokenKeys    []string `json:"old_token_keys"`
+	AccessTokenTTL  Duration `json:"access_token_ttl"`
+	RefreshTokenTTL Duration `json:"refresh_token_ttl"`
+
+	// BreachedPasswordsFile lists passwords, one per line, that are refused
+	// on top of the built-in list of common ones
+	BreachedPasswordsFile string `json:"breached_passwords_file"`
+
+	// After LockoutThreshold failed logins in a row an email is locked for
+	// LockoutBase, doubling with every further failure up to LockoutMax.
+	// The count starts over once no login failed for LockoutWindow.
+	LockoutThreshold int      `json:"lockout_threshold"`
+	LockoutBase      Duration `json:"lockout_base"`
+	LockoutMax       Duration `json:"lockout_max"`
+	LockoutWindow    Duration `json:"lockout_window"`
+
+	// LoginRateLimit is how many login attempts a client IP may make per
+	// minute, after a burst of LoginBurst
+	LoginRateLimit int `json:"login_rate_limit"`
+	LoginBurst     int `json:"login_burst"`
+
+	// RequireVerifiedEmail refuses logins until the email is verified.
+	// Verification and password reset links expire after their TTL.
+	RequireVerifiedEmail bool     `json:"require_verified_email"`
+	EmailVerificationTTL Duration `json:"email_verification_ttl"`
+	PasswordResetTTL     Duration `json:"password_reset_ttl"`
+}
+
+type BookingConfig struct {
+	// TaxRateBps is the tax charged on bookings in basis points, e.g. 1100
+	// for 11%
+	TaxRateBps int64 `json:"tax_rate_bps"`
+
+	// HoldMinutes is how long a pending booking holds its tickets before it
+	// expires unpaid. ExpiryInterval is how often expired holds are released.
+	HoldMinutes    int      `json:"hold_minutes"`
+	ExpiryInterval Duration `json:"expiry_interval"`
+}
+
+type PaymentConfig struct {
+	// Provider is either "mock", which works offline, or "midtrans".
+	// ServerKey authenticates API calls and signs webhooks.
+	Provider  string `json:"provider"`
+	BaseURL   string `json:"base_url"`
+	ServerKey string `json:"server_key"`
+}
+
+type TicketsConfig struct {
+	// SigningKey signs the codes in e-ticket QR codes. Changing it
+	// invalidates every ticket issued so far.
+	SigningKey string `json:"signing_key"`
+}
+
+type UploadsConfig struct {
+	// Dir is where uploaded images are stored, they are served under
+	// /uploads whatever the directory is called
+	Dir       string `json:"dir"`
+	MaxSizeMB int64  `json:"max_size_mb"`
+}
+
+type DocumentsConfig struct {
+	// TemplateDir optionally holds invoice.json, ticket.json and the assets
+	// they refer to. Files missing from it, or all of them when it is empty,
+	// come from the templates built into the binary.
+	TemplateDir string `json:"template_dir"`
+}
+
+type MailConfig struct {
+	// Driver is "smtp", or "log" which writes messages to Dir and the log
+	// instead of sending them
+	Driver string `json:"driver"`
+	From   string `json:"from"`
+	Dir    string `json:"dir"`
+
+	// BaseURL starts the links sent in emails, e.g. https://tiket.example.com.
+	// It has no default outside dev.
+	BaseURL string `json:"base_url"`
+
+	// VerifyEmailPath and ResetPasswordPath follow BaseURL in the links,
+	// which carry the token as ?token=. A verification link can open
+	// GET /verify-email/confirm directly, a reset link has to open a page
+	// that asks for the new password and sends it to POST /password/reset.
+	VerifyEmailPath   string `json:"verify_email_path"`
+	ResetPasswordPath string `json:"reset_password_path"`
+
+	SMTP SMTPConfig `json:"smtp"`
+}
+
+type SMTPConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type CORSConfig struct {
+	AllowOrigins []string `json:"allow_origins"`
+}
+
+type LoggingConfig struct {
+	// Level is debug, info, warn or error, Format is text or json
+	Level  string `json:"level"`
+	Format string `json:"format"`
+}
+
+// Duration is a time.Duration written as "15m" or "720h" in config files and
+// environment variables
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	d.Duration = duration
+
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.Duration.String()), nil
+}
+
+var (
+	mu      sync.RWMutex
+	current *Configuration
+)
+
+// Init loads the configuration with the command line flags in args and makes
+// it the one returned by GetConfig. It returns the arguments left after the
+// flags, i.e. the sub-command and its own arguments.
+func Init(args []string) ([]string, error) {
+	conf, rest, err := Load(args)
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	current = &conf
+	mu.Unlock()
+
+	return rest, nil
+}
+
+// GetConfig returns the configuration loaded by Init. When Init has not been
+// called, e.g. in tests, the dev configuration is loaded without other flags
+// and the call panics if it is invalid.
 func GetConfig() Configuration {
-	conf := Configuration{}
+	mu.RLock()
+	conf := current
+	mu.RUnlock()
+
+	if conf != nil {
+		return *conf
+	}
 
-	gonfig.GetConf("config/config.json", &conf)
+	if _, err := Init([]string{"-profile", ProfileDev}); err != nil {
+		panic(err)
+	}
 
-	return conf
+	return GetConfig()
 }
@@ -0,0 +1,226 @@
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix starts the name of every environment variable read by Load
+const EnvPrefix = "PEPPERWAVE_"
+
+// DefaultFile is the config file read when the -config flag is not given
+const DefaultFile = "config/config.json"
+
+// settings collects the repeated -set key=value flags
+type settings []string
+
+func (s *settings) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *settings) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+
+	*s = append(*s, value)
+
+	return nil
+}
+
+// Load builds the configuration from the defaults, the config files, the
+// environment and the flags in args, then validates it. It returns the
+// arguments left after the flags.
+func Load(args []string) (Configuration, []string, error) {
+	var sets settings
+
+	flags := flag.NewFlagSet("ticket-booking", flag.ContinueOnError)
+	file := flags.String("config", DefaultFile, "config file, config.<profile>.json next to it is read too")
+	profile := flags.String("profile", "", "dev, staging or prod, defaults to "+EnvPrefix+"PROFILE")
+	port := flags.Int("port", 0, "port to listen on, same as -set server.port=PORT")
+	flags.Var(&sets, "set", "override a setting, e.g. -set database.host=db, may be repeated")
+
+	if err := flags.Parse(args); err != nil {
+		return Configuration{}, nil, err
+	}
+
+	explicitFile := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "config" {
+			explicitFile = true
+		}
+	})
+
+	if *profile == "" {
+		*profile = os.Getenv(EnvPrefix + "PROFILE")
+	}
+
+	// There is no default profile, so a deployment that forgot to set one
+	// does not silently run with the development settings
+	if *profile == "" {
+		return Configuration{}, nil, errors.New("no profile given, pass -profile dev, staging or prod or set " + EnvPrefix + "PROFILE")
+	}
+
+	conf, err := Defaults(*profile)
+	if err != nil {
+		return conf, nil, err
+	}
+
+	if err = readFile(&conf, *file, !explicitFile); err != nil {
+		return conf, nil, err
+	}
+
+	profileFile := filepath.Join(filepath.Dir(*file), "config."+*profile+".json")
+
+	if err = readFile(&conf, profileFile, true); err != nil {
+		return conf, nil, err
+	}
+
+	if err = applyEnv(&conf, os.LookupEnv); err != nil {
+		return conf, nil, err
+	}
+
+	if *port != 0 {
+		sets = append(sets, "server.port="+strconv.Itoa(*port))
+	}
+
+	for _, set := range sets {
+		key, value, _ := strings.Cut(set, "=")
+
+		if err = Set(&conf, key, value); err != nil {
+			return conf, nil, fmt.Errorf("-set %s: %w", key, err)
+		}
+	}
+
+	if err = conf.Validate(); err != nil {
+		return conf, nil, err
+	}
+
+	return conf, flags.Args(), nil
+}
+
+// readFile overlays the settings in a JSON config file onto conf. Settings
+// left out of the file keep their value. A missing file is only an error
+// when it is not optional.
+func readFile(conf *Configuration, path string, optional bool) error {
+	content, err := os.ReadFile(path)
+	if optional && errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+
+	if err = decoder.Decode(conf); err != nil && err != io.EOF {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	return nil
+}
+
+// applyEnv overrides every setting with a matching environment variable,
+// named after its path in the config file, e.g. PEPPERWAVE_AUTH_TOKEN_KEY
+// for auth.token_key. Lists are comma separated.
+func applyEnv(conf *Configuration, lookup func(string) (string, bool)) error {
+	var errs []error
+
+	walk(reflect.ValueOf(conf).Elem(), nil, func(path []string, field reflect.Value) {
+		name := EnvPrefix + strings.ToUpper(strings.Join(path, "_"))
+
+		if value, ok := lookup(name); ok {
+			if err := setValue(field, value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+		}
+	})
+
+	return errors.Join(errs...)
+}
+
+// Set changes the setting at a dotted path of the config file, e.g.
+// "server.port", parsing value as the environment variables are
+func Set(conf *Configuration, key string, value string) error {
+	found := false
+
+	var err error
+
+	walk(reflect.ValueOf(conf).Elem(), nil, func(path []string, field reflect.Value) {
+		if strings.Join(path, ".") == key {
+			found = true
+			err = setValue(field, value)
+		}
+	})
+
+	if !found {
+		return errors.New("unknown setting")
+	}
+
+	return err
+}
+
+// walk calls fn with every setting of a config section and its path
+func walk(section reflect.Value, path []string, fn func(path []string, field reflect.Value)) {
+	for i := 0; i < section.NumField(); i++ {
+		name, _, _ := strings.Cut(section.Type().Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		field := section.Field(i)
+		fieldPath := append(append([]string(nil), path...), name)
+
+		if field.Kind() == reflect.Struct && field.Type() != reflect.TypeOf(Duration{}) {
+			walk(field, fieldPath, fn)
+			continue
+		}
+
+		fn(fieldPath, field)
+	}
+}
+
+func setValue(field reflect.Value, value string) error {
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(value))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int64:
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", value)
+		}
+		field.SetInt(number)
+	case reflect.Bool:
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+		field.SetBool(boolean)
+	case reflect.Slice:
+		items := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("settings of type %s cannot be set", field.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,341 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfig writes a config file into dir and returns its path
+func writeConfig(t *testing.T, dir string, name string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestDefaults(t *testing.T) {
+	dev, err := Defaults(ProfileDev)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = dev.Validate(); err != nil {
+		t.Errorf("dev defaults Validate() = %v, want nil", err)
+	}
+
+	if dev.Payment.Provider != "mock" || dev.Mail.Driver != "log" {
+		t.Errorf("dev defaults use payment %q and mail %q, want mock and log", dev.Payment.Provider, dev.Mail.Driver)
+	}
+
+	again, err := Defaults(ProfileDev)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dev.Auth.TokenKey == again.Auth.TokenKey || dev.Tickets.SigningKey == again.Tickets.SigningKey {
+		t.Error("dev defaults share their secrets between runs, want random secrets")
+	}
+
+	prod, err := Defaults(ProfileProd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = prod.Validate()
+	if err == nil || !strings.Contains(err.Error(), "auth.token_key") || !strings.Contains(err.Error(), "database.password is required in prod") {
+		t.Errorf("prod defaults Validate() = %v, want missing secrets reported", err)
+	}
+
+	if _, err = Defaults("qa"); err == nil {
+		t.Error(`Defaults("qa") error = nil, want unknown profile`)
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		check   func(conf Configuration) bool
+		wantErr string
+	}{
+		{
+			name:  "int",
+			env:   map[string]string{"PEPPERWAVE_SERVER_PORT": "8080"},
+			check: func(conf Configuration) bool { return conf.Server.Port == 8080 },
+		},
+		{
+			name:  "nested section",
+			env:   map[string]string{"PEPPERWAVE_MAIL_SMTP_HOST": "smtp.example.com"},
+			check: func(conf Configuration) bool { return conf.Mail.SMTP.Host == "smtp.example.com" },
+		},
+		{
+			name: "comma separated list",
+			env:  map[string]string{"PEPPERWAVE_CORS_ALLOW_ORIGINS": "https://a.example.com, https://b.example.com,"},
+			check: func(conf Configuration) bool {
+				return reflect.DeepEqual(conf.CORS.AllowOrigins, []string{"https://a.example.com", "https://b.example.com"})
+			},
+		},
+		{
+			name:  "duration",
+			env:   map[string]string{"PEPPERWAVE_AUTH_ACCESS_TOKEN_TTL": "5m"},
+			check: func(conf Configuration) bool { return conf.Auth.AccessTokenTTL.Duration == 5*time.Minute },
+		},
+		{
+			name:  "bool",
+			env:   map[string]string{"PEPPERWAVE_AUTH_REQUIRE_VERIFIED_EMAIL": "false"},
+			check: func(conf Configuration) bool { return !conf.Auth.RequireVerifiedEmail },
+		},
+		{
+			name:    "not a number",
+			env:     map[string]string{"PEPPERWAVE_SERVER_PORT": "http"},
+			wantErr: "PEPPERWAVE_SERVER_PORT",
+		},
+		{
+			name:    "every bad variable is reported",
+			env:     map[string]string{"PEPPERWAVE_SERVER_PORT": "http", "PEPPERWAVE_BOOKING_EXPIRY_INTERVAL": "soon"},
+			wantErr: "PEPPERWAVE_BOOKING_EXPIRY_INTERVAL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf, err := Defaults(ProfileDev)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = applyEnv(&conf, func(name string) (string, bool) {
+				value, ok := tt.env[name]
+				return value, ok
+			})
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("applyEnv() error = %v, want it to name %s", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.check(conf) {
+				t.Errorf("applyEnv() did not apply %v", tt.env)
+			}
+		})
+	}
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		check   func(conf Configuration) bool
+		wantErr string
+	}{
+		{key: "server.port", value: "9000", check: func(conf Configuration) bool { return conf.Server.Port == 9000 }},
+		{key: "database.host", value: "db", check: func(conf Configuration) bool { return conf.Database.Host == "db" }},
+		{key: "booking.expiry_interval", value: "30s", check: func(conf Configuration) bool { return conf.Booking.ExpiryInterval.Duration == 30*time.Second }},
+		{key: "auth.require_verified_email", value: "false", check: func(conf Configuration) bool { return !conf.Auth.RequireVerifiedEmail }},
+		{key: "server.trusted_proxies", value: "10.0.0.0/8", check: func(conf Configuration) bool {
+			return reflect.DeepEqual(conf.Server.TrustedProxies, []string{"10.0.0.0/8"})
+		}},
+		{key: "server.prot", value: "9000", wantErr: "unknown setting"},
+		{key: "server", value: "9000", wantErr: "unknown setting"},
+		{key: "server.port", value: "nine", wantErr: "not a whole number"},
+		{key: "auth.require_verified_email", value: "sometimes", wantErr: "not true or false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			conf, err := Defaults(ProfileDev)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = Set(&conf, tt.key, tt.value)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Set() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.check(conf) {
+				t.Errorf("Set(%q, %q) was not applied", tt.key, tt.value)
+			}
+		})
+	}
+}
+
+func TestLoadLayering(t *testing.T) {
+	dir := t.TempDir()
+	file := writeConfig(t, dir, "config.json", `{
+		"server": {"port": 4000, "host": "file"},
+		"database": {"name": "from_file", "host": "file", "username": "file"}
+	}`)
+	writeConfig(t, dir, "config.dev.json", `{"database": {"name": "from_profile_file", "host": "profile_file"}}`)
+
+	t.Setenv(EnvPrefix+"PROFILE", ProfileDev)
+	t.Setenv(EnvPrefix+"DATABASE_HOST", "env")
+	t.Setenv(EnvPrefix+"SERVER_PORT", "5000")
+	t.Setenv(EnvPrefix+"SERVER_HOST", "env")
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantPort int
+		wantRest []string
+	}{
+		{name: "environment over the files", args: []string{"-config", file}, wantPort: 5000},
+		{name: "-set over the environment", args: []string{"-config", file, "-set", "server.port=6000"}, wantPort: 6000},
+		{name: "-port over -set", args: []string{"-config", file, "-port", "7000", "-set", "server.port=6000"}, wantPort: 7000},
+		{name: "arguments after the flags", args: []string{"-config", file, "migrate", "up"}, wantPort: 5000, wantRest: []string{"migrate", "up"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf, rest, err := Load(tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if conf.Profile != ProfileDev {
+				t.Errorf("Profile = %q, want %q", conf.Profile, ProfileDev)
+			}
+
+			if conf.Server.Port != tt.wantPort {
+				t.Errorf("Server.Port = %d, want %d", conf.Server.Port, tt.wantPort)
+			}
+
+			if conf.Database.Username != "file" || conf.Database.Name != "from_profile_file" || conf.Database.Host != "env" {
+				t.Errorf("Database = %s@%s/%s, want file@env/from_profile_file", conf.Database.Username, conf.Database.Host, conf.Database.Name)
+			}
+
+			if conf.Server.Host != "env" {
+				t.Errorf("Server.Host = %q, want env", conf.Server.Host)
+			}
+
+			if conf.Database.Port != 3306 {
+				t.Errorf("Database.Port = %d, want the default 3306", conf.Database.Port)
+			}
+
+			if len(rest) != len(tt.wantRest) || (len(rest) > 0 && !reflect.DeepEqual(rest, tt.wantRest)) {
+				t.Errorf("rest = %v, want %v", rest, tt.wantRest)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	valid := writeConfig(t, dir, "config.json", `{"server": {"port": 4000}}`)
+	unknownField := writeConfig(t, dir, "unknown.json", `{"server": {"prot": 4000}}`)
+	malformed := writeConfig(t, dir, "malformed.json", `{"server": `)
+
+	t.Setenv(EnvPrefix+"PROFILE", ProfileDev)
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "unknown field in the file", args: []string{"-config", unknownField}, wantErr: `unknown field "prot"`},
+		{name: "malformed file", args: []string{"-config", malformed}, wantErr: "malformed.json"},
+		{name: "missing explicit file", args: []string{"-config", filepath.Join(dir, "missing.json")}, wantErr: "missing.json"},
+		{name: "unknown profile", args: []string{"-config", valid, "-profile", "qa"}, wantErr: "unknown profile"},
+		{name: "unknown -set key", args: []string{"-config", valid, "-set", "server.prot=1"}, wantErr: "-set server.prot: unknown setting"},
+		{name: "-set without a value", args: []string{"-config", valid, "-set", "server.port"}, wantErr: "expected key=value"},
+		{name: "invalid result", args: []string{"-config", valid, "-set", "database.location=Mars/Olympus_Mons"}, wantErr: "database.location must be a time zone"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Load(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadProfileFromEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	file := writeConfig(t, dir, "config.json", `{}`)
+
+	t.Setenv(EnvPrefix+"PROFILE", ProfileProd)
+
+	_, _, err := Load([]string{"-config", file})
+	if err == nil || !strings.Contains(err.Error(), "invalid prod configuration") {
+		t.Errorf("Load() error = %v, want the prod defaults rejected", err)
+	}
+}
+
+func TestLoadRequiresProfile(t *testing.T) {
+	dir := t.TempDir()
+	file := writeConfig(t, dir, "config.json", `{}`)
+
+	t.Setenv(EnvPrefix+"PROFILE", "")
+
+	_, _, err := Load([]string{"-config", file})
+	if err == nil || !strings.Contains(err.Error(), "no profile given") {
+		t.Errorf("Load() error = %v, want the missing profile reported", err)
+	}
+}
+
+func TestMockProviderOnlyInDev(t *testing.T) {
+	for _, profile := range []string{ProfileStaging, ProfileProd} {
+		t.Run(profile, func(t *testing.T) {
+			conf, err := Defaults(profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			conf.Payment.Provider = "mock"
+
+			err = conf.Validate()
+			if err == nil || !strings.Contains(err.Error(), "payment.provider can only be mock in dev") {
+				t.Errorf("Validate() = %v, want the mock provider refused", err)
+			}
+		})
+	}
+}
+
+func TestMailBaseURLRequiredOutsideDev(t *testing.T) {
+	for _, profile := range []string{ProfileStaging, ProfileProd} {
+		t.Run(profile, func(t *testing.T) {
+			conf, err := Defaults(profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = conf.Validate()
+			if err == nil || !strings.Contains(err.Error(), "mail.base_url is required") {
+				t.Errorf("Validate() = %v, want the missing mail.base_url reported", err)
+			}
+
+			conf.Mail.BaseURL = "https://tiket.example.com"
+
+			err = conf.Validate()
+			if err != nil && strings.Contains(err.Error(), "mail.base_url") {
+				t.Errorf("Validate() = %v, want mail.base_url accepted", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"os"
+	"strings"
+	"time"
+
+	// database.location must resolve in containers without zoneinfo
+	_ "time/tzdata"
+)
+
+// Defaults returns the built-in configuration of a profile. Staging and
+// production have no secrets, they must come from the config file or the
+// environment. Development gets random secrets on every start, so tokens and
+// tickets from an earlier run stop working unless auth.token_key and
+// tickets.signing_key are set in config.dev.json.
+func Defaults(profile string) (Configuration, error) {
+	conf := Configuration{
+		Profile: profile,
+		Server: ServerConfig{
+			Port:            3000,
+			ReadTimeout:     Duration{15 * time.Second},
+			WriteTimeout:    Duration{30 * time.Second},
+			IdleTimeout:     Duration{time.Minute},
+			ShutdownTimeout: Duration{20 * time.Second},
+			TrustedProxies:  []string{},
+		},
+		Database: DatabaseConfig{
+			Username: "root",
+			Host:     "127.0.0.1",
+			Port:     3306,
+			Name:     "ticket_booking",
+
+			Location:       "Asia/Jayapura",
+			ConnectTimeout: Duration{5 * time.Second},
+			ReadTimeout:    Duration{30 * time.Second},
+			WriteTimeout:   Duration{30 * time.Second},
+
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: Duration{5 * time.Minute},
+			ConnMaxIdleTime: Duration{time.Minute},
+
+			StartupTimeout: Duration{time.Minute},
+		},
+		Auth: AuthConfig{
+			OldTokenKeys:    []string{},
+			AccessTokenTTL:  Duration{15 * time.Minute},
+			RefreshTokenTTL: Duration{720 * time.Hour},
+
+			LockoutThreshold: 5,
+			LockoutBase:      Duration{time.Minute},
+			LockoutMax:       Duration{time.Hour},
+			LockoutWindow:    Duration{24 * time.Hour},
+
+			LoginRateLimit: 10,
+			LoginBurst:     5,
+
+			RequireVerifiedEmail: true,
+			EmailVerificationTTL: Duration{48 * time.Hour},
+			PasswordResetTTL:     Duration{time.Hour},
+		},
+		Booking: BookingConfig{
+			HoldMinutes:    15,
+			ExpiryInterval: Duration{time.Minute},
+		},
+		Payment: PaymentConfig{
+			Provider: "midtrans",
+			BaseURL:  "https://api.midtrans.com",
+		},
+		Uploads: UploadsConfig{Dir: "uploads", MaxSizeMB: 5},
+		Mail: MailConfig{
+			Driver: "smtp",
+			From:   "Ticket Booking <no-reply@localhost>",
+			Dir:    "tmp/mail",
+			SMTP:   SMTPConfig{Port: 587},
+
+			VerifyEmailPath:   "/verify-email/confirm",
+			ResetPasswordPath: "/reset-password",
+		},
+		CORS:    CORSConfig{AllowOrigins: []string{}},
+		Logging: LoggingConfig{Level: "info", Format: "json"},
+	}
+
+	switch profile {
+	case ProfileDev:
+		var secrets [3]string
+
+		for i := range secrets {
+			key, err := randomKey()
+			if err != nil {
+				return conf, err
+			}
+
+			secrets[i] = key
+		}
+
+		conf.Auth.TokenKey = secrets[0]
+		conf.Payment = PaymentConfig{Provider: "mock", BaseURL: "https://api.sandbox.midtrans.com", ServerKey: secrets[1]}
+		conf.Tickets.SigningKey = secrets[2]
+		conf.CORS.AllowOrigins = []string{"*"}
+		conf.Logging = LoggingConfig{Level: "debug", Format: "text"}
+		conf.Mail.Driver = "log"
+		conf.Mail.BaseURL = "http://localhost:3000"
+	case ProfileStaging:
+		conf.Payment.BaseURL = "https://api.sandbox.midtrans.com"
+		conf.Logging.Level = "debug"
+	case ProfileProd:
+	default:
+		return conf, fmt.Errorf("unknown profile %q, expected %s, %s or %s", profile, ProfileDev, ProfileStaging, ProfileProd)
+	}
+
+	return conf, nil
+}
+
+// randomKey returns a random secret of 32 bytes, the length auth.token_key
+// must have
+func randomKey() (string, error) {
+	key := make([]byte, 24)
+
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("generating a development secret: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(key), nil
+}
+
+// Validate checks every setting and reports all the problems at once
+func (c Configuration) Validate() error {
+	var problems []string
+
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port < 65536, "server.port must be between 1 and 65535")
+	check(c.Server.ReadTimeout.Duration > 0, "server.read_timeout must be positive")
+	check(c.Server.WriteTimeout.Duration > 0, "server.write_timeout must be positive")
+	check(c.Server.IdleTimeout.Duration > 0, "server.idle_timeout must be positive")
+	check(c.Server.ShutdownTimeout.Duration > 0, "server.shutdown_timeout must be positive")
+	check((c.Server.TLS.CertFile == "") == (c.Server.TLS.KeyFile == ""), "server.tls.cert_file and server.tls.key_file must be set together")
+	if c.Server.TLS.Enabled() {
+		_, certErr := os.Stat(c.Server.TLS.CertFile)
+		check(certErr == nil, "server.tls.cert_file: %v", certErr)
+		_, keyErr := os.Stat(c.Server.TLS.KeyFile)
+		check(keyErr == nil, "server.tls.key_file: %v", keyErr)
+	}
+	for i, proxy := range c.Server.TrustedProxies {
+		_, _, proxyErr := net.ParseCIDR(proxy)
+		check(proxyErr == nil, "server.trusted_proxies[%d] must be a CIDR range such as 10.0.0.0/8, got %q", i, proxy)
+	}
+
+	check(c.Database.Host != "", "database.host is required")
+	check(c.Database.Port > 0 && c.Database.Port < 65536, "database.port must be between 1 and 65535")
+	check(c.Database.Name != "", "database.name is required")
+	check(c.Database.Username != "", "database.username is required")
+	_, err := time.LoadLocation(c.Database.Location)
+	check(c.Database.Location != "" && err == nil, "database.location must be a time zone such as Asia/Jayapura, got %q", c.Database.Location)
+	check(c.Database.ConnectTimeout.Duration > 0, "database.connect_timeout must be positive")
+	check(c.Database.ReadTimeout.Duration > 0, "database.read_timeout must be positive")
+	check(c.Database.WriteTimeout.Duration > 0, "database.write_timeout must be positive")
+	check(c.Database.MaxOpenConns > 0, "database.max_open_conns must be positive")
+	check(c.Database.MaxIdleConns >= 0 && c.Database.MaxIdleConns <= c.Database.MaxOpenConns, "database.max_idle_conns must be between 0 and database.max_open_conns")
+	check(c.Database.ConnMaxLifetime.Duration >= 0, "database.conn_max_lifetime must not be negative")
+	check(c.Database.ConnMaxIdleTime.Duration >= 0, "database.conn_max_idle_time must not be negative")
+	check(c.Database.StartupTimeout.Duration >= 0, "database.startup_timeout must not be negative")
+
+	check(len(c.Auth.TokenKey) == 32, "auth.token_key must be exactly 32 bytes, got %d", len(c.Auth.TokenKey))
+	for i, key := range c.Auth.OldTokenKeys {
+		check(len(key) == 32, "auth.old_token_keys[%d] must be exactly 32 bytes, got %d", i, len(key))
+	}
+	check(c.Auth.AccessTokenTTL.Duration > 0, "auth.access_token_ttl must be positive")
+	check(c.Auth.RefreshTokenTTL.Duration > c.Auth.AccessTokenTTL.Duration, "auth.refresh_token_ttl must be longer than auth.access_token_ttl")
+	if c.Auth.BreachedPasswordsFile != "" {
+		_, breachedErr := os.Stat(c.Auth.BreachedPasswordsFile)
+		check(breachedErr == nil, "auth.breached_passwords_file: %v", breachedErr)
+	}
+	check(c.Auth.LockoutThreshold > 0, "auth.lockout_threshold must be positive")
+	check(c.Auth.LockoutBase.Duration > 0, "auth.lockout_base must be positive")
+	check(c.Auth.LockoutMax.Duration >= c.Auth.LockoutBase.Duration, "auth.lockout_max must not be shorter than auth.lockout_base")
+	check(c.Auth.LockoutWindow.Duration > 0, "auth.lockout_window must be positive")
+	check(c.Auth.LoginRateLimit > 0, "auth.login_rate_limit must be positive")
+	check(c.Auth.LoginBurst > 0, "auth.login_burst must be positive")
+	check(c.Auth.EmailVerificationTTL.Duration > 0, "auth.email_verification_ttl must be positive")
+	check(c.Auth.PasswordResetTTL.Duration > 0, "auth.password_reset_ttl must be positive")
+
+	check(c.Booking.TaxRateBps >= 0 && c.Booking.TaxRateBps <= 10000, "booking.tax_rate_bps must be between 0 and 10000")
+	check(c.Booking.HoldMinutes > 0, "booking.hold_minutes must be positive")
+	check(c.Booking.ExpiryInterval.Duration > 0, "booking.expiry_interval must be positive")
+
+	check(c.Payment.Provider == "mock" || c.Payment.Provider == "midtrans", "payment.provider must be mock or midtrans, got %q", c.Payment.Provider)
+	// Anyone signed in can mark their bookings paid through the mock
+	// provider, see POST /payments/mock/:order_id/pay
+	check(c.Payment.Provider != "mock" || c.Profile == ProfileDev, "payment.provider can only be mock in dev")
+	check(c.Payment.ServerKey != "", "payment.server_key is required")
+	check(c.Payment.Provider != "midtrans" || strings.HasPrefix(c.Payment.BaseURL, "https://"), "payment.base_url must be an https URL")
+
+	check(len(c.Tickets.SigningKey) >= 32, "tickets.signing_key must be at least 32 bytes, got %d", len(c.Tickets.SigningKey))
+
+	check(c.Uploads.Dir != "", "uploads.dir is required")
+	check(c.Uploads.MaxSizeMB > 0, "uploads.max_size_mb must be positive")
+
+	if c.Documents.TemplateDir != "" {
+		info, dirErr := os.Stat(c.Documents.TemplateDir)
+		check(dirErr == nil && info.IsDir(), "documents.template_dir must be a directory, got %q", c.Documents.TemplateDir)
+	}
+
+	check(c.Mail.Driver == "log" || c.Mail.Driver == "smtp", "mail.driver must be log or smtp, got %q", c.Mail.Driver)
+	_, fromErr := mail.ParseAddress(c.Mail.From)
+	check(fromErr == nil, "mail.from must be an email address, got %q", c.Mail.From)
+	check(c.Mail.BaseURL != "", "mail.base_url is required")
+	check(c.Mail.BaseURL == "" || strings.HasPrefix(c.Mail.BaseURL, "http://") || strings.HasPrefix(c.Mail.BaseURL, "https://"), "mail.base_url must be an http or https URL, got %q", c.Mail.BaseURL)
+	check(strings.HasPrefix(c.Mail.VerifyEmailPath, "/"), "mail.verify_email_path must start with /, got %q", c.Mail.VerifyEmailPath)
+	check(strings.HasPrefix(c.Mail.ResetPasswordPath, "/"), "mail.reset_password_path must start with /, got %q", c.Mail.ResetPasswordPath)
+	if c.Mail.Driver == "smtp" {
+		check(c.Mail.SMTP.Host != "", "mail.smtp.host is required")
+		check(c.Mail.SMTP.Port > 0 && c.Mail.SMTP.Port < 65536, "mail.smtp.port must be between 1 and 65535")
+	} else {
+		check(c.Mail.Dir != "", "mail.dir is required")
+	}
+
+	check(c.Logging.Level == "debug" || c.Logging.Level == "info" || c.Logging.Level == "warn" || c.Logging.Level == "error",
+		"logging.level must be debug, info, warn or error, got %q", c.Logging.Level)
+	check(c.Logging.Format == "text" || c.Logging.Format == "json", "logging.format must be text or json, got %q", c.Logging.Format)
+
+	if c.Profile == ProfileProd {
+		check(c.Database.Password != "", "database.password is required in prod")
+		check(c.Mail.Driver == "smtp", "mail.driver must be smtp in prod")
+		check(strings.HasPrefix(c.Mail.BaseURL, "https://"), "mail.base_url must be an https URL in prod")
+
+		for _, origin := range c.CORS.AllowOrigins {
+			check(origin != "*", "cors.allow_origins must list the allowed origins in prod, not *")
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid " + c.Profile + " configuration:\n  - " + strings.Join(problems, "\n  - "))
+	}
+
+	return nil
+}
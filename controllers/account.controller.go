@@ -0,0 +1,268 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/apperror"
+	"github.com/bryansamperura/ticket-booking/auth"
+	"github.com/bryansamperura/ticket-booking/config"
+	"github.com/bryansamperura/ticket-booking/logging"
+	"github.com/bryansamperura/ticket-booking/mail"
+	"github.com/bryansamperura/ticket-booking/models"
+	"github.com/bryansamperura/ticket-booking/repository"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	errInvalidAccountToken = apperror.Validation("invalid_token", "The link is invalid, already used or expired")
+	errEmailNotVerified    = apperror.Forbidden("email_not_verified", "Verify your email before logging in")
+)
+
+// mailTimeout bounds looking up the account, storing its token and sending
+// the mail in the background
+const mailTimeout = time.Minute
+
+// RequestEmailVerification mails a new verification link
+// @Summary Request email verification
+// @Description Mails a new verification link when an unverified account uses the email. The answer is the same whether it does or not.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param data body models.EmailRequest true "Email"
+// @Success 202 {object} models.Response
+// @Failure 400 {object} models.Problem
+// @Failure 429 {object} models.Problem
+// @Router /verify-email/request [post]
+func (h *Handler) RequestEmailVerification(c echo.Context) error {
+	request := new(models.EmailRequest)
+
+	if err := c.Bind(request); err != nil {
+		return bindError(err)
+	}
+
+	if err := c.Validate(request); err != nil {
+		return err
+	}
+
+	h.inBackground(c, func(ctx context.Context) error {
+		user, err := h.repos.Users.FindByEmail(ctx, request.Email)
+		if errors.Is(err, repository.ErrNotFound) || (err == nil && user.EmailVerifiedAt != nil) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		return h.sendVerification(ctx, user)
+	})
+
+	return c.JSON(http.StatusAccepted, models.Response{Status: http.StatusAccepted, Message: "If an unverified account uses this email, a verification link has been sent to it"})
+}
+
+// ConfirmEmail verifies an email with the token of a verification link
+// @Summary Confirm email
+// @Description Verifies the email of the account the link was sent for. Each link works once.
+// @Tags Auth
+// @Produce json
+// @Param token query string true "Token from the verification link"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.Problem
+// @Router /verify-email/confirm [get]
+func (h *Handler) ConfirmEmail(c echo.Context) error {
+	token := c.QueryParam("token")
+
+	if token == "" {
+		return errInvalidAccountToken
+	}
+
+	_, err := h.repos.Users.VerifyEmail(c.Request().Context(), auth.HashToken(token))
+
+	if errors.Is(err, repository.ErrNotFound) {
+		return errInvalidAccountToken
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response{Status: http.StatusOK, Message: "Email verified"})
+}
+
+// ForgotPassword mails a password reset link
+// @Summary Forgot password
+// @Description Mails a password reset link when an account uses the email. The answer is the same whether it does or not.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param data body models.EmailRequest true "Email"
+// @Success 202 {object} models.Response
+// @Failure 400 {object} models.Problem
+// @Failure 429 {object} models.Problem
+// @Router /password/forgot [post]
+func (h *Handler) ForgotPassword(c echo.Context) error {
+	request := new(models.EmailRequest)
+
+	if err := c.Bind(request); err != nil {
+		return bindError(err)
+	}
+
+	if err := c.Validate(request); err != nil {
+		return err
+	}
+
+	h.inBackground(c, func(ctx context.Context) error {
+		user, err := h.repos.Users.FindByEmail(ctx, request.Email)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		ttl := config.GetConfig().Auth.PasswordResetTTL.Duration
+
+		link, err := h.issueLink(ctx, user, models.TokenResetPassword, ttl, config.GetConfig().Mail.ResetPasswordPath)
+		if err != nil {
+			return err
+		}
+
+		return h.mailer.Send(ctx, mail.Message{
+			To:      user.Email,
+			Subject: "Reset your password",
+			Body: "Hello,\n\n" +
+				"Someone asked to reset the password of your account. Choose a new password with the link below, it expires in " + formatTTL(ttl) + ".\n\n" +
+				link + "\n\n" +
+				"If it was not you, ignore this email, your password stays the same.\n",
+		})
+	})
+
+	return c.JSON(http.StatusAccepted, models.Response{Status: http.StatusAccepted, Message: "If an account uses this email, a password reset link has been sent to it"})
+}
+
+// ResetPassword sets a new password with the token of a reset link
+// @Summary Reset password
+// @Description Replaces the password of the account the link was sent for and signs it out everywhere. Each link works once.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param data body models.ResetPasswordRequest true "Token and new password"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.Problem
+// @Failure 429 {object} models.Problem
+// @Router /password/reset [post]
+func (h *Handler) ResetPassword(c echo.Context) error {
+	request := new(models.ResetPasswordRequest)
+
+	if err := c.Bind(request); err != nil {
+		return bindError(err)
+	}
+
+	if err := c.Validate(request); err != nil {
+		return err
+	}
+
+	hashPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+
+	user, err := h.repos.Users.ResetPassword(ctx, auth.HashToken(request.Token), string(hashPassword))
+
+	if errors.Is(err, repository.ErrNotFound) {
+		return errInvalidAccountToken
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// Whoever knew the old password is signed out and the owner is no
+	// longer locked out by their failed attempts
+	if err := auth.RevokeAll(ctx, user.Id); err != nil {
+		return err
+	}
+
+	if err := h.repos.Logins.Reset(ctx, user.Email); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response{Status: http.StatusOK, Message: "Password changed"})
+}
+
+// sendVerification mails a verification link to the owner of user
+func (h *Handler) sendVerification(ctx context.Context, user models.User) error {
+	ttl := config.GetConfig().Auth.EmailVerificationTTL.Duration
+
+	link, err := h.issueLink(ctx, user, models.TokenVerifyEmail, ttl, config.GetConfig().Mail.VerifyEmailPath)
+	if err != nil {
+		return err
+	}
+
+	return h.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: "Hello,\n\n" +
+			"Confirm that this is your email address with the link below, it expires in " + formatTTL(ttl) + ".\n\n" +
+			link + "\n\n" +
+			"If you did not create an account, ignore this email.\n",
+	})
+}
+
+// issueLink stores a new token for user and returns the link carrying it
+func (h *Handler) issueLink(ctx context.Context, user models.User, purpose string, ttl time.Duration, path string) (string, error) {
+	token, tokenHash, err := auth.GenerateAccountToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err = h.repos.Users.IssueToken(ctx, user.Id, purpose, tokenHash, ttl); err != nil {
+		return "", err
+	}
+
+	return config.GetConfig().Mail.BaseURL + path + "?token=" + url.QueryEscape(token), nil
+}
+
+// inBackground runs fn after the response is sent, with the request logger
+// but not its deadline. Answers then take as long whether an account exists
+// or not. Wait lets shutdown finish it before the database is closed.
+func (h *Handler) inBackground(c echo.Context, fn func(ctx context.Context) error) {
+	ctx := context.WithoutCancel(c.Request().Context())
+
+	h.background.Add(1)
+
+	go func() {
+		defer h.background.Done()
+
+		ctx, cancel := context.WithTimeout(ctx, mailTimeout)
+		defer cancel()
+
+		if err := fn(ctx); err != nil {
+			logging.FromContext(ctx).Error("sending mail failed", slog.Any("error", err))
+		}
+	}()
+}
+
+// formatTTL writes how long a link stays valid, e.g. "48 hours"
+func formatTTL(ttl time.Duration) string {
+	if ttl >= time.Hour && ttl%time.Hour == 0 {
+		return plural(int(ttl/time.Hour), "hour")
+	}
+
+	return plural(int(math.Ceil(ttl.Minutes())), "minute")
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -1,10 +1,15 @@
 package controllers
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/bryansamperura/ticket-booking/middlewares"
 	"github.com/bryansamperura/ticket-booking/models"
+	"github.com/bryansamperura/ticket-booking/repository"
+	"github.com/bryansamperura/ticket-booking/validation"
 	"github.com/labstack/echo/v4"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -13,17 +18,34 @@ import (
 // @Summary Get a list of all admin
 // @Description Retrieve a list of all admin
 // @Tags Admin
+// @Security Bearer
 // @Produce json
-// @Success 200 {array} models.Admin
-// @Failure 500 {object} models.HTTPError
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page, at most 100" default(20)
+// @Param cursor query string false "Cursor from next_cursor, send it empty to start cursor pagination"
+// @Param sort query string false "Comma separated fields to sort by (id, fullname, email), prefix with - for descending"
+// @Param fullname query string false "Full name contains"
+// @Param email query string false "Email contains"
+// @Success 200 {object} models.Response{data=[]models.Admin}
+// @Failure 400 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /admin [get]
-func FetchAllAdmin(c echo.Context) error {
-	result, err := models.FindAllAdmin()
+func (h *Handler) FetchAllAdmin(c echo.Context) error {
+	opts, err := parseListOptions(c, models.AdminListSpec)
+	if opts == nil {
+		return err
+	}
+
+	page, err := h.repos.Admins.List(c.Request().Context(), *opts)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return err
 	}
 
+	result := page.Response()
+
+	setPageLinks(c, &result, *opts)
+
 	return c.JSON(http.StatusOK, result)
 }
 
@@ -31,64 +53,56 @@ func FetchAllAdmin(c echo.Context) error {
 // @Summary Get admin by id
 // @Description Returns the admin with the given id
 // @Tags Admin
+// @Security Bearer
 // @Param id path int true "Admin ID"
 // @Accept  json
 // @Produce  json
 // @Success 200 {object} models.Admin
-// @Failure 404 {object} models.HTTPError
-// @Failure 500 {object} models.HTTPError
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /admin/{id} [get]
-func GetAdminById(c echo.Context) error {
+func (h *Handler) GetAdminById(c echo.Context) error {
 	// Get the path parameter "id" as a string
 	idStr := c.Param("id")
 
 	// Convert the string to an integer
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+		return errInvalidID
 	}
 
-	result, err := models.FindAdminById(id)
+	admin, err := h.repos.Admins.FindByID(c.Request().Context(), id)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
-	}
-
-	if result.Status == 404 {
-		return c.JSON(http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return err
 	}
 
-	return c.JSON(http.StatusOK, result)
+	return c.JSON(http.StatusOK, models.Response{Status: http.StatusOK, Message: "OK", Data: admin})
 }
 
 // StoreAdmin stores admin data
 // @Summary Create a new admin
-// @Description Save a new admin to the database
+// @Description Save a new admin to the database. Set role to "staff" to create a gate staff account that can only check tickets in. The new account can log in once its email is verified through the link mailed to it.
 // @Tags Admin
+// @Security Bearer
 // @Accept json
 // @Consumes json
 // @Param admin body models.AdminRequest true "Admin Name"
 // @Success 201 {object} models.Admin
-// @Failure 500 {object} models.HTTPError
+// @Failure 400 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /admin [post]
-func StoreAdmin(c echo.Context) error {
+func (h *Handler) StoreAdmin(c echo.Context) error {
 
 	request := new(models.AdminRequest)
 
 	if err := c.Bind(request); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return bindError(err)
 	}
 
-	result, err := models.StoreAdmin(request.FullName, request.Email, request.Phone)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
-	}
-
-	var accountID int64
-
-	if data, ok := result.Data.(map[string]int64); ok {
-		id := data["id"]
-		accountID = id
+	if err := c.Validate(request); err != nil {
+		return err
 	}
 
 	hashPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
@@ -97,16 +111,26 @@ func StoreAdmin(c echo.Context) error {
 		return err
 	}
 
-	request.Password = string(hashPassword)
-	request.Role = "admin"
+	// Gate staff are back office accounts too, but may only check tickets in
+	if request.Role != middlewares.RoleStaff {
+		request.Role = middlewares.RoleAdmin
+	}
+
+	user, err := h.repos.Users.CreateAdmin(c.Request().Context(), *request, string(hashPassword), request.Role)
 
-	account, err := models.StoreAccount(request.Email, request.Password, request.Role, int(accountID))
+	if errors.Is(err, repository.ErrDuplicate) {
+		return errEmailTaken
+	}
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return err
 	}
 
-	return c.JSON(http.StatusCreated, account)
+	h.inBackground(c, func(ctx context.Context) error {
+		return h.sendVerification(ctx, user)
+	})
+
+	return c.JSON(http.StatusCreated, models.Response{Status: http.StatusCreated, Message: "Inserted", Data: map[string]int64{"id": int64(user.Id)}})
 
 }
 
@@ -114,66 +138,70 @@ func StoreAdmin(c echo.Context) error {
 // @Summary Update admin
 // @Description Updates an existing admin in the database
 // @Tags Admin
+// @Security Bearer
 // @Accept json
 // @Consumes json
 // @Param id path int true "Admin ID"
 // @Param admin body models.AdminRequest true "Admin Name"
 // @Success 204 {object} string
-// @Failure 500 {object} models.HTTPError
+// @Failure 400 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /admin/{id} [put]
-func UpdateAdmin(c echo.Context) error {
+func (h *Handler) UpdateAdmin(c echo.Context) error {
 	// Get the path parameter "id" as a string
 	idStr := c.Param("id")
 
 	// Convert the string to an integer
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+		return errInvalidID
 	}
 
 	request := new(models.AdminRequest)
 
 	if err := c.Bind(request); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return bindError(err)
 	}
 
-	result, err := models.UpdateCustomer(id, request.FullName, request.Email, request.Phone)
+	// Passwords and roles are not changed here
+	if err := validation.Default().ValidateExcept(request, "Password", "Role"); err != nil {
+		return err
+	}
+
+	err = h.repos.Admins.Update(c.Request().Context(), id, *request)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return err
 	}
 
-	return c.JSON(http.StatusCreated, result)
+	return c.JSON(http.StatusCreated, models.Response{Status: http.StatusCreated, Message: "Updated", Data: map[string]int64{"rows_affected": 1}})
 }
 
 // DeleteAdmin delete admin by id
 // @Summary Delete admin
-// @Description Deletes an existing admin from the database
+// @Description Deletes an existing admin together with their login account, which is signed out everywhere
 // @Tags Admin
+// @Security Bearer
 // @Produce json
 // @Param id path int true "Admin ID"
 // @Success 204 {object} string
-// @Failure 400 {object} models.HTTPError
-// @Failure 500 {object} models.HTTPError
+// @Failure 400 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /admin/{id} [delete]
-func DeleteAdmin(c echo.Context) error {
+func (h *Handler) DeleteAdmin(c echo.Context) error {
 	// Get the path parameter "id" as a string
 	idStr := c.Param("id")
 
 	// Convert the string to an integer
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+		return errInvalidID
 	}
 
-	result, err := models.DeleteAdmin(id)
+	err = h.repos.Admins.Delete(c.Request().Context(), id)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
-	}
-
-	if result.Status == 404 {
-		return c.JSON(http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return err
 	}
 
-	return c.JSON(http.StatusNoContent, result)
+	return c.JSON(http.StatusNoContent, models.Response{Status: http.StatusNoContent, Message: "Deleted", Data: map[string]int64{"rows_affected": 1}})
 }
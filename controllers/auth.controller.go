@@ -1,13 +1,22 @@
 package controllers
 
 import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
 	"net/http"
 	"strconv"
-	"strings"
+	"time"
 
+	"github.com/bryansamperura/ticket-booking/apperror"
+	"github.com/bryansamperura/ticket-booking/auth"
+	"github.com/bryansamperura/ticket-booking/config"
+	"github.com/bryansamperura/ticket-booking/logging"
+	"github.com/bryansamperura/ticket-booking/middlewares"
 	"github.com/bryansamperura/ticket-booking/models"
+	"github.com/bryansamperura/ticket-booking/repository"
 	"github.com/labstack/echo/v4"
-	"github.com/o1egl/paseto"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -16,40 +25,50 @@ type CustomClaims struct {
 	Role      string `json:"role"`
 }
 
+type TokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
 type SignUpRequest struct {
 	Fullname string `json:"fullname"`
 	Email    string `json:"email"`
-	Phone    string `json:"phone"`
+	Phone    string `json:"phone" example:"+6281234567890"`
 	Password string `json:"password"`
 }
 
+var (
+	errEmailTaken          = apperror.Conflict("email_taken", "Email already registered")
+	errInvalidCredentials  = apperror.Unauthorized("invalid_credentials", "Invalid credentials")
+	errInvalidRefreshToken = apperror.Unauthorized("invalid_refresh_token", "Invalid refresh token")
+	errTooManyAttempts     = apperror.TooManyRequests("too_many_login_attempts", "Too many failed logins, try again later")
+)
+
+// dummyPasswordHash is checked when no account uses the email, so unknown
+// emails take as long to reject as wrong passwords
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("no account uses this email"), bcrypt.DefaultCost)
+
 // Register Register new customer
 // @Summary Regster new customer
-// @Description register a new user
+// @Description register a new user and mail a link to verify the email. Passwords need 8 to 72 characters and must not be a common or breached one.
 // @Tags Auth
 // @Accept  json
 // @Produce  json
 // @Param data body SignUpRequest true "Register Data"
-// @Success 200 {object} models.Response
+// @Success 201 {object} models.Response
+// @Failure 400 {object} models.Problem
+// @Failure 409 {object} models.Problem
 // @Router /register [post]
-func Register(c echo.Context) error {
+func (h *Handler) Register(c echo.Context) error {
 	request := new(models.AuthRegisterRequest)
 
 	if err := c.Bind(request); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
-	}
-
-	cust, err := models.StoreCustomer(request.Fullname, request.Email, request.Phone)
-
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return bindError(err)
 	}
 
-	var accountID int64
-
-	if data, ok := cust.Data.(map[string]int64); ok {
-		id := data["id"]
-		accountID = id
+	if err := c.Validate(request); err != nil {
+		return err
 	}
 
 	hashPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
@@ -58,123 +77,318 @@ func Register(c echo.Context) error {
 		return err
 	}
 
-	request.Password = string(hashPassword)
-	request.Role = "customer"
+	customer := models.CustomerRequest{
+		FullName: request.Fullname,
+		Email:    request.Email,
+		Phone:    request.Phone,
+	}
+
+	// The customer and its login account are created together or not at all
+	user, err := h.repos.Users.CreateCustomer(c.Request().Context(), customer, string(hashPassword))
 
-	account, err := models.StoreAccount(request.Email, request.Password, request.Role, int(accountID))
+	if errors.Is(err, repository.ErrDuplicate) {
+		return errEmailTaken
+	}
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return err
 	}
 
-	return c.JSON(http.StatusCreated, account)
+	h.inBackground(c, func(ctx context.Context) error {
+		return h.sendVerification(ctx, user)
+	})
+
+	return c.JSON(http.StatusCreated, models.Response{Status: http.StatusCreated, Message: "Inserted", Data: map[string]int64{"id": int64(user.Id)}})
 }
 
 // Login Make authentication
 // @Summary Login customer
-// @Description make authentication for the users
+// @Description make authentication for the users. Unknown emails and wrong passwords get the same answer. Repeated failures lock the email for a while, and every client IP is rate limited.
 // @Tags Auth
 // @Accept  json
 // @Param data body models.AuthRequest true "Login Data"
-// @Success 200 {object} models.Response
+// @Success 200 {object} TokenResponse
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 429 {object} models.Problem
 // @Router /login [post]
-func Login(c echo.Context) error {
+func (h *Handler) Login(c echo.Context) error {
 	request := new(models.AuthRequest)
 
 	if err := c.Bind(request); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return bindError(err)
+	}
+
+	if err := c.Validate(request); err != nil {
+		return err
 	}
 
-	user, err := models.FindUserByEmail(request.Email)
+	ctx := c.Request().Context()
 
+	failure := models.LoginFailure{
+		Email:     request.Email,
+		IP:        c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	}
+
+	lockedUntil, err := h.repos.Logins.LockedUntil(ctx, request.Email)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return err
 	}
 
-	if user.Status == 404 {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Bad Credentials"})
+	// Locked emails are refused without looking at the password
+	if time.Now().Before(lockedUntil) {
+		failure.Reason = models.LoginLocked
+
+		if err := h.repos.Logins.Audit(ctx, failure); err != nil {
+			return err
+		}
+
+		return tooManyAttempts(c, lockedUntil)
 	}
 
-	userData := user.Data.(models.User)
+	userData, err := h.repos.Users.FindByEmail(ctx, request.Email)
 
-	AccID, err := strconv.Atoi(userData.AccountID)
+	passwordHash := dummyPasswordHash
 
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+	if errors.Is(err, repository.ErrNotFound) {
+		failure.Reason = models.LoginUnknownEmail
+	} else if err != nil {
+		return err
+	} else {
+		passwordHash = []byte(userData.Password)
+		failure.UserID = userData.Id
 	}
 
 	// Check if the password is correct
-	err = bcrypt.CompareHashAndPassword([]byte(userData.Password), []byte(request.Password))
+	err = bcrypt.CompareHashAndPassword(passwordHash, []byte(request.Password))
+
+	if err != nil && failure.Reason == "" {
+		failure.Reason = models.LoginWrongPassword
+	}
+
+	if failure.Reason != "" {
+		return h.loginFailed(c, failure)
+	}
+
+	if err := h.repos.Logins.Reset(ctx, request.Email); err != nil {
+		return err
+	}
+
+	if userData.EmailVerifiedAt == nil && config.GetConfig().Auth.RequireVerifiedEmail {
+		return errEmailNotVerified
+	}
+
+	AccID, err := strconv.Atoi(userData.AccountID)
 
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, map[string]string{"message": "Invalid credentials"})
+		return errInvalidID
 	}
 
-	// Generate a PASETO token
-	token, err := generateToken(AccID, userData.Role)
+	return h.issueTokens(c, userData.Id, AccID, userData.Role)
+}
+
+// loginFailed records a failed login and answers it the same way whether
+// the email has an account or not
+func (h *Handler) loginFailed(c echo.Context, failure models.LoginFailure) error {
+	ctx := c.Request().Context()
+
+	lockedUntil, err := h.repos.Logins.RecordFailure(ctx, failure, models.DefaultLockoutPolicy())
 	if err != nil {
 		return err
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{"token": "Bearer " + token})
+	logging.FromContext(ctx).Warn("login failed",
+		slog.String("reason", failure.Reason),
+		slog.Int("user_id", failure.UserID),
+		slog.String("ip", failure.IP),
+	)
+
+	if time.Now().Before(lockedUntil) {
+		return tooManyAttempts(c, lockedUntil)
+	}
+
+	return errInvalidCredentials
 }
 
-func Protected(c echo.Context) error {
-	userID := c.Get("uid").(int)
-	role := c.Get("role").(string)
+// tooManyAttempts tells the client when it may try to log in again
+func tooManyAttempts(c echo.Context, lockedUntil time.Time) error {
+	retryAfter := int(math.Ceil(time.Until(lockedUntil).Seconds()))
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"message":  "You accessed a protected route!",
-		"user_id":  userID,
-		"username": role,
-	})
+	c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+
+	return errTooManyAttempts
 }
 
-func generateToken(userID int, role string) (string, error) {
+// RefreshToken Exchange a refresh token for a new access token
+// @Summary Refresh access token
+// @Description exchange a refresh token for a new access token and a new refresh token. Each refresh token can only be used once.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param data body models.RefreshTokenRequest true "Refresh Token"
+// @Success 200 {object} TokenResponse
+// @Failure 401 {object} models.Problem
+// @Router /token/refresh [post]
+func (h *Handler) RefreshToken(c echo.Context) error {
+	request := new(models.RefreshTokenRequest)
+
+	if err := c.Bind(request); err != nil {
+		return bindError(err)
+	}
+
+	if request.RefreshToken == "" {
+		return errInvalidRefreshToken
+	}
+
+	ctx := c.Request().Context()
+
+	refreshToken, err := h.repos.Tokens.FindRefresh(ctx, auth.HashToken(request.RefreshToken))
+
+	if errors.Is(err, repository.ErrNotFound) {
+		return errInvalidRefreshToken
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// Refresh tokens are rotated on every use, losing the race means another
+	// request already exchanged this one
+	revoked, err := h.repos.Tokens.UseRefresh(ctx, refreshToken.Id)
+
+	if err != nil {
+		return err
+	}
+
+	if !revoked {
+		return errInvalidRefreshToken
+	}
+
+	userData, err := h.repos.Users.FindByID(ctx, refreshToken.UserID)
 
-	token, err := paseto.NewV2().Encrypt([]byte("YELLOW SUBMARINE, BLACK WIZARDRY"), CustomClaims{
-		AccountID: userID,
-		Role:      role,
-	}, nil)
+	if errors.Is(err, repository.ErrNotFound) {
+		return errInvalidRefreshToken
+	}
+
+	if err != nil {
+		return err
+	}
+
+	AccID, err := strconv.Atoi(userData.AccountID)
 
 	if err != nil {
-		return "", err
+		return errInvalidID
 	}
 
-	return token, nil
+	return h.issueTokens(c, userData.Id, AccID, userData.Role)
 }
 
-// GetAccountInfo Get account info
-// @Summary Show account info
-// @Description Show account info
+// Logout Revoke the current token
+// @Summary Logout
+// @Description revoke the access token used for this request and, when given, the refresh token issued with it
 // @Security Bearer
 // @Tags Auth
 // @Accept  json
-// @Produce  json
-// @Success 200 {object} CustomClaims
-// @Router /account-info [get]
-func GetAccountInfo(c echo.Context) error {
+// @Param data body models.LogoutRequest false "Refresh Token"
+// @Success 204 {object} string
+// @Failure 401 {object} models.Problem
+// @Router /logout [post]
+func (h *Handler) Logout(c echo.Context) error {
+	claims, ok := middlewares.GetClaims(c)
+
+	if !ok {
+		return middlewares.ErrTokenMissing
+	}
 
-	token := c.Request().Header.Get("Authorization")
+	request := new(models.LogoutRequest)
 
-	if token == "" {
-		return echo.NewHTTPError(http.StatusUnauthorized, "Token is missing")
+	if err := c.Bind(request); err != nil {
+		return bindError(err)
 	}
 
-	// Split the token string by space to get the actual token value
-	tokenParts := strings.Fields(token)
-	if len(tokenParts) != 2 {
-		return echo.NewHTTPError(http.StatusUnauthorized, "Invalid token format")
+	ctx := c.Request().Context()
+
+	if err := auth.Revoke(ctx, claims); err != nil {
+		return err
 	}
 
-	// Extract the actual token value
-	actualToken := tokenParts[1]
+	if request.RefreshToken != "" {
+		err := h.repos.Tokens.RevokeRefresh(ctx, claims.Subject, auth.HashToken(request.RefreshToken))
+
+		if err != nil {
+			return err
+		}
+	}
 
-	var claims CustomClaims
+	return c.NoContent(http.StatusNoContent)
+}
 
-	if err := paseto.NewV2().Decrypt(actualToken, []byte("YELLOW SUBMARINE, BLACK WIZARDRY"), &claims, nil); err != nil {
-		return echo.NewHTTPError(http.StatusUnauthorized, "Invalid token")
+// LogoutAll Revoke every token of the account
+// @Summary Logout from all devices
+// @Description revoke every access and refresh token issued to the account so far
+// @Security Bearer
+// @Tags Auth
+// @Success 204 {object} string
+// @Failure 401 {object} models.Problem
+// @Router /logout-all [post]
+func (h *Handler) LogoutAll(c echo.Context) error {
+	claims, ok := middlewares.GetClaims(c)
+
+	if !ok {
+		return middlewares.ErrTokenMissing
 	}
 
-	return c.JSON(http.StatusOK, claims)
+	if err := auth.RevokeAll(c.Request().Context(), claims.Subject); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// issueTokens responds with a new access token and a new refresh token for the user
+func (h *Handler) issueTokens(c echo.Context, userID int, accountID int, role string) error {
+	token, _, err := auth.GenerateAccessToken(c.Request().Context(), userID, accountID, role)
+	if err != nil {
+		return err
+	}
+
+	refreshToken, refreshTokenHash, err := auth.GenerateRefreshToken()
+	if err != nil {
+		return err
+	}
+
+	err = h.repos.Tokens.StoreRefresh(c.Request().Context(), userID, refreshTokenHash, auth.RefreshTokenTTL())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, TokenResponse{
+		Token:        "Bearer " + token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(auth.AccessTokenTTL().Seconds()),
+	})
+}
+
+// GetAccountInfo Get account info
+// @Summary Show account info
+// @Description Show account info
+// @Security Bearer
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} CustomClaims
+// @Router /account-info [get]
+func (h *Handler) GetAccountInfo(c echo.Context) error {
+	claims, ok := middlewares.GetClaims(c)
+
+	if !ok {
+		return middlewares.ErrTokenMissing
+	}
+
+	return c.JSON(http.StatusOK, CustomClaims{
+		AccountID: claims.UserID,
+		Role:      claims.Role,
+	})
 }
@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/apperror"
+	"github.com/bryansamperura/ticket-booking/models"
+	"github.com/bryansamperura/ticket-booking/repository"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	errInvalidDate           = apperror.Validation("invalid_date", "Invalid date, expected YYYY-MM-DD")
+	errInvalidRange          = apperror.Validation("invalid_date_range", "Invalid date range")
+	errQuotaOverrideNotFound = apperror.NotFound("quota_override_not_found", "Quota override not found")
+)
+
+// GetDestinationAvailability returns the remaining tickets per day
+// @Summary Get destination availability
+// @Description Returns the quota, sold and remaining tickets per day for the destination. Defaults to the next 30 days.
+// @Tags Destinations
+// @Param id path int true "Destination ID"
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Produce  json
+// @Success 200 {array} models.Availability
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Router /destination/{id}/availability [get]
+func (h *Handler) GetDestinationAvailability(c echo.Context) error {
+	// Get the path parameter "id" as a string
+	idStr := c.Param("id")
+
+	// Convert the string to an integer
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return errInvalidID
+	}
+
+	from := models.Today()
+	if fromStr := c.QueryParam("from"); fromStr != "" {
+		from, err = time.Parse(models.DateLayout, fromStr)
+		if err != nil {
+			return errInvalidDate.WithMessage("Invalid from date, expected YYYY-MM-DD")
+		}
+	}
+
+	to := from.AddDate(0, 0, 29)
+	if toStr := c.QueryParam("to"); toStr != "" {
+		to, err = time.Parse(models.DateLayout, toStr)
+		if err != nil {
+			return errInvalidDate.WithMessage("Invalid to date, expected YYYY-MM-DD")
+		}
+	}
+
+	if to.Before(from) {
+		return errInvalidRange.WithMessage("to must not be before from")
+	}
+
+	if to.Sub(from) >= models.MaxAvailabilityDays*24*time.Hour {
+		return errInvalidRange.WithMessage("Range must not exceed " + strconv.Itoa(models.MaxAvailabilityDays) + " days")
+	}
+
+	days, err := h.repos.Availability.Find(c.Request().Context(), id, from, to)
+
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.ErrDestinationNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response{Status: http.StatusOK, Message: "OK", Data: days})
+}
+
+// UpdateDailyQuota sets the default daily quota of a destination
+// @Summary Update destination daily quota
+// @Description Sets how many tickets the destination sells per day. Send null to remove the limit.
+// @Tags Destinations
+// @Security Bearer
+// @Accept json
+// @Param id path int true "Destination ID"
+// @Param quota body models.DailyQuotaRequest true "Daily Quota"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Router /destination/{id}/quota [put]
+func (h *Handler) UpdateDailyQuota(c echo.Context) error {
+	// Get the path parameter "id" as a string
+	idStr := c.Param("id")
+
+	// Convert the string to an integer
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return errInvalidID
+	}
+
+	request := new(models.DailyQuotaRequest)
+
+	if err := c.Bind(request); err != nil {
+		return bindError(err)
+	}
+
+	if err := c.Validate(request); err != nil {
+		return err
+	}
+
+	if err := h.repos.Availability.UpdateDailyQuota(c.Request().Context(), id, request.DailyQuota); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response{Status: http.StatusOK, Message: "Updated", Data: map[string]int64{"rows_affected": 1}})
+}
+
+// StoreQuotaOverride overrides the quota of a destination on one date
+// @Summary Override destination quota for a date
+// @Description Replaces the daily quota on a single date, e.g. for holidays. A quota of 0 closes the destination for that day.
+// @Tags Destinations
+// @Security Bearer
+// @Accept json
+// @Param id path int true "Destination ID"
+// @Param date path string true "Date (YYYY-MM-DD)"
+// @Param quota body models.QuotaOverrideRequest true "Quota Override"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Router /destination/{id}/quota/{date} [put]
+func (h *Handler) StoreQuotaOverride(c echo.Context) error {
+	// Get the path parameter "id" as a string
+	idStr := c.Param("id")
+
+	// Convert the string to an integer
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return errInvalidID
+	}
+
+	date := c.Param("date")
+
+	if _, err := time.Parse(models.DateLayout, date); err != nil {
+		return errInvalidDate
+	}
+
+	request := new(models.QuotaOverrideRequest)
+
+	if err := c.Bind(request); err != nil {
+		return bindError(err)
+	}
+
+	if err := c.Validate(request); err != nil {
+		return err
+	}
+
+	if _, err := h.repos.Destinations.FindByID(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	if err := h.repos.Availability.StoreOverride(c.Request().Context(), id, date, request.Quota, request.Note); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response{
+		Status:  http.StatusOK,
+		Message: "Updated",
+		Data:    map[string]interface{}{"destination_id": id, "date": date, "quota": request.Quota},
+	})
+}
+
+// DeleteQuotaOverride removes the quota override of a destination on one date
+// @Summary Delete destination quota override
+// @Description Removes the override so the default daily quota applies again
+// @Tags Destinations
+// @Security Bearer
+// @Param id path int true "Destination ID"
+// @Param date path string true "Date (YYYY-MM-DD)"
+// @Success 204 {object} string
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Router /destination/{id}/quota/{date} [delete]
+func (h *Handler) DeleteQuotaOverride(c echo.Context) error {
+	// Get the path parameter "id" as a string
+	idStr := c.Param("id")
+
+	// Convert the string to an integer
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return errInvalidID
+	}
+
+	err = h.repos.Availability.DeleteOverride(c.Request().Context(), id, c.Param("date"))
+
+	if errors.Is(err, repository.ErrNotFound) {
+		return errQuotaOverrideNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusNoContent, models.Response{Status: http.StatusNoContent, Message: "Deleted", Data: map[string]int64{"rows_affected": 1}})
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/bryansamperura/ticket-booking/middlewares"
 	"github.com/bryansamperura/ticket-booking/models"
 	"github.com/labstack/echo/v4"
 )
@@ -14,16 +15,35 @@ import (
 // @Tags Booking
 // @Security Bearer
 // @Produce json
-// @Success 200 {array} models.Booking
-// @Failure 500 {object} models.HTTPError
+// @Param status query string false "Booking status" Enums(pending, confirmed, checked_in, cancelled, expired, refunded)
+// @Param customer_id query int false "Customer ID"
+// @Param destination_id query int false "Destination ID"
+// @Param from query string false "First booking date (YYYY-MM-DD)"
+// @Param to query string false "Last booking date (YYYY-MM-DD)"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page, at most 100" default(20)
+// @Param cursor query string false "Cursor from next_cursor, send it empty to start cursor pagination"
+// @Param sort query string false "Comma separated fields to sort by (id, booking_date, qty, total, status, created_at), prefix with - for descending"
+// @Success 200 {object} models.Response{data=[]models.Booking}
+// @Failure 400 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /booking [get]
-func FetchAllBooking(c echo.Context) error {
-	result, err := models.FindAllBooking()
+func (h *Handler) FetchAllBooking(c echo.Context) error {
+	opts, err := parseListOptions(c, models.BookingListSpec)
+	if opts == nil {
+		return err
+	}
+
+	page, err := h.repos.Bookings.List(c.Request().Context(), *opts)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return err
 	}
 
+	result := page.Response()
+
+	setPageLinks(c, &result, *opts)
+
 	return c.JSON(http.StatusOK, result)
 }
 
@@ -36,30 +56,26 @@ func FetchAllBooking(c echo.Context) error {
 // @Accept  json
 // @Produce  json
 // @Success 200 {object} models.Booking
-// @Failure 404 {object} models.HTTPError
-// @Failure 500 {object} models.HTTPError
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /booking/{id} [get]
-func GetBookingById(c echo.Context) error {
+func (h *Handler) GetBookingById(c echo.Context) error {
 	// Get the path parameter "id" as a string
 	idStr := c.Param("customer_id")
 
 	// Convert the string to an integer
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+		return errInvalidID
 	}
 
-	result, err := models.FindBookingById(id)
+	bookings, err := h.repos.Bookings.ListByCustomer(c.Request().Context(), id)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
-	}
-
-	if result.Status == 404 {
-		return c.JSON(http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return err
 	}
 
-	return c.JSON(http.StatusOK, result)
+	return c.JSON(http.StatusOK, models.Response{Status: http.StatusOK, Message: "OK", Data: bookings})
 }
 
 // StoreBooking stores booking data
@@ -71,20 +87,97 @@ func GetBookingById(c echo.Context) error {
 // @Consumes json
 // @Param booking body models.BookingRequest true "Booking Name"
 // @Success 201 {object} models.Booking
-// @Failure 500 {object} models.HTTPError
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /booking [post]
-func StoreBooking(c echo.Context) error {
+func (h *Handler) StoreBooking(c echo.Context) error {
 
 	request := new(models.BookingRequest)
 
 	if err := c.Bind(request); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return bindError(err)
+	}
+
+	if err := c.Validate(request); err != nil {
+		return err
+	}
+
+	// Customers may only book tickets for themselves
+	if !middlewares.HasRole(c, middlewares.RoleAdmin) && !middlewares.IsOwner(c, request.CustomerID) {
+		return middlewares.ErrForbidden
+	}
+
+	id, err := h.repos.Bookings.Create(c.Request().Context(), *request, models.DefaultBookingPolicy())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, models.Response{Status: http.StatusCreated, Message: "Inserted", Data: map[string]int64{"id": int64(id)}})
+}
+
+// CancelBooking cancels a booking
+// @Summary Cancel booking
+// @Description Cancels a pending or confirmed booking and gives its tickets back. Customers can only cancel their own bookings.
+// @Tags Booking
+// @Security Bearer
+// @Param id path int true "Booking ID"
+// @Produce  json
+// @Success 200 {object} models.Response
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Router /booking/{id}/cancel [post]
+func (h *Handler) CancelBooking(c echo.Context) error {
+	return h.transitionBooking(c, models.BookingCancelled, true)
+}
+
+// transitionBooking moves the booking in the "id" path parameter to a new
+// state. When ownerAllowed is true the customer owning the booking may do so,
+// otherwise the route is expected to be restricted to admins.
+func (h *Handler) transitionBooking(c echo.Context, to string, ownerAllowed bool) error {
+	booking, err := h.loadBooking(c, ownerAllowed)
+	if booking == nil {
+		return err
 	}
 
-	result, err := models.StoreBooking(request.CustomerID, request.Qty, request.DestinationID, request.TanggalBooking)
+	err = h.repos.Bookings.Transition(c.Request().Context(), booking.Id, to)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response{
+		Status:  http.StatusOK,
+		Message: "Updated",
+		Data:    map[string]interface{}{"id": booking.Id, "status": to},
+	})
+}
+
+// loadBooking fetches the booking in the "id" path parameter and checks the
+// caller may act on it. When ownerAllowed is true the customer owning the
+// booking may, otherwise only admins. A nil booking comes with the error the
+// handler must return.
+func (h *Handler) loadBooking(c echo.Context, ownerAllowed bool) (*models.Booking, error) {
+	// Get the path parameter "id" as a string
+	idStr := c.Param("id")
+
+	// Convert the string to an integer
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return nil, errInvalidID
+	}
+
+	booking, err := h.repos.Bookings.FindByID(c.Request().Context(), id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !middlewares.HasRole(c, middlewares.RoleAdmin) && !(ownerAllowed && middlewares.IsOwner(c, booking.CustomerID)) {
+		return nil, middlewares.ErrForbidden
 	}
 
-	return c.JSON(http.StatusCreated, result)
+	return &booking, nil
 }
@@ -14,16 +14,31 @@ import (
 // @Tags City
 // @Security Bearer
 // @Produce json
-// @Success 200 {array} models.City
-// @Failure 500 {object} models.HTTPError
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page, at most 100" default(20)
+// @Param cursor query string false "Cursor from next_cursor, send it empty to start cursor pagination"
+// @Param sort query string false "Comma separated fields to sort by (id, city), prefix with - for descending"
+// @Param city query string false "City name contains"
+// @Success 200 {object} models.Response{data=[]models.City}
+// @Failure 400 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /cities [get]
-func FetchAllCities(c echo.Context) error {
-	result, err := models.FindAllCity()
+func (h *Handler) FetchAllCities(c echo.Context) error {
+	opts, err := parseListOptions(c, models.CityListSpec)
+	if opts == nil {
+		return err
+	}
+
+	page, err := h.repos.Cities.List(c.Request().Context(), *opts)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return err
 	}
 
+	result := page.Response()
+
+	setPageLinks(c, &result, *opts)
+
 	return c.JSON(http.StatusOK, result)
 }
 
@@ -36,30 +51,26 @@ func FetchAllCities(c echo.Context) error {
 // @Accept  json
 // @Produce  json
 // @Success 200 {object} models.City
-// @Failure 404 {object} models.HTTPError
-// @Failure 500 {object} models.HTTPError
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /city/{id} [get]
-func GetCityById(c echo.Context) error {
+func (h *Handler) GetCityById(c echo.Context) error {
 	// Get the path parameter "id" as a string
 	idStr := c.Param("id")
 
 	// Convert the string to an integer
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+		return errInvalidID
 	}
 
-	result, err := models.FindCityById(id)
+	city, err := h.repos.Cities.FindByID(c.Request().Context(), id)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return err
 	}
 
-	if result.Status == 404 {
-		return c.JSON(http.StatusNotFound, map[string]string{"message": "Not Found"})
-	}
-
-	return c.JSON(http.StatusOK, result)
+	return c.JSON(http.StatusOK, models.Response{Status: http.StatusOK, Message: "OK", Data: city})
 }
 
 // StoreCity stores city data
@@ -71,22 +82,27 @@ func GetCityById(c echo.Context) error {
 // @Consumes json
 // @Param city body models.CityRequest true "City Name"
 // @Success 201 {object} models.City
-// @Failure 500 {object} models.HTTPError
+// @Failure 400 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /city [post]
-func StoreCity(c echo.Context) error {
+func (h *Handler) StoreCity(c echo.Context) error {
 
 	request := new(models.CityRequest)
 
 	if err := c.Bind(request); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return bindError(err)
 	}
 
-	result, err := models.StoreCity(request.CityName)
+	if err := c.Validate(request); err != nil {
+		return err
+	}
+
+	id, err := h.repos.Cities.Create(c.Request().Context(), request.CityName)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return err
 	}
 
-	return c.JSON(http.StatusCreated, result)
+	return c.JSON(http.StatusCreated, models.Response{Status: http.StatusCreated, Message: "Inserted", Data: map[string]int64{"id": int64(id)}})
 }
 
 // UpdateCity stores city data
@@ -99,30 +115,37 @@ func StoreCity(c echo.Context) error {
 // @Param id path int true "City ID"
 // @Param city body models.CityRequest true "City Name"
 // @Success 204 {object} string
-// @Failure 500 {object} models.HTTPError
+// @Failure 400 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /city/{id} [put]
-func UpdateCity(c echo.Context) error {
+func (h *Handler) UpdateCity(c echo.Context) error {
 	// Get the path parameter "id" as a string
 	idStr := c.Param("id")
 
 	// Convert the string to an integer
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+		return errInvalidID
 	}
 
 	request := new(models.CityRequest)
 
 	if err := c.Bind(request); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return bindError(err)
+	}
+
+	if err := c.Validate(request); err != nil {
+		return err
 	}
 
-	result, err := models.UpdateCity(id, request.CityName)
+	err = h.repos.Cities.Update(c.Request().Context(), id, request.CityName)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return err
 	}
 
-	return c.JSON(http.StatusCreated, result)
+	h.refreshSearchIndex(c)
+
+	return c.JSON(http.StatusCreated, models.Response{Status: http.StatusCreated, Message: "Updated", Data: map[string]int64{"rows_affected": 1}})
 }
 
 // DeleteCity delete city by id
@@ -133,28 +156,26 @@ func UpdateCity(c echo.Context) error {
 // @Produce json
 // @Param id path int true "City ID"
 // @Success 204 {object} string
-// @Failure 400 {object} models.HTTPError
-// @Failure 500 {object} models.HTTPError
+// @Failure 400 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /city/{id} [delete]
-func DeleteCity(c echo.Context) error {
+func (h *Handler) DeleteCity(c echo.Context) error {
 	// Get the path parameter "id" as a string
 	idStr := c.Param("id")
 
 	// Convert the string to an integer
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+		return errInvalidID
 	}
 
-	result, err := models.DeleteCity(id)
+	err = h.repos.Cities.Delete(c.Request().Context(), id)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return err
 	}
 
-	if result.Status == 404 {
-		return c.JSON(http.StatusNotFound, map[string]string{"message": "Not Found"})
-	}
+	h.refreshSearchIndex(c)
 
-	return c.JSON(http.StatusNoContent, result)
+	return c.JSON(http.StatusNoContent, models.Response{Status: http.StatusNoContent, Message: "Deleted", Data: map[string]int64{"rows_affected": 1}})
 }
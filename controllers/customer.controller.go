@@ -14,16 +14,33 @@ import (
 // @Tags Customer
 // @Security Bearer
 // @Produce json
-// @Success 200 {array} models.Customer
-// @Failure 500 {object} models.HTTPError
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page, at most 100" default(20)
+// @Param cursor query string false "Cursor from next_cursor, send it empty to start cursor pagination"
+// @Param sort query string false "Comma separated fields to sort by (id, fullname, email), prefix with - for descending"
+// @Param fullname query string false "Full name contains"
+// @Param email query string false "Email contains"
+// @Param phone query string false "Phone contains"
+// @Success 200 {object} models.Response{data=[]models.Customer}
+// @Failure 400 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /customers [get]
-func FetchAllCustomers(c echo.Context) error {
-	result, err := models.FindAllCustomer()
+func (h *Handler) FetchAllCustomers(c echo.Context) error {
+	opts, err := parseListOptions(c, models.CustomerListSpec)
+	if opts == nil {
+		return err
+	}
+
+	page, err := h.repos.Customers.List(c.Request().Context(), *opts)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return err
 	}
 
+	result := page.Response()
+
+	setPageLinks(c, &result, *opts)
+
 	return c.JSON(http.StatusOK, result)
 }
 
@@ -36,30 +53,26 @@ func FetchAllCustomers(c echo.Context) error {
 // @Accept  json
 // @Produce  json
 // @Success 200 {object} models.Customer
-// @Failure 404 {object} models.HTTPError
-// @Failure 500 {object} models.HTTPError
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /customer/{id} [get]
-func GetCustomerById(c echo.Context) error {
+func (h *Handler) GetCustomerById(c echo.Context) error {
 	// Get the path parameter "id" as a string
 	idStr := c.Param("id")
 
 	// Convert the string to an integer
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+		return errInvalidID
 	}
 
-	result, err := models.FindCustomerById(id)
+	customer, err := h.repos.Customers.FindByID(c.Request().Context(), id)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return err
 	}
 
-	if result.Status == 404 {
-		return c.JSON(http.StatusNotFound, map[string]string{"message": "Not Found"})
-	}
-
-	return c.JSON(http.StatusOK, result)
+	return c.JSON(http.StatusOK, models.Response{Status: http.StatusOK, Message: "OK", Data: customer})
 }
 
 // StoreCustomer stores customer data
@@ -71,27 +84,32 @@ func GetCustomerById(c echo.Context) error {
 // @Consumes json
 // @Param customer body models.CustomerRequest true "Customer Name"
 // @Success 201 {object} models.Customer
-// @Failure 500 {object} models.HTTPError
+// @Failure 400 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /customer [post]
-func StoreCustomer(c echo.Context) error {
+func (h *Handler) StoreCustomer(c echo.Context) error {
 
 	request := new(models.CustomerRequest)
 
 	if err := c.Bind(request); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return bindError(err)
 	}
 
-	result, err := models.StoreCustomer(request.FullName, request.Email, request.Phone)
+	if err := c.Validate(request); err != nil {
+		return err
+	}
+
+	id, err := h.repos.Customers.Create(c.Request().Context(), *request)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return err
 	}
 
-	return c.JSON(http.StatusCreated, result)
+	return c.JSON(http.StatusCreated, models.Response{Status: http.StatusCreated, Message: "Inserted", Data: map[string]int64{"id": int64(id)}})
 }
 
 // UpdateCustomer stores customer data
 // @Summary Update customer
-// @Description Updates an existing customer in the database
+// @Description Updates an existing customer in the database. Customers change their own profile through PUT /me.
 // @Tags Customer
 // @Security Bearer
 // @Accept json
@@ -99,62 +117,63 @@ func StoreCustomer(c echo.Context) error {
 // @Param id path int true "Customer ID"
 // @Param customer body models.CustomerRequest true "Customer Name"
 // @Success 204 {object} string
-// @Failure 500 {object} models.HTTPError
+// @Failure 400 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /customer/{id} [put]
-func UpdateCustomer(c echo.Context) error {
+func (h *Handler) UpdateCustomer(c echo.Context) error {
 	// Get the path parameter "id" as a string
 	idStr := c.Param("id")
 
 	// Convert the string to an integer
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+		return errInvalidID
 	}
 
 	request := new(models.CustomerRequest)
 
 	if err := c.Bind(request); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return bindError(err)
+	}
+
+	if err := c.Validate(request); err != nil {
+		return err
 	}
 
-	result, err := models.UpdateCustomer(id, request.FullName, request.Email, request.Phone)
+	err = h.repos.Customers.Update(c.Request().Context(), id, *request)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return err
 	}
 
-	return c.JSON(http.StatusCreated, result)
+	return c.JSON(http.StatusCreated, models.Response{Status: http.StatusCreated, Message: "Updated", Data: map[string]int64{"rows_affected": 1}})
 }
 
 // DeleteCustomer delete customer by id
 // @Summary Delete Customer
-// @Description Deletes an existing customer from the database
+// @Description Deletes an existing customer together with their login account, which is signed out everywhere. Customers delete their own account through DELETE /me.
 // @Tags Customer
 // @Security Bearer
 // @Produce json
 // @Param id path int true "Customer ID"
 // @Success 204 {object} string
-// @Failure 400 {object} models.HTTPError
-// @Failure 500 {object} models.HTTPError
+// @Failure 400 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /customer/{id} [delete]
-func DeleteCustomer(c echo.Context) error {
+func (h *Handler) DeleteCustomer(c echo.Context) error {
 	// Get the path parameter "id" as a string
 	idStr := c.Param("id")
 
 	// Convert the string to an integer
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+		return errInvalidID
 	}
 
-	result, err := models.DeleteCustomer(id)
+	err = h.repos.Customers.Delete(c.Request().Context(), id)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
-	}
-
-	if result.Status == 404 {
-		return c.JSON(http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return err
 	}
 
-	return c.JSON(http.StatusNoContent, result)
+	return c.JSON(http.StatusNoContent, models.Response{Status: http.StatusNoContent, Message: "Deleted", Data: map[string]int64{"rows_affected": 1}})
 }
@@ -2,34 +2,104 @@ package controllers
 
 import (
 	"io"
+	"log/slog"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/bryansamperura/ticket-booking/apperror"
+	"github.com/bryansamperura/ticket-booking/config"
+	"github.com/bryansamperura/ticket-booking/logging"
 	"github.com/bryansamperura/ticket-booking/models"
+	"github.com/bryansamperura/ticket-booking/search"
 	"github.com/labstack/echo/v4"
 )
 
+var (
+	errMissingQuery  = apperror.Validation("missing_query", "Missing search query")
+	errInvalidLimit  = apperror.Validation("invalid_limit", "Invalid limit")
+	errMissingImage  = apperror.Validation("missing_image", "missing image field")
+	errImageTooLarge = apperror.TooLarge("image_too_large", "image is too large")
+)
+
 // FetchAllDestination returns a list of all destination
 // @Summary Get a list of all destination
 // @Description Retrieve a list of all destination
 // @Tags Destinations
 // @Security Bearer
 // @Produce json
-// @Success 200 {array} models.Destination
-// @Failure 500 {object} models.HTTPError
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page, at most 100" default(20)
+// @Param cursor query string false "Cursor from next_cursor, send it empty to start cursor pagination"
+// @Param sort query string false "Comma separated fields to sort by (id, destination_name, price, city_id), prefix with - for descending"
+// @Param city_id query int false "City ID"
+// @Param min_price query int false "Minimum price"
+// @Param max_price query int false "Maximum price"
+// @Param destination_name query string false "Destination name contains"
+// @Success 200 {object} models.Response{data=[]models.Destination}
+// @Failure 400 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /destination [get]
-func FetchAllDestination(c echo.Context) error {
-	result, err := models.FindAllDestination()
+func (h *Handler) FetchAllDestination(c echo.Context) error {
+	opts, err := parseListOptions(c, models.DestinationListSpec)
+	if opts == nil {
+		return err
+	}
+
+	page, err := h.repos.Destinations.List(c.Request().Context(), *opts)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return err
 	}
 
+	result := page.Response()
+
+	setPageLinks(c, &result, *opts)
+
 	return c.JSON(http.StatusOK, result)
 }
 
+// SearchDestination searches destinations
+// @Summary Search destinations
+// @Description Full-text search over destination names, descriptions and city names, best matches first. Tolerates typos and understands Indonesian and English word forms, e.g. "pantai", "snorkeling biak".
+// @Tags Destinations
+// @Produce json
+// @Param q query string true "Search terms"
+// @Param limit query int false "Maximum number of results, at most 100" default(20)
+// @Success 200 {object} models.Response{data=[]search.Result}
+// @Failure 400 {object} models.Problem
+// @Router /destination/search [get]
+func (h *Handler) SearchDestination(c echo.Context) error {
+	q := strings.TrimSpace(c.QueryParam("q"))
+	if q == "" {
+		return errMissingQuery
+	}
+
+	limit := models.DefaultPerPage
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > models.MaxPerPage {
+			return errInvalidLimit
+		}
+	}
+
+	results := search.Search(q, limit)
+
+	return c.JSON(http.StatusOK, models.Response{Status: http.StatusOK, Message: "OK", Data: results})
+}
+
+// refreshSearchIndex rebuilds the search index after destinations or
+// cities changed. A failure leaves the previous index in place.
+func (h *Handler) refreshSearchIndex(c echo.Context) {
+	if err := search.Rebuild(c.Request().Context(), h.repos.Destinations); err != nil {
+		logging.FromContext(c.Request().Context()).Error("rebuilding search index failed", slog.Any("error", err))
+	}
+}
+
 // GetDestinationById return destination by ID
 // @Summary Get destination by id
 // @Description Returns the destination with the given id
@@ -39,30 +109,26 @@ func FetchAllDestination(c echo.Context) error {
 // @Accept  json
 // @Produce  json
 // @Success 200 {object} models.Destination
-// @Failure 404 {object} models.HTTPError
-// @Failure 500 {object} models.HTTPError
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /destination/{id} [get]
-func GetDestinationById(c echo.Context) error {
+func (h *Handler) GetDestinationById(c echo.Context) error {
 	// Get the path parameter "id" as a string
 	idStr := c.Param("id")
 
 	// Convert the string to an integer
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+		return errInvalidID
 	}
 
-	result, err := models.FindDestinationById(id)
+	destination, err := h.repos.Destinations.FindByID(c.Request().Context(), id)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
-	}
-
-	if result.Status == 404 {
-		return c.JSON(http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return err
 	}
 
-	return c.JSON(http.StatusOK, result)
+	return c.JSON(http.StatusOK, models.Response{Status: http.StatusOK, Message: "OK", Data: destination})
 }
 
 // StoreDestination stores destination data
@@ -78,52 +144,66 @@ func GetDestinationById(c echo.Context) error {
 // @Param price formData int true "Price"
 // @Param image formData file true "Image"
 // @Success 201 {object} models.Destination
-// @Failure 500 {object} models.HTTPError
+// @Failure 400 {object} models.Problem
+// @Failure 413 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /destination [post]
-func StoreDestination(c echo.Context) error {
+func (h *Handler) StoreDestination(c echo.Context) error {
 	var fileType, fileName string
 
-	destinationName := c.FormValue("destination_name")
-	cityID := c.FormValue("city_id")
-	description := c.FormValue("description")
-	price := c.FormValue("price")
-	file, err := c.FormFile("image")
+	request := new(models.DestinationRequest)
 
-	priceInt, err := strconv.Atoi(price)
+	if err := c.Bind(request); err != nil {
+		return bindError(err)
+	}
 
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+	if err := c.Validate(request); err != nil {
+		return err
 	}
 
+	file, err := c.FormFile("image")
+
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "missing image field"})
+		return errMissingImage
+	}
+
+	if tooLarge(file.Size) {
+		return errImageTooLarge
 	}
 
 	src, err := file.Open()
 	if err != nil {
-		return c.JSON(http.StatusOK, Response{Message: "Upload Failed", Data: nil})
+		return err
 	}
 	defer src.Close()
 
 	fileByte, err := io.ReadAll(src)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to read file"})
+		return err
 	}
 
 	fileType = http.DetectContentType(fileByte)
 	fileName = generateFileName(fileType)
 
-	err = os.WriteFile(fileName, fileByte, 0777)
+	err = os.WriteFile(uploadPath(fileName), fileByte, 0777)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return err
 	}
 
-	result, err := models.StoreDestination(destinationName, fileName, cityID, description, priceInt)
+	id, err := h.repos.Destinations.Create(c.Request().Context(), models.Destination{
+		DestinationName: request.DestinationName,
+		Image:           fileName,
+		City:            request.City,
+		Description:     request.Description,
+		Price:           request.Price,
+	})
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return err
 	}
 
-	return c.JSON(http.StatusCreated, result)
+	h.refreshSearchIndex(c)
+
+	return c.JSON(http.StatusCreated, models.Response{Status: http.StatusCreated, Message: "Inserted", Data: map[string]int64{"id": int64(id)}})
 }
 
 // UpdateDestination stores destination data
@@ -140,9 +220,11 @@ func StoreDestination(c echo.Context) error {
 // @Param price formData int true "Price"
 // @Param image formData file true "Image"
 // @Success 204 {object} string
-// @Failure 500 {object} models.HTTPError
+// @Failure 400 {object} models.Problem
+// @Failure 413 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /destination/{id} [put]
-func UpdateDestination(c echo.Context) error {
+func (h *Handler) UpdateDestination(c echo.Context) error {
 	var fileType, fileName string
 
 	// Get the path parameter "id" as a string
@@ -151,50 +233,63 @@ func UpdateDestination(c echo.Context) error {
 	// Convert the string to an integer
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+		return errInvalidID
 	}
 
-	destinationName := c.FormValue("destination_name")
-	cityID := c.FormValue("city_id")
-	description := c.FormValue("description")
-	price := c.FormValue("price")
-	priceInt, err := strconv.Atoi(price)
+	request := new(models.DestinationRequest)
 
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+	if err := c.Bind(request); err != nil {
+		return bindError(err)
+	}
+
+	if err := c.Validate(request); err != nil {
+		return err
 	}
 
 	file, err := c.FormFile("image")
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "missing image field"})
+		return errMissingImage
+	}
+
+	if tooLarge(file.Size) {
+		return errImageTooLarge
 	}
 
 	src, err := file.Open()
 	if err != nil {
-		return c.JSON(http.StatusOK, Response{Message: "Upload Failed", Data: nil})
+		return err
 	}
 	defer src.Close()
 
 	fileByte, err := io.ReadAll(src)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to read file"})
+		return err
 	}
 
 	fileType = http.DetectContentType(fileByte)
 	fileName = generateFileName(fileType)
 
-	err = os.WriteFile(fileName, fileByte, 0777)
+	err = os.WriteFile(uploadPath(fileName), fileByte, 0777)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return err
 	}
 
-	result, err := models.UpdateDestination(id, destinationName, fileName, cityID, description, priceInt)
+	err = h.repos.Destinations.Update(c.Request().Context(), models.Destination{
+		Id:              id,
+		DestinationName: request.DestinationName,
+		Image:           fileName,
+		City:            request.City,
+		Description:     request.Description,
+		Price:           request.Price,
+	})
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return err
 	}
 
-	return c.JSON(http.StatusCreated, result)
+	h.refreshSearchIndex(c)
+
+	return c.JSON(http.StatusCreated, models.Response{Status: http.StatusCreated, Message: "Updated", Data: map[string]int64{"rows_affected": 1}})
 }
 
 // DeleteDestination delete destination by id
@@ -205,30 +300,39 @@ func UpdateDestination(c echo.Context) error {
 // @Produce json
 // @Param id path int true "Destination ID"
 // @Success 204 {object} string
-// @Failure 400 {object} models.HTTPError
-// @Failure 500 {object} models.HTTPError
+// @Failure 400 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /destination/{id} [delete]
-func DeleteDestination(c echo.Context) error {
+func (h *Handler) DeleteDestination(c echo.Context) error {
 	// Get the path parameter "id" as a string
 	idStr := c.Param("id")
 
 	// Convert the string to an integer
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+		return errInvalidID
 	}
 
-	result, err := models.DeleteDestination(id)
+	err = h.repos.Destinations.Delete(c.Request().Context(), id)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return err
 	}
 
-	if result.Status == 404 {
-		return c.JSON(http.StatusNotFound, map[string]string{"message": "Not Found"})
-	}
+	h.refreshSearchIndex(c)
+
+	return c.JSON(http.StatusNoContent, models.Response{Status: http.StatusNoContent, Message: "Deleted", Data: map[string]int64{"rows_affected": 1}})
+}
+
+// uploadPath returns where an upload stored as "uploads/<name>" is kept on
+// disk, uploads are served under /uploads whatever the directory is called
+func uploadPath(fileName string) string {
+	return filepath.Join(config.GetConfig().Uploads.Dir, strings.TrimPrefix(fileName, "uploads/"))
+}
 
-	return c.JSON(http.StatusNoContent, result)
+// tooLarge reports whether an uploaded file exceeds uploads.max_size_mb
+func tooLarge(size int64) bool {
+	return size > config.GetConfig().Uploads.MaxSizeMB<<20
 }
 
 func generateFileName(fileType string) string {
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/bryansamperura/ticket-booking/documents"
+	"github.com/bryansamperura/ticket-booking/models"
+	"github.com/labstack/echo/v4"
+)
+
+// GetBookingInvoicePDF returns the invoice of a booking as a PDF
+// @Summary Download invoice
+// @Description Renders the invoice of the booking, with the price breakdown, as a PDF document
+// @Tags Document
+// @Security Bearer
+// @Produce application/pdf
+// @Param id path int true "Booking ID"
+// @Success 200 {file} file
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Router /booking/{id}/invoice.pdf [get]
+func (h *Handler) GetBookingInvoicePDF(c echo.Context) error {
+	booking, err := h.loadBooking(c, true)
+	if booking == nil {
+		return err
+	}
+
+	pdf, err := documents.RenderInvoice(*booking)
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename=invoice-"+strconv.Itoa(booking.Id)+".pdf")
+
+	return c.Blob(http.StatusOK, "application/pdf", pdf)
+}
+
+// GetBookingTicketPDF returns the e-tickets of a booking as a PDF
+// @Summary Download e-ticket PDF
+// @Description Renders the e-tickets of a confirmed booking as a PDF document with one page and QR code per seat
+// @Tags Document
+// @Security Bearer
+// @Produce application/pdf
+// @Param id path int true "Booking ID"
+// @Success 200 {file} file
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Router /booking/{id}/ticket.pdf [get]
+func (h *Handler) GetBookingTicketPDF(c echo.Context) error {
+	booking, err := h.loadBooking(c, true)
+	if booking == nil {
+		return err
+	}
+
+	if booking.Status != models.BookingConfirmed && booking.Status != models.BookingCheckedIn {
+		return errTicketsNotIssued
+	}
+
+	pdf, err := documents.RenderTicket(*booking)
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename=ticket-"+strconv.Itoa(booking.Id)+".pdf")
+
+	return c.Blob(http.StatusOK, "application/pdf", pdf)
+}
@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/bryansamperura/ticket-booking/apperror"
+	"github.com/bryansamperura/ticket-booking/logging"
+	"github.com/bryansamperura/ticket-booking/middlewares"
+	"github.com/bryansamperura/ticket-booking/models"
+	"github.com/bryansamperura/ticket-booking/validation"
+	"github.com/labstack/echo/v4"
+)
+
+// ProblemContentType is the media type of error responses
+const ProblemContentType = "application/problem+json"
+
+// errInvalidID is returned when an id path parameter is not a number
+var errInvalidID = middlewares.ErrInvalidID
+
+// statuses maps error kinds to HTTP statuses
+var statuses = map[apperror.Kind]int{
+	apperror.KindInternal:        http.StatusInternalServerError,
+	apperror.KindBadRequest:      http.StatusBadRequest,
+	apperror.KindValidation:      http.StatusBadRequest,
+	apperror.KindUnauthorized:    http.StatusUnauthorized,
+	apperror.KindForbidden:       http.StatusForbidden,
+	apperror.KindNotFound:        http.StatusNotFound,
+	apperror.KindConflict:        http.StatusConflict,
+	apperror.KindTooLarge:        http.StatusRequestEntityTooLarge,
+	apperror.KindUnprocessable:   http.StatusUnprocessableEntity,
+	apperror.KindTooManyRequests: http.StatusTooManyRequests,
+	apperror.KindUpstream:        http.StatusBadGateway,
+	apperror.KindUnavailable:     http.StatusServiceUnavailable,
+}
+
+// echoCodes gives the errors echo raises itself, e.g. for unknown routes, a
+// code like the ones of apperror
+var echoCodes = map[int]string{
+	http.StatusBadRequest:            "bad_request",
+	http.StatusUnauthorized:          "unauthorized",
+	http.StatusForbidden:             "forbidden",
+	http.StatusNotFound:              "route_not_found",
+	http.StatusMethodNotAllowed:      "method_not_allowed",
+	http.StatusRequestEntityTooLarge: "payload_too_large",
+	http.StatusUnsupportedMediaType:  "unsupported_media_type",
+	http.StatusTooManyRequests:       "too_many_requests",
+	http.StatusServiceUnavailable:    "unavailable",
+}
+
+// ErrorHandler answers every error returned by a handler or middleware with
+// a problem+json body. It is installed as the echo.HTTPErrorHandler.
+func ErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	problem := NewProblem(c, err)
+
+	c.Response().Header().Set(echo.HeaderContentType, ProblemContentType)
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(problem.Status)
+	} else {
+		err = c.JSON(problem.Status, problem)
+	}
+
+	if err != nil {
+		logging.FromContext(c.Request().Context()).Error("writing error response failed", slog.Any("error", err))
+	}
+}
+
+// NewProblem describes err for the client. Causes of internal errors are
+// left out, they are only logged.
+func NewProblem(c echo.Context, err error) models.Problem {
+	problem := models.Problem{Type: "about:blank", Instance: c.Request().URL.Path}
+
+	if id, ok := c.Get("request_id").(string); ok {
+		problem.RequestID = id
+	}
+
+	var httpErr *echo.HTTPError
+
+	if errors.As(err, &httpErr) && !isAppError(err) {
+		problem.Status = httpErr.Code
+		problem.Code = echoCodes[httpErr.Code]
+		problem.Detail = fmt.Sprint(httpErr.Message)
+
+		if problem.Code == "" || problem.Status >= 500 {
+			problem.Status = http.StatusInternalServerError
+			problem.Code = "internal_error"
+			problem.Detail = http.StatusText(http.StatusInternalServerError)
+		}
+	} else {
+		appErr := apperror.From(err)
+
+		problem.Status = statuses[appErr.Kind]
+		problem.Code = appErr.Code
+		problem.Detail = appErr.Message
+		problem.Errors = appErr.Fields
+
+		// Field errors are answered in the language the client asked for
+		if fields, ok := validation.Default().Localize(err, c.Request().Header.Get("Accept-Language")); ok {
+			problem.Errors = fields
+		}
+
+		// Messages wrapped around a domain error add what went wrong, e.g.
+		// which list parameter is invalid
+		if _, direct := err.(*apperror.Error); !direct && appErr.Kind != apperror.KindInternal {
+			problem.Detail = err.Error()
+		}
+	}
+
+	problem.Title = http.StatusText(problem.Status)
+
+	return problem
+}
+
+func isAppError(err error) bool {
+	var appErr *apperror.Error
+
+	return errors.As(err, &appErr)
+}
+
+// bindError is returned when the request body or parameters cannot be
+// decoded
+func bindError(err error) error {
+	detail := "The request could not be read"
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		detail = fmt.Sprint(httpErr.Message)
+	}
+
+	return apperror.BadRequest("malformed_request", detail).Wrap(err)
+}
@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/bryansamperura/ticket-booking/mail"
+	"github.com/bryansamperura/ticket-booking/repository"
+)
+
+// Handler serves the HTTP endpoints, persisting through the repositories it
+// was built with and mailing through its mailer. Handlers return their
+// errors, ErrorHandler turns them into responses.
+type Handler struct {
+	repos  repository.Repositories
+	mailer mail.Mailer
+
+	// background counts the work still running after its response was sent
+	background sync.WaitGroup
+}
+
+func NewHandler(repos repository.Repositories, mailer mail.Mailer) *Handler {
+	return &Handler{repos: repos, mailer: mailer}
+}
+
+// Wait blocks until the work started in the background by past requests is
+// done, or ctx ends. Call it once the server stopped taking requests and
+// before closing what that work uses, such as the database.
+func (h *Handler) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+
+	go func() {
+		h.background.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/config"
+	"github.com/bryansamperura/ticket-booking/models"
+	"github.com/labstack/echo/v4"
+)
+
+// readyTimeout bounds every readiness check so a hung database fails the
+// probe instead of stalling it
+const readyTimeout = 2 * time.Second
+
+// Healthz tells the orchestrator the process is alive
+// @Summary Liveness probe
+// @Description Answers as long as the server is running, without checking its dependencies
+// @Tags Health
+// @Produce json
+// @Success 200 {object} models.Health
+// @Router /healthz [get]
+func (h *Handler) Healthz(c echo.Context) error {
+	return c.JSON(http.StatusOK, models.Health{Status: "ok"})
+}
+
+// Readyz tells the orchestrator whether the service can take traffic
+// @Summary Readiness probe
+// @Description Checks that the database answers and that uploads can be written
+// @Tags Health
+// @Produce json
+// @Success 200 {object} models.Health
+// @Failure 503 {object} models.Health
+// @Router /readyz [get]
+func (h *Handler) Readyz(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), readyTimeout)
+	defer cancel()
+
+	checks := map[string]error{
+		"database": h.repos.Health.Ping(ctx),
+		"uploads":  checkWritable(config.GetConfig().Uploads.Dir),
+	}
+
+	result := models.Health{Status: "ok", Checks: make(map[string]string)}
+	status := http.StatusOK
+
+	for name, err := range checks {
+		result.Checks[name] = "ok"
+
+		if err != nil {
+			result.Checks[name] = err.Error()
+			result.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	return c.JSON(status, result)
+}
+
+// checkWritable creates and removes a file in dir
+func checkWritable(dir string) error {
+	file, err := os.CreateTemp(dir, ".readyz-*")
+	if err != nil {
+		return err
+	}
+
+	file.Close()
+
+	return os.Remove(file.Name())
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/bryansamperura/ticket-booking/models"
+	"github.com/labstack/echo/v4"
+)
+
+// parseListOptions reads the paging, sorting and filtering parameters of a
+// list request. The result is nil when they are invalid.
+func parseListOptions(c echo.Context, spec models.ListSpec) (*models.ListOptions, error) {
+	opts, err := models.ParseListOptions(c.QueryParams(), spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &opts, nil
+}
+
+// setPageLinks adds the next and prev links of a list response, keeping the
+// sort and filters of the current request
+func setPageLinks(c echo.Context, res *models.Response, opts models.ListOptions) {
+	page := res.Pagination
+	if page == nil {
+		return
+	}
+
+	link := func(key string, value string) string {
+		query := c.QueryParams()
+		query.Set(key, value)
+
+		return c.Request().URL.Path + "?" + query.Encode()
+	}
+
+	if opts.UseCursor {
+		if page.NextCursor != "" {
+			page.Next = link("cursor", page.NextCursor)
+		}
+
+		return
+	}
+
+	if opts.Page*opts.PerPage < page.Total {
+		page.Next = link("page", strconv.Itoa(opts.Page+1))
+	}
+
+	if opts.Page > 1 {
+		// Jump back to the last page when the current one is past the end
+		prev := opts.Page - 1
+		if last := (page.Total + opts.PerPage - 1) / opts.PerPage; prev > last {
+			prev = last
+		}
+
+		if prev >= 1 {
+			page.Prev = link("page", strconv.Itoa(prev))
+		}
+	}
+}
@@ -0,0 +1,371 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/apperror"
+	"github.com/bryansamperura/ticket-booking/logging"
+	"github.com/bryansamperura/ticket-booking/middlewares"
+	"github.com/bryansamperura/ticket-booking/models"
+	"github.com/bryansamperura/ticket-booking/payments"
+	"github.com/bryansamperura/ticket-booking/repository"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	errNotPayable          = apperror.Conflict("booking_not_payable", "Only pending bookings can be paid")
+	errHoldExpired         = apperror.Conflict("booking_hold_expired", "The hold on the tickets has run out, book again")
+	errNotRefundable       = apperror.Conflict("booking_not_refundable", "Booking cannot be refunded")
+	errNoPaidPayment       = apperror.Conflict("no_paid_payment", "Booking has no paid payment")
+	errPaymentNotFound     = apperror.NotFound("payment_not_found", "Payment not found")
+	errInvalidNotification = apperror.BadRequest("invalid_notification", "The notification could not be read")
+	errAmountMismatch      = apperror.Validation("amount_mismatch", "Amount does not match the charge")
+)
+
+// PayBooking starts the payment of a booking
+// @Summary Pay booking
+// @Description Creates a charge at the payment gateway for a pending booking and returns the payment instructions (virtual account number, QRIS payload or e-wallet redirect). The charge expires together with the hold on the tickets. The booking is confirmed once the gateway reports the charge as paid.
+// @Tags Payment
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Param payment body models.PaymentRequest true "Payment Method"
+// @Success 201 {object} models.Payment
+// @Failure 400 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Router /booking/{id}/pay [post]
+func (h *Handler) PayBooking(c echo.Context) error {
+	booking, err := h.loadBooking(c, true)
+	if booking == nil {
+		return err
+	}
+
+	if booking.Status != models.BookingPending {
+		return errNotPayable
+	}
+
+	// The expiry worker may not have run yet, a charge must still never
+	// outlive the hold
+	holdExpiresAt := booking.HoldExpiresAt()
+
+	if !holdExpiresAt.IsZero() && !time.Now().Before(holdExpiresAt) {
+		return errHoldExpired
+	}
+
+	request := new(models.PaymentRequest)
+
+	if err := c.Bind(request); err != nil {
+		return bindError(err)
+	}
+
+	if err := c.Validate(request); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+
+	// Paying twice for the same booking returns the charge already created
+	latest, err := h.repos.Payments.FindLatestByBooking(ctx, booking.Id)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+
+	if err == nil && latest.Status == payments.StatusPending {
+		return c.JSON(http.StatusOK, models.Response{Status: http.StatusOK, Message: "OK", Data: latest})
+	}
+
+	customer, err := h.repos.Customers.FindByID(ctx, booking.CustomerID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+
+	chargeRequest := payments.ChargeRequest{
+		OrderID:       "BK" + strconv.Itoa(booking.Id) + "-" + strconv.FormatInt(time.Now().Unix(), 10),
+		Amount:        booking.Total,
+		ExpiresAt:     holdExpiresAt,
+		Method:        request.Method,
+		Bank:          request.Bank,
+		Channel:       request.Channel,
+		CustomerName:  customer.FullName,
+		CustomerEmail: customer.Email,
+		CustomerPhone: customer.Phone,
+	}
+
+	provider := payments.Default()
+
+	charge, err := provider.CreateCharge(ctx, chargeRequest)
+
+	if errors.Is(err, payments.ErrUnsupportedMethod) || errors.Is(err, payments.ErrFractionalAmount) {
+		return err
+	}
+
+	if err != nil {
+		return payments.ErrGateway.Wrap(err)
+	}
+
+	payment := models.Payment{
+		BookingID:   booking.Id,
+		Provider:    provider.Name(),
+		OrderID:     chargeRequest.OrderID,
+		Reference:   charge.Reference,
+		Method:      request.Method,
+		Amount:      chargeRequest.Amount,
+		Status:      payments.StatusPending,
+		Bank:        charge.Bank,
+		VANumber:    charge.VANumber,
+		QRString:    charge.QRString,
+		RedirectURL: charge.RedirectURL,
+	}
+
+	stored, err := h.repos.Payments.Create(ctx, payment, charge.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, models.Response{Status: http.StatusCreated, Message: "Inserted", Data: stored})
+}
+
+// GetBookingPayment returns the latest payment of a booking
+// @Summary Get booking payment
+// @Description Returns the most recent payment attempt of the booking
+// @Tags Payment
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Success 200 {object} models.Payment
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Router /booking/{id}/payment [get]
+func (h *Handler) GetBookingPayment(c echo.Context) error {
+	booking, err := h.loadBooking(c, true)
+	if booking == nil {
+		return err
+	}
+
+	payment, err := h.repos.Payments.FindLatestByBooking(c.Request().Context(), booking.Id)
+
+	if errors.Is(err, repository.ErrNotFound) {
+		return errPaymentNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response{Status: http.StatusOK, Message: "OK", Data: payment})
+}
+
+// RefundBooking refunds a paid booking
+// @Summary Refund booking
+// @Description Refunds the paid charge of a confirmed, cancelled or expired booking through the payment gateway
+// @Tags Payment
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Param refund body models.RefundRequest false "Refund Reason"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Router /booking/{id}/refund [post]
+func (h *Handler) RefundBooking(c echo.Context) error {
+	booking, err := h.loadBooking(c, false)
+	if booking == nil {
+		return err
+	}
+
+	if !models.CanTransitionBooking(booking.Status, models.BookingRefunded) {
+		return errNotRefundable
+	}
+
+	request := new(models.RefundRequest)
+
+	if err := c.Bind(request); err != nil {
+		return bindError(err)
+	}
+
+	if err := c.Validate(request); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+
+	// Claiming the payment first keeps concurrent requests from refunding
+	// the same charge twice at the gateway
+	payment, err := h.repos.Payments.ClaimRefund(ctx, booking.Id)
+
+	if errors.Is(err, repository.ErrNotFound) {
+		return errNoPaidPayment
+	}
+
+	if errors.Is(err, models.ErrInvalidTransition) {
+		return errNotRefundable
+	}
+
+	if err != nil {
+		return err
+	}
+
+	provider, err := payments.Get(payment.Provider)
+	if err == nil {
+		err = provider.Refund(ctx, payment.OrderID, payment.Amount, request.Reason)
+	}
+
+	// The gateway has answered, so the outcome is recorded even when the
+	// client has gone away meanwhile
+	ctx = context.WithoutCancel(ctx)
+
+	if err != nil {
+		if releaseErr := h.repos.Payments.ReleaseRefund(ctx, payment.OrderID); releaseErr != nil {
+			logging.FromContext(ctx).Error("releasing refund failed", slog.String("order_id", payment.OrderID), slog.Any("error", releaseErr))
+		}
+
+		if errors.Is(err, payments.ErrUnknownProvider) {
+			return err
+		}
+
+		return payments.ErrGateway.Wrap(err)
+	}
+
+	if err := h.repos.Payments.CompleteRefund(ctx, payment.OrderID, booking.Id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response{
+		Status:  http.StatusOK,
+		Message: "Updated",
+		Data:    map[string]interface{}{"id": booking.Id, "status": models.BookingRefunded},
+	})
+}
+
+// PaymentWebhook receives payment notifications from the gateway
+// @Summary Payment webhook
+// @Description Receives signed payment status notifications from the payment gateway. A paid notification confirms the booking.
+// @Tags Payment
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Router /payments/webhook/{provider} [post]
+func (h *Handler) PaymentWebhook(c echo.Context) error {
+	provider, err := payments.Get(c.Param("provider"))
+	if err != nil {
+		return err
+	}
+
+	notification, err := provider.HandleWebhook(c.Request())
+
+	if errors.Is(err, payments.ErrInvalidSignature) {
+		return err
+	}
+
+	if err != nil {
+		return errInvalidNotification.Wrap(err)
+	}
+
+	return h.applyPaymentNotification(c, notification)
+}
+
+// SimulateMockPayment pays a charge of the mock provider
+// @Summary Simulate payment
+// @Description Development only: sends the signed paid notification the mock gateway would send for the order. Only available when the mock provider is configured.
+// @Tags Payment
+// @Security Bearer
+// @Produce json
+// @Param order_id path string true "Order ID"
+// @Success 200 {object} models.Response
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Router /payments/mock/{order_id}/pay [post]
+func (h *Handler) SimulateMockPayment(c echo.Context) error {
+	mock, ok := payments.Mock()
+	if !ok {
+		return echo.ErrNotFound
+	}
+
+	ctx := c.Request().Context()
+
+	payment, err := h.repos.Payments.FindByOrderID(ctx, c.Param("order_id"))
+
+	if errors.Is(err, repository.ErrNotFound) {
+		return errPaymentNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	booking, err := h.repos.Bookings.FindByID(ctx, payment.BookingID)
+	if err != nil {
+		return err
+	}
+
+	if !middlewares.HasRole(c, middlewares.RoleAdmin) && !middlewares.IsOwner(c, booking.CustomerID) {
+		return middlewares.ErrForbidden
+	}
+
+	req, err := mock.SimulatePayment(payment.OrderID, payment.Amount)
+	if err != nil {
+		return err
+	}
+
+	// Go through the same verification as a real webhook delivery
+	notification, err := mock.HandleWebhook(req)
+	if err != nil {
+		return err
+	}
+
+	return h.applyPaymentNotification(c, notification)
+}
+
+// applyPaymentNotification records a verified notification on the payment,
+// confirming the booking when the charge was paid and refunding it when the
+// gateway reports a refund
+func (h *Handler) applyPaymentNotification(c echo.Context, notification payments.Notification) error {
+	ctx := c.Request().Context()
+
+	payment, err := h.repos.Payments.FindByOrderID(ctx, notification.OrderID)
+
+	if errors.Is(err, repository.ErrNotFound) {
+		return errPaymentNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if notification.Status == payments.StatusPaid && notification.Amount != payment.Amount {
+		return errAmountMismatch
+	}
+
+	switch notification.Status {
+	case payments.StatusPaid:
+		err = h.repos.Payments.MarkPaid(ctx, payment.OrderID)
+	case payments.StatusFailed, payments.StatusExpired:
+		_, err = h.repos.Payments.UpdateStatus(ctx, payment.OrderID, payments.StatusPending, notification.Status)
+	case payments.StatusRefunded:
+		err = h.repos.Payments.MarkRefunded(ctx, payment.OrderID)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response{Status: http.StatusOK, Message: "OK"})
+}
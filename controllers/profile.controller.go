@@ -0,0 +1,283 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/apperror"
+	"github.com/bryansamperura/ticket-booking/auth"
+	"github.com/bryansamperura/ticket-booking/middlewares"
+	"github.com/bryansamperura/ticket-booking/models"
+	"github.com/bryansamperura/ticket-booking/repository"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	errWrongPassword = apperror.Validation("wrong_password", "The current password is wrong").
+				WithField("current_password", "The current password is wrong")
+	errActiveBookings = apperror.Conflict("active_bookings", "Cancel your pending and confirmed bookings before deleting the account")
+)
+
+// GetProfile returns the account of the logged in user
+// @Summary Show my profile
+// @Description Returns the login account of the token together with its customer or admin record
+// @Tags Me
+// @Security Bearer
+// @Produce json
+// @Success 200 {object} models.Response{data=models.Profile}
+// @Failure 401 {object} models.Problem
+// @Router /me [get]
+func (h *Handler) GetProfile(c echo.Context) error {
+	claims, ok := middlewares.GetClaims(c)
+
+	if !ok {
+		return middlewares.ErrTokenMissing
+	}
+
+	profile, err := h.repos.Users.FindProfile(c.Request().Context(), claims.Subject)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response{Status: http.StatusOK, Message: "OK", Data: profile})
+}
+
+// UpdateProfile changes the account of the logged in user
+// @Summary Update my profile
+// @Description Changes the name, email and phone of the logged in user. Changing the email needs the current password and a new verification of the email.
+// @Tags Me
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param data body models.ProfileRequest true "Profile"
+// @Success 200 {object} models.Response{data=models.Profile}
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 429 {object} models.Problem
+// @Router /me [put]
+func (h *Handler) UpdateProfile(c echo.Context) error {
+	claims, ok := middlewares.GetClaims(c)
+
+	if !ok {
+		return middlewares.ErrTokenMissing
+	}
+
+	request := new(models.ProfileRequest)
+
+	if err := c.Bind(request); err != nil {
+		return bindError(err)
+	}
+
+	if err := c.Validate(request); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+
+	user, err := h.repos.Users.FindByID(ctx, claims.Subject)
+	if err != nil {
+		return err
+	}
+
+	// Whoever holds a stolen token must not be able to take the account
+	// over through the password reset of a new email
+	emailChanged := !strings.EqualFold(user.Email, request.Email)
+
+	if emailChanged {
+		if err := h.checkPassword(c, user, request.CurrentPassword); err != nil {
+			return err
+		}
+	}
+
+	err = h.repos.Users.UpdateProfile(ctx, user.Id, *request)
+
+	if errors.Is(err, repository.ErrDuplicate) {
+		return errEmailTaken
+	}
+
+	if err != nil {
+		return err
+	}
+
+	profile, err := h.repos.Users.FindProfile(ctx, user.Id)
+	if err != nil {
+		return err
+	}
+
+	if emailChanged {
+		h.inBackground(c, func(ctx context.Context) error {
+			user.Email = profile.Email
+
+			return h.sendVerification(ctx, user)
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.Response{Status: http.StatusOK, Message: "Updated", Data: profile})
+}
+
+// ChangePassword changes the password of the logged in user
+// @Summary Change my password
+// @Description Replaces the password of the logged in user after checking the current one. Every session is signed out, including this one.
+// @Tags Me
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param data body models.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 429 {object} models.Problem
+// @Router /me/password [put]
+func (h *Handler) ChangePassword(c echo.Context) error {
+	claims, ok := middlewares.GetClaims(c)
+
+	if !ok {
+		return middlewares.ErrTokenMissing
+	}
+
+	request := new(models.ChangePasswordRequest)
+
+	if err := c.Bind(request); err != nil {
+		return bindError(err)
+	}
+
+	if err := c.Validate(request); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+
+	user, err := h.repos.Users.FindByID(ctx, claims.Subject)
+	if err != nil {
+		return err
+	}
+
+	if err := h.checkPassword(c, user, request.CurrentPassword); err != nil {
+		return err
+	}
+
+	hashPassword, err := bcrypt.GenerateFromPassword([]byte(request.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if err := h.repos.Users.UpdatePassword(ctx, user.Id, string(hashPassword)); err != nil {
+		return err
+	}
+
+	if err := auth.RevokeAll(ctx, user.Id); err != nil {
+		return err
+	}
+
+	if err := h.repos.Logins.Reset(ctx, user.Email); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response{Status: http.StatusOK, Message: "Password changed, log in again with the new password"})
+}
+
+// DeleteAccount deletes the account of the logged in user
+// @Summary Delete my account
+// @Description Deletes the login account of the logged in user after checking the password. Admin records are deleted, customer records are anonymised so past bookings are kept. Customers must have no pending or confirmed bookings.
+// @Tags Me
+// @Security Bearer
+// @Accept json
+// @Param data body models.DeleteAccountRequest true "Password"
+// @Success 204 {object} string
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 429 {object} models.Problem
+// @Router /me [delete]
+func (h *Handler) DeleteAccount(c echo.Context) error {
+	claims, ok := middlewares.GetClaims(c)
+
+	if !ok {
+		return middlewares.ErrTokenMissing
+	}
+
+	request := new(models.DeleteAccountRequest)
+
+	if err := c.Bind(request); err != nil {
+		return bindError(err)
+	}
+
+	if err := c.Validate(request); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+
+	user, err := h.repos.Users.FindByID(ctx, claims.Subject)
+	if err != nil {
+		return err
+	}
+
+	if err := h.checkPassword(c, user, request.Password); err != nil {
+		return err
+	}
+
+	if user.Role == middlewares.RoleCustomer {
+		bookings, err := h.repos.Bookings.ListByCustomer(ctx, claims.UserID)
+		if err != nil {
+			return err
+		}
+
+		for _, booking := range bookings {
+			if booking.Status == models.BookingPending || booking.Status == models.BookingConfirmed {
+				return errActiveBookings
+			}
+		}
+	}
+
+	if err := auth.RevokeAll(ctx, user.Id); err != nil {
+		return err
+	}
+
+	if err := h.repos.Users.Delete(ctx, user.Id); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// checkPassword confirms a sensitive change with the current password.
+// Wrong passwords count towards the lockout of the email like failed logins.
+func (h *Handler) checkPassword(c echo.Context, user models.User, password string) error {
+	ctx := c.Request().Context()
+
+	lockedUntil, err := h.repos.Logins.LockedUntil(ctx, user.Email)
+	if err != nil {
+		return err
+	}
+
+	if time.Now().Before(lockedUntil) {
+		return tooManyAttempts(c, lockedUntil)
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) == nil {
+		return nil
+	}
+
+	lockedUntil, err = h.repos.Logins.RecordFailure(ctx, models.LoginFailure{
+		Email:     user.Email,
+		UserID:    user.Id,
+		IP:        c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+		Reason:    models.LoginWrongPassword,
+	}, models.DefaultLockoutPolicy())
+	if err != nil {
+		return err
+	}
+
+	if time.Now().Before(lockedUntil) {
+		return tooManyAttempts(c, lockedUntil)
+	}
+
+	return errWrongPassword
+}
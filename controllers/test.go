@@ -15,7 +15,7 @@ type Response struct {
 	Data    interface{}
 }
 
-func Test(c echo.Context) error {
+func (h *Handler) Test(c echo.Context) error {
 	var response Response
 	var isSuccess bool
 	var fileType, fileName string
@@ -23,12 +23,12 @@ func Test(c echo.Context) error {
 
 	file, err := c.FormFile("image")
 	if err != nil {
-		return c.JSON(http.StatusOK, Response{Message: "Upload Failed", Data: nil})
+		return errMissingImage
 	}
 
 	src, err := file.Open()
 	if err != nil {
-		return c.JSON(http.StatusOK, Response{Message: "Upload Failed", Data: nil})
+		return err
 	}
 	defer src.Close()
 
@@ -43,7 +43,7 @@ func Test(c echo.Context) error {
 		fileName = "uploads/" + strconv.FormatInt(time.Now().Unix(), 10) + ".png"
 	}
 
-	err = os.WriteFile(fileName, fileByte, 0777)
+	err = os.WriteFile(uploadPath(fileName), fileByte, 0777)
 	if err != nil {
 		isSuccess = false
 	} else {
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/bryansamperura/ticket-booking/apperror"
+	"github.com/bryansamperura/ticket-booking/middlewares"
+	"github.com/bryansamperura/ticket-booking/models"
+	"github.com/bryansamperura/ticket-booking/repository"
+	"github.com/bryansamperura/ticket-booking/tickets"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	errTicketsNotIssued = apperror.Conflict("tickets_not_issued", "Tickets are issued once the booking is confirmed")
+	errInvalidSeat      = apperror.Validation("invalid_seat", "Invalid seat")
+)
+
+// GetBookingTicket returns the e-ticket QR code of a booking
+// @Summary Download e-ticket
+// @Description Returns the QR code of one seat of a confirmed booking as a PNG image. Bookings for more than one person have one ticket per seat.
+// @Tags Ticket
+// @Security Bearer
+// @Produce png
+// @Param id path int true "Booking ID"
+// @Param seat query int false "Seat number, from 1 to the booking qty" default(1)
+// @Success 200 {file} file
+// @Failure 400 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Router /booking/{id}/ticket [get]
+func (h *Handler) GetBookingTicket(c echo.Context) error {
+	booking, err := h.loadBooking(c, true)
+	if booking == nil {
+		return err
+	}
+
+	if booking.Status != models.BookingConfirmed && booking.Status != models.BookingCheckedIn {
+		return errTicketsNotIssued
+	}
+
+	seat := 1
+	if seatStr := c.QueryParam("seat"); seatStr != "" {
+		seat, err = strconv.Atoi(seatStr)
+		if err != nil || seat < 1 || seat > booking.Qty {
+			return errInvalidSeat
+		}
+	}
+
+	png, err := tickets.QRCode(booking.Id, seat)
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename=ticket-"+strconv.Itoa(booking.Id)+"-"+strconv.Itoa(seat)+".png")
+
+	return c.Blob(http.StatusOK, "image/png", png)
+}
+
+// CheckIn lets a visitor in with their e-ticket
+// @Summary Check in ticket
+// @Description Verifies a scanned e-ticket code for today's visit to the destination and marks the seat as used. A ticket can only be used once.
+// @Tags Ticket
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param checkin body models.CheckInRequest true "Scanned Ticket"
+// @Success 200 {object} models.CheckIn
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 422 {object} models.Problem
+// @Router /checkin [post]
+func (h *Handler) CheckIn(c echo.Context) error {
+	request := new(models.CheckInRequest)
+
+	if err := c.Bind(request); err != nil {
+		return bindError(err)
+	}
+
+	if err := c.Validate(request); err != nil {
+		return err
+	}
+
+	bookingID, seat, err := tickets.Verify(request.Code)
+	if err != nil {
+		return err
+	}
+
+	claims, _ := middlewares.GetClaims(c)
+
+	checkIn, err := h.repos.CheckIns.CheckIn(c.Request().Context(), bookingID, seat, request.DestinationID, claims.UserID)
+
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.ErrBookingNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response{Status: http.StatusOK, Message: "Checked In", Data: checkIn})
+}
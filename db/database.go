@@ -1,28 +1,137 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"time"
 
 	"github.com/bryansamperura/ticket-booking/config"
-	"github.com/bryansamperura/ticket-booking/helper"
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
+)
+
+// Backoff between connection attempts at startup, doubling up to the maximum
+const (
+	initialBackoff = 500 * time.Millisecond
+	maxBackoff     = 10 * time.Second
 )
 
 var db *sql.DB
-var err error
 
-func Init() {
-	conf := config.GetConfig()
+// Init connects to the database and refuses to start the service when the
+// schema misses migrations
+func Init() error {
+	con, err := Open()
+	if err != nil {
+		return err
+	}
+
+	if err = CheckSchema(context.Background(), con); err != nil {
+		con.Close()
+		return err
+	}
+
+	db = con
+
+	return nil
+}
+
+// Open connects to the configured database without checking its schema, for
+// the migrate command. While MySQL is not reachable yet the connection is
+// retried until database.startup_timeout has passed.
+func Open() (*sql.DB, error) {
+	conf := config.GetConfig().Database
+
+	driverConfig, err := DriverConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	connector, err := mysql.NewConnector(driverConfig)
+	if err != nil {
+		return nil, err
+	}
 
-	connectionString := conf.DB_USERNAME + ":" + conf.DB_PASSWORD + "@tcp(" + conf.DB_HOST + ":" + conf.DB_PORT + ")/" + conf.DB_NAME
+	con := sql.OpenDB(connector)
 
-	db, err = sql.Open("mysql", connectionString)
+	con.SetMaxOpenConns(conf.MaxOpenConns)
+	con.SetMaxIdleConns(conf.MaxIdleConns)
+	con.SetConnMaxLifetime(conf.ConnMaxLifetime.Duration)
+	con.SetConnMaxIdleTime(conf.ConnMaxIdleTime.Duration)
+
+	if err = waitForDatabase(con, conf.ConnectTimeout.Duration, conf.StartupTimeout.Duration); err != nil {
+		con.Close()
+		return nil, err
+	}
+
+	return con, nil
+}
 
-	helper.PanicIfError(err)
+// DriverConfig returns the MySQL driver settings for conf. Times are parsed
+// into time.Time in the configured location and the session uses the same
+// offset, so NOW() and the values read back agree.
+func DriverConfig(conf config.DatabaseConfig) (*mysql.Config, error) {
+	location, err := time.LoadLocation(conf.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	driverConfig := mysql.NewConfig()
+	driverConfig.User = conf.Username
+	driverConfig.Passwd = conf.Password
+	driverConfig.Net = "tcp"
+	driverConfig.Addr = net.JoinHostPort(conf.Host, strconv.Itoa(conf.Port))
+	driverConfig.DBName = conf.Name
+	driverConfig.ParseTime = true
+	driverConfig.Loc = location
+	driverConfig.Timeout = conf.ConnectTimeout.Duration
+	driverConfig.ReadTimeout = conf.ReadTimeout.Duration
+	driverConfig.WriteTimeout = conf.WriteTimeout.Duration
+	driverConfig.Params = map[string]string{
+		"time_zone": "'" + time.Now().In(location).Format("-07:00") + "'",
+	}
+
+	return driverConfig, nil
+}
+
+// waitForDatabase pings con until it answers, backing off between attempts,
+// and gives up once the next attempt would start after timeout
+func waitForDatabase(con *sql.DB, pingTimeout time.Duration, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	backoff := initialBackoff
+
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+		err := con.PingContext(ctx)
+		cancel()
+
+		if err == nil {
+			return nil
+		}
+
+		if time.Now().Add(backoff).After(deadline) {
+			return fmt.Errorf("database is not reachable after %d attempts: %w", attempt, err)
+		}
+
+		slog.Warn("database is not reachable, retrying", slog.Duration("backoff", backoff), slog.Any("error", err))
+
+		time.Sleep(backoff)
+
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
 
-	err = db.Ping()
+// Close closes the connection pool opened by Init, waiting for the queries
+// in progress
+func Close() error {
+	if db == nil {
+		return nil
+	}
 
-	helper.PanicIfError(err)
+	return db.Close()
 }
 
 func CreateConnection() *sql.DB {
@@ -0,0 +1,416 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MigrationsDir is where migrate create writes new migrations, relative to
+// the repository root. They are embedded into the binary when it is built.
+const MigrationsDir = "db/migrations"
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// ErrSchemaBehind is returned when the database misses migrations this build
+// depends on
+var ErrSchemaBehind = errors.New("database schema is behind, run the migrate up command")
+
+// Migration is a versioned schema change with the SQL to apply and revert it
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus tells whether a migration has been applied and when
+type MigrationStatus struct {
+	Migration
+	AppliedAt *string
+}
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migrations returns the migrations embedded in the binary, oldest first
+func Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file name %s", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+
+		content, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration := byVersion[version]
+		if migration == nil {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names, %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// CheckSchema returns ErrSchemaBehind when a migration embedded in the binary
+// has not been applied to the database
+func CheckSchema(ctx context.Context, con *sql.DB) error {
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+
+	var exists int
+
+	err = con.QueryRowContext(ctx, "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = 'schema_migrations'").Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	applied := make(map[int]bool)
+
+	if exists > 0 {
+		applied, err = appliedVersions(ctx, con)
+		if err != nil {
+			return err
+		}
+	}
+
+	var pending []string
+
+	for _, migration := range migrations {
+		if !applied[migration.Version] {
+			pending = append(pending, strconv.Itoa(migration.Version)+"_"+migration.Name)
+		}
+	}
+
+	if len(pending) > 0 {
+		return fmt.Errorf("%w, pending: %s", ErrSchemaBehind, strings.Join(pending, ", "))
+	}
+
+	return nil
+}
+
+// MigrationStatuses lists every embedded migration with when it was applied
+func MigrationStatuses(ctx context.Context, con *sql.DB) ([]MigrationStatus, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = createMigrationsTable(ctx, con); err != nil {
+		return nil, err
+	}
+
+	rows, err := con.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	appliedAt := make(map[int]string)
+
+	for rows.Next() {
+		var version int
+		var at string
+
+		if err = rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+
+		appliedAt[version] = at
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+
+	for _, migration := range migrations {
+		status := MigrationStatus{Migration: migration}
+
+		if at, ok := appliedAt[migration.Version]; ok {
+			status.AppliedAt = &at
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// MigrateUp applies every pending migration in order and returns the ones it
+// applied. It stops at the first failing migration.
+func MigrateUp(ctx context.Context, con *sql.DB) ([]Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+
+	err = withMigrationLock(ctx, con, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			if applied[migration.Version] {
+				continue
+			}
+
+			if err = runMigration(ctx, conn, migration.Up); err != nil {
+				return fmt.Errorf("applying migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			_, err = conn.ExecContext(ctx, "INSERT INTO schema_migrations(version, name) VALUES (?, ?)", migration.Version, migration.Name)
+			if err != nil {
+				return err
+			}
+
+			done = append(done, migration)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// MigrateDown reverts the last steps applied migrations, newest first, and
+// returns the ones it reverted
+func MigrateDown(ctx context.Context, con *sql.DB, steps int) ([]Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+
+	err = withMigrationLock(ctx, con, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			migration := migrations[i]
+
+			if !applied[migration.Version] {
+				continue
+			}
+
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s cannot be reverted, it has no down file", migration.Version, migration.Name)
+			}
+
+			if err = runMigration(ctx, conn, migration.Down); err != nil {
+				return fmt.Errorf("reverting migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			if _, err = conn.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", migration.Version); err != nil {
+				return err
+			}
+
+			done = append(done, migration)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// CreateMigration writes empty up and down files for a new migration into
+// dir, numbered after the last migration found there, and returns their paths
+func CreateMigration(dir string, name string) ([]string, error) {
+	name = strings.Trim(regexp.MustCompile(`\W+`).ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, errors.New("migration name must contain letters or digits")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	last := 0
+
+	for _, entry := range entries {
+		if match := migrationFileName.FindStringSubmatch(entry.Name()); match != nil {
+			if version, _ := strconv.Atoi(match[1]); version > last {
+				last = version
+			}
+		}
+	}
+
+	var paths []string
+
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, fmt.Sprintf("%04d_%s.%s.sql", last+1, name, direction))
+
+		if err = os.WriteFile(path, []byte("-- "+strings.ToUpper(direction)+" migration "+name+"\n"), 0644); err != nil {
+			return paths, err
+		}
+
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// contextExecutor is implemented by *sql.DB and *sql.Conn
+type contextExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func createMigrationsTable(ctx context.Context, con contextExecutor) error {
+	_, err := con.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version     INT PRIMARY KEY,
+		name        VARCHAR(255) NOT NULL,
+		applied_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+
+	return err
+}
+
+func appliedVersions(ctx context.Context, con contextExecutor) (map[int]bool, error) {
+	rows, err := con.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	applied := make(map[int]bool)
+
+	for rows.Next() {
+		var version int
+
+		if err = rows.Scan(&version); err != nil {
+			return nil, err
+		}
+
+		applied[version] = true
+	}
+
+	return applied, rows.Err()
+}
+
+// withMigrationLock runs fn on a single connection holding a MySQL named
+// lock, so two instances starting at once do not apply the same migration
+func withMigrationLock(ctx context.Context, con *sql.DB, fn func(conn *sql.Conn) error) error {
+	conn, err := con.Conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	var locked sql.NullInt64
+
+	if err = conn.QueryRowContext(ctx, "SELECT GET_LOCK('schema_migrations', 60)").Scan(&locked); err != nil {
+		return err
+	}
+
+	if locked.Int64 != 1 {
+		return errors.New("another migration is running")
+	}
+
+	defer conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK('schema_migrations')")
+
+	if err = createMigrationsTable(ctx, conn); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// runMigration executes the statements of a migration file one by one. MySQL
+// commits every DDL statement on its own, so a migration failing halfway is
+// not rolled back and has to be fixed by hand.
+func runMigration(ctx context.Context, conn *sql.Conn, script string) error {
+	for _, statement := range splitStatements(script) {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// splitStatements splits a migration file into statements. A statement ends
+// with a semicolon at the end of a line, lines starting with -- are comments.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		current.WriteString(line)
+		current.WriteString("\n")
+
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+
+	return statements
+}
@@ -0,0 +1,32 @@
+-- Reverts what 0001 added to the original ticket_booking schema. The
+-- original tables are left in place, with whatever data they hold.
+
+DROP TABLE IF EXISTS ticket_checkins;
+DROP TABLE IF EXISTS payments;
+DROP TABLE IF EXISTS destination_inventory;
+DROP TABLE IF EXISTS destination_quota_overrides;
+DROP TABLE IF EXISTS revoked_users;
+DROP TABLE IF EXISTS revoked_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
+
+ALTER TABLE booking
+    DROP KEY idx_booking_status_expires_at,
+    DROP KEY idx_booking_status,
+    DROP COLUMN expires_at,
+    DROP COLUMN currency,
+    DROP COLUMN unit_price,
+    DROP COLUMN subtotal,
+    DROP COLUMN discount,
+    DROP COLUMN tax,
+    DROP COLUMN total,
+    DROP COLUMN status,
+    DROP COLUMN created_at,
+    DROP COLUMN confirmed_at,
+    DROP COLUMN checked_in_at,
+    DROP COLUMN cancelled_at,
+    DROP COLUMN expired_at,
+    DROP COLUMN refunded_at;
+
+ALTER TABLE destination DROP COLUMN daily_quota;
+
+ALTER TABLE users DROP KEY uq_users_email;
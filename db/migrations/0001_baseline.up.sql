@@ -0,0 +1,189 @@
+-- Schema of the service at the time migrations were introduced.
+--
+-- The first tables are the original ticket_booking schema, which databases
+-- created by hand already have. Everything the service added on top of it
+-- follows as separate statements. Databases that applied the old
+-- db/schema.sql by hand have some or all of these changes already, so each
+-- ALTER TABLE first checks whether its columns exist and is skipped if so.
+
+CREATE TABLE IF NOT EXISTS cities (
+    id         INT AUTO_INCREMENT PRIMARY KEY,
+    city_name  VARCHAR(255) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS customers (
+    id        INT AUTO_INCREMENT PRIMARY KEY,
+    fullname  VARCHAR(255) NOT NULL,
+    email     VARCHAR(255) NOT NULL,
+    phone     VARCHAR(32) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS admin (
+    id        INT AUTO_INCREMENT PRIMARY KEY,
+    fullname  VARCHAR(255) NOT NULL,
+    email     VARCHAR(255) NOT NULL,
+    phone     VARCHAR(32) NOT NULL DEFAULT ''
+);
+
+-- Login accounts, account_id points at customers or admin depending on role
+CREATE TABLE IF NOT EXISTS users (
+    id          INT AUTO_INCREMENT PRIMARY KEY,
+    email       VARCHAR(255) NOT NULL,
+    password    VARCHAR(255) NOT NULL,
+    role        VARCHAR(16) NOT NULL,
+    account_id  INT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS destination (
+    id                INT AUTO_INCREMENT PRIMARY KEY,
+    destination_name  VARCHAR(255) NOT NULL,
+    image             VARCHAR(255) NOT NULL DEFAULT '',
+    city_id           INT NOT NULL,
+    description       TEXT NOT NULL,
+    price             INT NOT NULL,
+    KEY idx_destination_city_id (city_id)
+);
+
+CREATE TABLE IF NOT EXISTS booking (
+    id              INT AUTO_INCREMENT PRIMARY KEY,
+    customer_id     INT NOT NULL,
+    qty             INT NOT NULL,
+    destination_id  INT NOT NULL,
+    booking_date    DATE NOT NULL,
+    KEY idx_booking_customer_id (customer_id),
+    KEY idx_booking_destination_id (destination_id)
+);
+
+-- Registration relies on this to reject duplicate emails inside its transaction
+SET @add_users_email_key = NOT EXISTS (SELECT 1 FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = 'users' AND index_name = 'uq_users_email');
+SET @ddl = IF(@add_users_email_key, 'ALTER TABLE users ADD UNIQUE KEY uq_users_email (email)', 'DO 0');
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
+
+-- Default number of tickets sold per day, NULL means unlimited
+SET @add_daily_quota = NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = 'destination' AND column_name = 'daily_quota');
+SET @ddl = IF(@add_daily_quota, 'ALTER TABLE destination ADD COLUMN daily_quota INT NULL', 'DO 0');
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
+
+-- Booking lifecycle: pending -> confirmed -> checked_in / cancelled / expired / refunded
+SET @add_booking_status = NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = 'booking' AND column_name = 'status');
+SET @ddl = IF(@add_booking_status, 'ALTER TABLE booking
+    ADD COLUMN status         VARCHAR(16) NOT NULL DEFAULT ''pending'',
+    ADD COLUMN created_at     DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN confirmed_at   DATETIME NULL,
+    ADD COLUMN checked_in_at  DATETIME NULL,
+    ADD COLUMN cancelled_at   DATETIME NULL,
+    ADD COLUMN expired_at     DATETIME NULL,
+    ADD COLUMN refunded_at    DATETIME NULL,
+    ADD KEY idx_booking_status (status)', 'DO 0');
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
+
+-- Bookings made before the lifecycle existed were already sold
+UPDATE booking SET status = 'confirmed', confirmed_at = created_at WHERE @add_booking_status AND status = 'pending';
+
+-- Price snapshot taken when the booking is made, amounts in IDR minor units (sen)
+SET @add_booking_price = NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = 'booking' AND column_name = 'total');
+SET @ddl = IF(@add_booking_price, 'ALTER TABLE booking
+    ADD COLUMN currency    CHAR(3) NOT NULL DEFAULT ''IDR'',
+    ADD COLUMN unit_price  BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN subtotal    BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN discount    BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN tax         BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN total       BIGINT NOT NULL DEFAULT 0', 'DO 0');
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
+
+-- Existing bookings are priced at the destination price they show today
+UPDATE booking
+    JOIN destination ON destination.id = booking.destination_id
+SET booking.unit_price = destination.price * 100,
+    booking.subtotal   = destination.price * 100 * booking.qty,
+    booking.total      = destination.price * 100 * booking.qty
+WHERE @add_booking_price AND booking.unit_price = 0;
+
+-- Pending bookings hold their tickets until expires_at, then expire unpaid
+SET @add_booking_expiry = NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = 'booking' AND column_name = 'expires_at');
+SET @ddl = IF(@add_booking_expiry, 'ALTER TABLE booking
+    ADD COLUMN expires_at DATETIME NULL AFTER created_at,
+    ADD KEY idx_booking_status_expires_at (status, expires_at)', 'DO 0');
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id          INT AUTO_INCREMENT PRIMARY KEY,
+    user_id     INT NOT NULL,
+    token_hash  CHAR(64) NOT NULL,
+    expires_at  DATETIME NOT NULL,
+    revoked_at  DATETIME NULL,
+    created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_refresh_tokens_token_hash (token_hash),
+    KEY idx_refresh_tokens_user_id (user_id)
+);
+
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti         VARCHAR(64) PRIMARY KEY,
+    user_id     INT NOT NULL,
+    expires_at  DATETIME NOT NULL,
+    created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY idx_revoked_tokens_expires_at (expires_at)
+);
+
+CREATE TABLE IF NOT EXISTS revoked_users (
+    user_id         INT PRIMARY KEY,
+    revoked_before  DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS destination_quota_overrides (
+    destination_id  INT NOT NULL,
+    quota_date      DATE NOT NULL,
+    quota           INT NOT NULL,
+    note            VARCHAR(255) NOT NULL DEFAULT '',
+    PRIMARY KEY (destination_id, quota_date)
+);
+
+-- Tickets sold per destination and day, locked while a booking is created
+CREATE TABLE IF NOT EXISTS destination_inventory (
+    destination_id  INT NOT NULL,
+    inventory_date  DATE NOT NULL,
+    sold            INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (destination_id, inventory_date)
+);
+
+-- Payment attempts, amounts in IDR minor units. A booking is confirmed once
+-- the gateway notifies that one of its payments is paid.
+CREATE TABLE IF NOT EXISTS payments (
+    id            INT AUTO_INCREMENT PRIMARY KEY,
+    booking_id    INT NOT NULL,
+    provider      VARCHAR(32) NOT NULL,
+    order_id      VARCHAR(64) NOT NULL,
+    reference     VARCHAR(128) NOT NULL DEFAULT '',
+    method        VARCHAR(32) NOT NULL,
+    amount        BIGINT NOT NULL,
+    status        VARCHAR(16) NOT NULL DEFAULT 'pending',
+    bank          VARCHAR(32) NOT NULL DEFAULT '',
+    va_number     VARCHAR(64) NOT NULL DEFAULT '',
+    qr_string     TEXT NOT NULL,
+    redirect_url  VARCHAR(512) NOT NULL DEFAULT '',
+    expires_at    DATETIME NULL,
+    paid_at       DATETIME NULL,
+    created_at    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_payments_order_id (order_id),
+    KEY idx_payments_booking_id (booking_id)
+);
+
+-- One row per e-ticket seat scanned at the gate, the primary key makes sure
+-- a seat is only let in once
+CREATE TABLE IF NOT EXISTS ticket_checkins (
+    booking_id     INT NOT NULL,
+    seat_no        INT NOT NULL,
+    checked_in_by  INT NOT NULL,
+    checked_in_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (booking_id, seat_no)
+);
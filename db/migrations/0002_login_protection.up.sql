@@ -0,0 +1,21 @@
+-- Failed logins are counted per email, whether an account uses it or not, so
+-- a lockout does not reveal which emails are registered
+CREATE TABLE IF NOT EXISTS login_throttles (
+    email           VARCHAR(255) PRIMARY KEY,
+    failures        INT NOT NULL DEFAULT 0,
+    last_failure_at DATETIME NOT NULL,
+    locked_until    DATETIME NULL
+);
+
+-- Audit trail of failed logins, user_id is NULL for unknown emails
+CREATE TABLE IF NOT EXISTS login_failures (
+    id          BIGINT AUTO_INCREMENT PRIMARY KEY,
+    email       VARCHAR(255) NOT NULL,
+    user_id     INT NULL,
+    ip          VARCHAR(45) NOT NULL,
+    user_agent  VARCHAR(255) NOT NULL DEFAULT '',
+    reason      VARCHAR(32) NOT NULL,
+    created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY idx_login_failures_email (email, created_at),
+    KEY idx_login_failures_ip (ip, created_at)
+);
@@ -0,0 +1,17 @@
+-- Accounts created before email verification existed are trusted as they are
+ALTER TABLE users ADD COLUMN email_verified_at DATETIME NULL;
+
+UPDATE users SET email_verified_at = NOW();
+
+-- Single-use tokens mailed to account owners, only their SHA-256 is stored
+CREATE TABLE IF NOT EXISTS account_tokens (
+    id          INT AUTO_INCREMENT PRIMARY KEY,
+    user_id     INT NOT NULL,
+    purpose     VARCHAR(32) NOT NULL,
+    token_hash  CHAR(64) NOT NULL,
+    expires_at  DATETIME NOT NULL,
+    used_at     DATETIME NULL,
+    created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_account_tokens_token_hash (token_hash),
+    KEY idx_account_tokens_user_id (user_id, purpose)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE revoked_users ADD COLUMN revoked_before DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+ALTER TABLE revoked_users DROP COLUMN generation;
@@ -0,0 +1,9 @@
+-- Logging out everywhere moves the user on to a new token generation
+-- instead of recording a time, which caught logins made in the same second.
+-- Users who logged out everywhere before start at generation 1, so access
+-- tokens issued without a generation are revoked for them.
+ALTER TABLE revoked_users ADD COLUMN generation INT NOT NULL DEFAULT 0;
+
+UPDATE revoked_users SET generation = 1;
+
+ALTER TABLE revoked_users DROP COLUMN revoked_before;
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Truncate empties every table of the database except schema_migrations, so
+// the seed command can bring it back to a known state
+func Truncate(ctx context.Context, con *sql.DB) error {
+	rows, err := con.QueryContext(ctx, "SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE' AND table_name <> 'schema_migrations'")
+	if err != nil {
+		return err
+	}
+
+	var tables []string
+
+	for rows.Next() {
+		var table string
+
+		if err = rows.Scan(&table); err != nil {
+			rows.Close()
+			return err
+		}
+
+		tables = append(tables, table)
+	}
+
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	// Foreign key checks are per session, so every statement has to run on
+	// the same connection
+	conn, err := con.Conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	if _, err = conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 0"); err != nil {
+		return err
+	}
+
+	defer conn.ExecContext(context.Background(), "SET FOREIGN_KEY_CHECKS = 1")
+
+	for _, table := range tables {
+		if _, err = conn.ExecContext(ctx, "TRUNCATE TABLE `"+table+"`"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// TransactionContext runs fn inside a transaction on the given connection
+// pool. The transaction is committed when fn returns nil and rolled back
+// when it returns an error or panics.
+func TransactionContext(ctx context.Context, con *sql.DB, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := con.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,381 @@
+// Package documents renders printable PDF invoices and tickets for bookings.
+// The layout is driven by JSON templates built into the binary, a configured
+// directory can replace them so the branding changes without a new build.
+package documents
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/config"
+	"github.com/bryansamperura/ticket-booking/models"
+	"github.com/bryansamperura/ticket-booking/tickets"
+	"github.com/go-pdf/fpdf"
+)
+
+// builtin holds the default invoice.json and ticket.json
+//
+//go:embed templates
+var builtin embed.FS
+
+// Template describes the layout of a document
+type Template struct {
+	PageSize    string   `json:"page_size"`
+	Orientation string   `json:"orientation"`
+	Brand       Brand    `json:"brand"`
+	Title       string   `json:"title"`
+	Fields      []Field  `json:"fields"`
+	ShowItems   bool     `json:"show_items"`
+	ShowQR      bool     `json:"show_qr"`
+	TermsTitle  string   `json:"terms_title"`
+	Terms       []string `json:"terms"`
+	Footer      string   `json:"footer"`
+}
+
+// Brand is printed in the header of every page
+type Brand struct {
+	Name    string `json:"name"`
+	Tagline string `json:"tagline"`
+	Address string `json:"address"`
+	Color   string `json:"color"`
+	Logo    string `json:"logo"`
+}
+
+// Field is a labelled line of the document. Value is a text/template
+// executed with Data.
+type Field struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// Data is what field values, terms and the footer can refer to
+type Data struct {
+	Booking  models.Booking
+	Seat     int
+	Code     string
+	IssuedAt string
+}
+
+var funcs = template.FuncMap{
+	"rupiah": FormatRupiah,
+	"upper":  strings.ToUpper,
+}
+
+// RenderInvoice renders the invoice of a booking
+func RenderInvoice(booking models.Booking) ([]byte, error) {
+	tpl, err := LoadTemplate("invoice.json")
+	if err != nil {
+		return nil, err
+	}
+
+	return render(tpl, booking)
+}
+
+// RenderTicket renders the e-ticket of a booking, one page per seat
+func RenderTicket(booking models.Booking) ([]byte, error) {
+	tpl, err := LoadTemplate("ticket.json")
+	if err != nil {
+		return nil, err
+	}
+
+	return render(tpl, booking)
+}
+
+// LoadTemplate reads a layout, from documents.template_dir when it has the
+// file and from the built-in templates otherwise. It is read on every call so
+// edits show up without restarting the server.
+func LoadTemplate(name string) (Template, error) {
+	var tpl Template
+
+	b, err := readTemplateFile(name)
+	if err != nil {
+		return tpl, err
+	}
+
+	if err := json.Unmarshal(b, &tpl); err != nil {
+		return tpl, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return tpl, nil
+}
+
+// readTemplateFile reads a template or an asset it refers to, such as the logo
+func readTemplateFile(name string) ([]byte, error) {
+	if dir := config.GetConfig().Documents.TemplateDir; dir != "" {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if !errors.Is(err, fs.ErrNotExist) {
+			return b, err
+		}
+	}
+
+	return builtin.ReadFile(path.Join("templates", filepath.ToSlash(name)))
+}
+
+// FormatRupiah formats an amount in minor units the Indonesian way, e.g.
+// 2500000 as "Rp 25.000"
+func FormatRupiah(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	whole := strconv.FormatInt(amount/100, 10)
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	result := sign + "Rp " + grouped.String()
+	if sen := amount % 100; sen != 0 {
+		result += fmt.Sprintf(",%02d", sen)
+	}
+
+	return result
+}
+
+func render(tpl Template, booking models.Booking) ([]byte, error) {
+	orientation := tpl.Orientation
+	if orientation == "" {
+		orientation = "P"
+	}
+
+	pageSize := tpl.PageSize
+	if pageSize == "" {
+		pageSize = "A4"
+	}
+
+	pdf := fpdf.New(orientation, "mm", pageSize, "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.SetTitle(tpl.Title, true)
+	pdf.SetCreator(tpl.Brand.Name, true)
+
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	data := Data{
+		Booking:  booking,
+		IssuedAt: time.Now().Format("02-01-2006 15:04"),
+	}
+
+	footer, err := execute(tpl.Footer, data)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(0, 5, tr(footer), "", 0, "C", false, 0, "")
+	})
+
+	seats := []int{0}
+	if tpl.ShowQR {
+		seats = seats[:0]
+		for seat := 1; seat <= booking.Qty; seat++ {
+			seats = append(seats, seat)
+		}
+	}
+
+	for _, seat := range seats {
+		data.Seat = seat
+		data.Code = ""
+		if seat > 0 {
+			data.Code = tickets.Code(booking.Id, seat)
+		}
+
+		if err := renderPage(pdf, tr, tpl, data); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func renderPage(pdf *fpdf.Fpdf, tr func(string) string, tpl Template, data Data) error {
+	pdf.AddPage()
+
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	contentWidth := pageWidth - left - right
+
+	r, g, b := parseColor(tpl.Brand.Color)
+
+	// Header band with the brand
+	pdf.SetFillColor(r, g, b)
+	pdf.Rect(0, 0, pageWidth, 32, "F")
+
+	textX := left
+	if tpl.Brand.Logo != "" {
+		if logo, err := readTemplateFile(tpl.Brand.Logo); err == nil {
+			options := fpdf.ImageOptions{ImageType: strings.TrimPrefix(path.Ext(tpl.Brand.Logo), "."), ReadDpi: true}
+			pdf.RegisterImageOptionsReader(tpl.Brand.Logo, options, bytes.NewReader(logo))
+			pdf.ImageOptions(tpl.Brand.Logo, left, 6, 0, 20, false, options, 0, "")
+			textX = left + 25
+		}
+	}
+
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetXY(textX, 8)
+	pdf.SetFont("Helvetica", "B", 15)
+	pdf.CellFormat(0, 7, tr(tpl.Brand.Name), "", 2, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.CellFormat(0, 5, tr(tpl.Brand.Tagline), "", 2, "L", false, 0, "")
+	pdf.CellFormat(0, 5, tr(tpl.Brand.Address), "", 2, "L", false, 0, "")
+
+	// Title
+	pdf.SetTextColor(r, g, b)
+	pdf.SetXY(left, 40)
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.CellFormat(0, 10, tr(tpl.Title), "", 1, "L", false, 0, "")
+	pdf.Ln(2)
+
+	// Fields
+	pdf.SetTextColor(40, 40, 40)
+	for _, field := range tpl.Fields {
+		value, err := execute(field.Value, data)
+		if err != nil {
+			return err
+		}
+
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(45, 7, tr(field.Label), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.MultiCell(contentWidth-45, 7, tr(value), "", "L", false)
+	}
+
+	if tpl.ShowItems {
+		renderItems(pdf, tr, data.Booking, contentWidth, r, g, b)
+	}
+
+	if tpl.ShowQR && data.Seat > 0 {
+		png, err := tickets.QRCode(data.Booking.Id, data.Seat)
+		if err != nil {
+			return err
+		}
+
+		name := "qr-" + strconv.Itoa(data.Seat)
+		pdf.RegisterImageOptionsReader(name, fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+
+		size := 60.0
+		pdf.Ln(6)
+		y := pdf.GetY()
+		pdf.ImageOptions(name, (pageWidth-size)/2, y, size, size, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+		pdf.SetY(y + size + 2)
+
+		pdf.SetFont("Courier", "", 9)
+		pdf.CellFormat(0, 5, data.Code, "", 1, "C", false, 0, "")
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.CellFormat(0, 7, tr(fmt.Sprintf("Seat %d of %d", data.Seat, data.Booking.Qty)), "", 1, "C", false, 0, "")
+	}
+
+	if len(tpl.Terms) > 0 {
+		pdf.Ln(8)
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(0, 6, tr(tpl.TermsTitle), "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 8)
+
+		for _, term := range tpl.Terms {
+			text, err := execute(term, data)
+			if err != nil {
+				return err
+			}
+
+			pdf.MultiCell(contentWidth, 4.5, tr("- "+text), "", "L", false)
+		}
+	}
+
+	return nil
+}
+
+func renderItems(pdf *fpdf.Fpdf, tr func(string) string, booking models.Booking, contentWidth float64, r int, g int, b int) {
+	widths := []float64{contentWidth - 95, 20, 37.5, 37.5}
+
+	pdf.Ln(6)
+	pdf.SetFillColor(r, g, b)
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetFont("Helvetica", "B", 10)
+
+	for i, header := range []string{"Description", "Qty", "Unit Price", "Amount"} {
+		align := "R"
+		if i == 0 {
+			align = "L"
+		}
+		pdf.CellFormat(widths[i], 8, header, "", 0, align, true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetTextColor(40, 40, 40)
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(widths[0], 8, tr(booking.DestinationName+" - "+booking.TanggalBooking), "B", 0, "L", false, 0, "")
+	pdf.CellFormat(widths[1], 8, strconv.Itoa(booking.Qty), "B", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[2], 8, FormatRupiah(booking.UnitPrice), "B", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[3], 8, FormatRupiah(booking.Subtotal), "B", 1, "R", false, 0, "")
+
+	labelWidth := widths[0] + widths[1] + widths[2]
+
+	totals := []struct {
+		label  string
+		amount int64
+	}{
+		{"Subtotal", booking.Subtotal},
+		{"Discount", -booking.Discount},
+		{"Tax", booking.Tax},
+	}
+
+	for _, line := range totals {
+		pdf.CellFormat(labelWidth, 7, line.label, "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[3], 7, FormatRupiah(line.amount), "", 1, "R", false, 0, "")
+	}
+
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(labelWidth, 8, "Total", "T", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[3], 8, FormatRupiah(booking.Total), "T", 1, "R", false, 0, "")
+}
+
+func execute(text string, data Data) (string, error) {
+	t, err := template.New("field").Funcs(funcs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// parseColor turns "#RRGGBB" into its components, falling back to a dark teal
+func parseColor(hex string) (int, int, int) {
+	hex = strings.TrimPrefix(hex, "#")
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 6 || err != nil {
+		return 11, 110, 79
+	}
+
+	return int(value >> 16 & 0xff), int(value >> 8 & 0xff), int(value & 0xff)
+}
@@ -0,0 +1,45 @@
+// Package logging builds the structured logger of the service and carries
+// the logger of a request on its context, so everything logged while
+// serving it shares the request id.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/bryansamperura/ticket-booking/config"
+)
+
+type contextKey struct{}
+
+// New returns a logger writing to w in the configured format and level
+func New(conf config.LoggingConfig, w io.Writer) *slog.Logger {
+	var level slog.Level
+
+	// The level was validated with the rest of the configuration
+	level.UnmarshalText([]byte(conf.Level))
+
+	options := &slog.HandlerOptions{Level: level}
+
+	if conf.Format == "text" {
+		return slog.New(slog.NewTextHandler(w, options))
+	}
+
+	return slog.New(slog.NewJSONHandler(w, options))
+}
+
+// WithLogger returns a copy of ctx carrying logger
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or the default logger when
+// there is none, e.g. outside of a request
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+
+	return slog.Default()
+}
@@ -0,0 +1,56 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/logging"
+)
+
+// LogMailer stands in for SMTP during development. Every message is written
+// to an .eml file in its directory and logged with its body, so links can be
+// followed without a mail server.
+type LogMailer struct {
+	from string
+	dir  string
+}
+
+func NewLogMailer(from string, dir string) *LogMailer {
+	return &LogMailer{from: from, dir: dir}
+}
+
+func (m *LogMailer) Send(ctx context.Context, message Message) error {
+	body, err := render(m.from, message)
+	if err != nil {
+		return err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err = rand.Read(suffix); err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(m.dir, 0755); err != nil {
+		return err
+	}
+
+	path := filepath.Join(m.dir, time.Now().Format("20060102-150405")+"-"+hex.EncodeToString(suffix)+".eml")
+
+	if err = os.WriteFile(path, body, 0600); err != nil {
+		return err
+	}
+
+	logging.FromContext(ctx).Info("mail written",
+		slog.String("to", message.To),
+		slog.String("subject", message.Subject),
+		slog.String("file", path),
+		slog.String("body", message.Body),
+	)
+
+	return nil
+}
@@ -0,0 +1,60 @@
+// Package mail sends the emails of the service, e.g. email verification and
+// password reset links. Production uses SMTP, development writes messages
+// to files and the log so no mail server is needed.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/mail"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/config"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers messages
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// New returns the mailer selected by conf.Driver
+func New(conf config.MailConfig) (Mailer, error) {
+	switch conf.Driver {
+	case "smtp":
+		return NewSMTPMailer(conf), nil
+	case "log":
+		return NewLogMailer(conf.From, conf.Dir), nil
+	}
+
+	return nil, fmt.Errorf("unknown mail driver %q", conf.Driver)
+}
+
+// render formats a message as RFC 5322 text
+func render(from string, message Message) ([]byte, error) {
+	if _, err := mail.ParseAddress(message.To); err != nil {
+		return nil, fmt.Errorf("invalid recipient %q: %w", message.To, err)
+	}
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", message.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(message.Body)
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,58 @@
+package mail
+
+import (
+	"context"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+
+	"github.com/bryansamperura/ticket-booking/config"
+)
+
+// SMTPMailer sends messages through an SMTP server, upgrading to TLS with
+// STARTTLS when the server offers it
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPMailer(conf config.MailConfig) *SMTPMailer {
+	mailer := &SMTPMailer{
+		addr: net.JoinHostPort(conf.SMTP.Host, strconv.Itoa(conf.SMTP.Port)),
+		from: conf.From,
+	}
+
+	if conf.SMTP.Username != "" {
+		mailer.auth = smtp.PlainAuth("", conf.SMTP.Username, conf.SMTP.Password, conf.SMTP.Host)
+	}
+
+	return mailer
+}
+
+// Send delivers message. net/smtp cannot be interrupted, ctx is only checked
+// before connecting.
+func (m *SMTPMailer) Send(ctx context.Context, message Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	body, err := render(m.from, message)
+	if err != nil {
+		return err
+	}
+
+	// The envelope needs the bare addresses, not "Name <address>"
+	from, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return err
+	}
+
+	to, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return err
+	}
+
+	return smtp.SendMail(m.addr, m.auth, from.Address, []string{to.Address}, body)
+}
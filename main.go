@@ -1,8 +1,30 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/bryansamperura/ticket-booking/auth"
+	"github.com/bryansamperura/ticket-booking/config"
+	"github.com/bryansamperura/ticket-booking/controllers"
 	"github.com/bryansamperura/ticket-booking/db"
+	"github.com/bryansamperura/ticket-booking/helper"
+	"github.com/bryansamperura/ticket-booking/logging"
+	"github.com/bryansamperura/ticket-booking/mail"
+	"github.com/bryansamperura/ticket-booking/payments"
+	"github.com/bryansamperura/ticket-booking/repository"
 	"github.com/bryansamperura/ticket-booking/routes"
+	"github.com/bryansamperura/ticket-booking/search"
+	"github.com/bryansamperura/ticket-booking/tickets"
+	"github.com/bryansamperura/ticket-booking/workers"
+	"github.com/labstack/echo/v4"
 )
 
 // @title API Documentation - Ticket Wisata Booking API
@@ -22,9 +44,120 @@ import (
 // Use the Authorization header with a Bearer token for authentication.
 
 func main() {
-	db.Init()
+	args, err := config.Init(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "migrate":
+			os.Exit(runMigrate(args[1:]))
+		case "seed":
+			os.Exit(runSeed(args[1:]))
+		default:
+			fmt.Fprintln(os.Stderr, "unknown command", args[0])
+			os.Exit(2)
+		}
+	}
+
+	conf := config.GetConfig()
+
+	logger := logging.New(conf.Logging, os.Stderr)
+	slog.SetDefault(logger)
+
+	if err := db.Init(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	repos := repository.NewMySQL(db.CreateConnection())
+
+	auth.Init(repos.Tokens)
+	payments.Init()
+	tickets.Init()
+
+	helper.PanicIfError(search.Rebuild(context.Background(), repos.Destinations))
+
+	// Stop on Ctrl+C or when the container is asked to terminate
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	expiryDone := workers.StartBookingExpiry(ctx, repos.Bookings, conf.Booking.ExpiryInterval.Duration)
+
+	mailer, err := mail.New(conf.Mail)
+	helper.PanicIfError(err)
+
+	handler := controllers.NewHandler(repos, mailer)
+	e := routes.Init(handler, logger)
+
+	serverErr := make(chan error, 1)
+
+	go func() {
+		serverErr <- serve(e, conf.Server)
+	}()
+
+	exitCode := 0
+
+	select {
+	case <-ctx.Done():
+		logger.Info("shutting down", slog.Duration("drain_timeout", conf.Server.ShutdownTimeout.Duration))
+	case err := <-serverErr:
+		logger.Error("server stopped", slog.Any("error", err))
+		exitCode = 1
+	}
+
+	// A second signal kills the process instead of waiting for the drain
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), conf.Server.ShutdownTimeout.Duration)
+
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		logger.Error("shutdown failed", slog.Any("error", err))
+		exitCode = 1
+	}
+
+	// Mails of the last requests are still being sent and need the database
+	if err := handler.Wait(shutdownCtx); err != nil {
+		logger.Error("background work did not finish", slog.Any("error", err))
+		exitCode = 1
+	}
+
+	cancel()
+
+	// The worker only stops between batches, so it may still use the pool
+	<-expiryDone
+
+	if err := db.Close(); err != nil {
+		logger.Error("closing the database failed", slog.Any("error", err))
+		exitCode = 1
+	}
+
+	os.Exit(exitCode)
+}
+
+// serve listens until the server is shut down, over HTTPS when a certificate
+// is configured
+func serve(e *echo.Echo, conf config.ServerConfig) error {
+	for _, server := range []*http.Server{e.Server, e.TLSServer} {
+		server.ReadTimeout = conf.ReadTimeout.Duration
+		server.WriteTimeout = conf.WriteTimeout.Duration
+		server.IdleTimeout = conf.IdleTimeout.Duration
+	}
+
+	var err error
+
+	if conf.TLS.Enabled() {
+		err = e.StartTLS(conf.Address(), conf.TLS.CertFile, conf.TLS.KeyFile)
+	} else {
+		err = e.Start(conf.Address())
+	}
 
-	e := routes.Init()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
 
-	e.Logger.Fatal(e.Start(":3000"))
+	return err
 }
@@ -0,0 +1,116 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/logging"
+	"github.com/labstack/echo/v4"
+)
+
+// RequestIDHeader carries the id correlating a request with its log lines
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the ids accepted from callers
+const maxRequestIDLength = 128
+
+// probes are logged at debug level, the orchestrator calls them all the time
+var probes = map[string]bool{"/healthz": true, "/readyz": true}
+
+// RequestID keeps the X-Request-ID sent by the caller, or makes one up when
+// it is missing or malformed, and returns it on the response
+func RequestID(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Request().Header.Get(RequestIDHeader)
+
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		c.Set("request_id", id)
+		c.Response().Header().Set(RequestIDHeader, id)
+
+		return next(c)
+	}
+}
+
+// RequestLogger puts a logger carrying the request id on the request context
+// and logs every request once it is answered. Server errors are logged with
+// the error behind them. It must be registered after RequestID.
+func RequestLogger(logger *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			requestLogger := logger.With(slog.Any("request_id", c.Get("request_id")))
+
+			request := c.Request()
+			c.SetRequest(request.WithContext(logging.WithLogger(request.Context(), requestLogger)))
+
+			err := next(c)
+			if err != nil {
+				// Let the error handler answer now so the status can be logged
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+
+			attrs := []slog.Attr{
+				slog.String("method", request.Method),
+				slog.String("route", c.Path()),
+				slog.String("path", request.URL.Path),
+				slog.Int("status", status),
+				slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			}
+
+			if uid, ok := c.Get("uid").(int); ok {
+				attrs = append(attrs, slog.Int("user_id", uid))
+			}
+
+			if role, ok := c.Get("role").(string); ok {
+				attrs = append(attrs, slog.String("role", role))
+			}
+
+			level := slog.LevelInfo
+
+			if probes[c.Path()] {
+				level = slog.LevelDebug
+			}
+
+			if status >= 500 {
+				level = slog.LevelError
+
+				if err != nil {
+					attrs = append(attrs, slog.String("error", err.Error()))
+				}
+			}
+
+			requestLogger.LogAttrs(c.Request().Context(), level, "request", attrs...)
+
+			return nil
+		}
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range id {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
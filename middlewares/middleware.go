@@ -1,41 +1,147 @@
 package middlewares
 
 import (
-	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/bryansamperura/ticket-booking/apperror"
+	"github.com/bryansamperura/ticket-booking/auth"
 	"github.com/labstack/echo/v4"
-	"github.com/o1egl/paseto"
 )
 
-type CustomClaims struct {
-	UserID int    `json:"uid"`
-	Role   string `json:"role"`
-}
+const (
+	RoleAdmin    = "admin"
+	RoleCustomer = "customer"
+	RoleStaff    = "staff"
+)
+
+var (
+	ErrTokenMissing   = apperror.Unauthorized("token_missing", "Token is missing")
+	ErrTokenMalformed = apperror.Unauthorized("token_malformed", "Invalid token format")
+	ErrTokenInvalid   = apperror.Unauthorized("token_invalid", "Invalid token")
+	ErrTokenRevoked   = apperror.Unauthorized("token_revoked", "Token has been revoked")
+	ErrForbidden      = apperror.Forbidden("forbidden", "Forbidden")
+	ErrInvalidID      = apperror.Validation("invalid_id", "Invalid ID")
+)
+
+type CustomClaims = auth.Claims
 
 func AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		token := c.Request().Header.Get("Authorization")
 
 		if token == "" {
-			return echo.NewHTTPError(http.StatusUnauthorized, "Token is missing")
+			return ErrTokenMissing
 		}
 
 		// Split the token string by space to get the actual token value
 		tokenParts := strings.Fields(token)
 		if len(tokenParts) != 2 {
-			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid token format")
+			return ErrTokenMalformed
 		}
 
 		// Extract the actual token value
 		actualToken := tokenParts[1]
 
-		var claims CustomClaims
+		claims, err := auth.ParseAccessToken(actualToken)
+		if err != nil {
+			return ErrTokenInvalid
+		}
+
+		revoked, err := auth.IsRevoked(c.Request().Context(), claims)
+		if err != nil {
+			return err
+		}
 
-		if err := paseto.NewV2().Decrypt(actualToken, []byte("YELLOW SUBMARINE, BLACK WIZARDRY"), &claims, nil); err != nil {
-			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid token")
+		if revoked {
+			return ErrTokenRevoked
 		}
 
+		// Keep the claims on the context so handlers and the authorization
+		// middlewares below can tell who is calling
+		c.Set("claims", claims)
+		c.Set("uid", claims.UserID)
+		c.Set("role", claims.Role)
+
 		return next(c)
 	}
 }
+
+// GetClaims returns the claims stored on the context by AuthMiddleware
+func GetClaims(c echo.Context) (CustomClaims, bool) {
+	claims, ok := c.Get("claims").(CustomClaims)
+
+	return claims, ok
+}
+
+// HasRole reports whether the authenticated user has one of the given roles
+func HasRole(c echo.Context, roles ...string) bool {
+	claims, ok := GetClaims(c)
+	if !ok {
+		return false
+	}
+
+	for _, role := range roles {
+		if claims.Role == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsOwner reports whether the authenticated user is the customer with the given id
+func IsOwner(c echo.Context, customerID int) bool {
+	claims, ok := GetClaims(c)
+	if !ok {
+		return false
+	}
+
+	return claims.Role == RoleCustomer && claims.UserID == customerID
+}
+
+// RequireRole only lets users with one of the given roles through.
+// It must be registered after AuthMiddleware.
+func RequireRole(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if _, ok := GetClaims(c); !ok {
+				return ErrTokenMissing
+			}
+
+			if !HasRole(c, roles...) {
+				return ErrForbidden
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// RequireOwnerOrRole lets a customer through when the path parameter named
+// param is their own customer id, and users with one of the given roles
+// through for any id. It must be registered after AuthMiddleware.
+func RequireOwnerOrRole(param string, roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if _, ok := GetClaims(c); !ok {
+				return ErrTokenMissing
+			}
+
+			if HasRole(c, roles...) {
+				return next(c)
+			}
+
+			id, err := strconv.Atoi(c.Param(param))
+			if err != nil {
+				return ErrInvalidID
+			}
+
+			if !IsOwner(c, id) {
+				return ErrForbidden
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package middlewares
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/apperror"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"golang.org/x/time/rate"
+)
+
+// ErrRateLimited is returned when a client makes too many requests
+var ErrRateLimited = apperror.TooManyRequests("rate_limited", "Too many requests, try again later")
+
+// RateLimit lets every client IP make perMinute requests a minute, after a
+// burst of burst requests. Clients are forgotten after ten idle minutes.
+func RateLimit(perMinute int, burst int) echo.MiddlewareFunc {
+	store := middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+		Rate:      rate.Limit(float64(perMinute) / 60),
+		Burst:     burst,
+		ExpiresIn: 10 * time.Minute,
+	})
+
+	// Seconds until the next request is allowed
+	retryAfter := strconv.Itoa(int(math.Ceil(60 / float64(perMinute))))
+
+	return middleware.RateLimiterWithConfig(middleware.RateLimiterConfig{
+		Store: store,
+		IdentifierExtractor: func(c echo.Context) (string, error) {
+			return c.RealIP(), nil
+		},
+		DenyHandler: func(c echo.Context, identifier string, err error) error {
+			c.Response().Header().Set("Retry-After", retryAfter)
+			return ErrRateLimited
+		},
+	})
+}
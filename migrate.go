@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/bryansamperura/ticket-booking/db"
+)
+
+const migrateUsage = `usage: ticket-booking migrate <command>
+
+commands:
+  up             apply every pending migration
+  down [steps]   revert the last applied migrations, 1 by default
+  status         list migrations and when they were applied
+  create <name>  add empty up and down files to ` + db.MigrationsDir
+
+// runMigrate runs the migrate sub-command and returns the exit code
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	ctx := context.Background()
+
+	// Creating a migration only touches the source tree
+	if args[0] == "create" {
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			return 2
+		}
+
+		paths, err := db.CreateMigration(db.MigrationsDir, args[1])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		for _, path := range paths {
+			fmt.Println("created", path)
+		}
+
+		return 0
+	}
+
+	con, err := db.Open()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	defer con.Close()
+
+	switch args[0] {
+	case "up":
+		done, err := db.MigrateUp(ctx, con)
+
+		for _, migration := range done {
+			fmt.Printf("applied  %04d_%s\n", migration.Version, migration.Name)
+		}
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		if len(done) == 0 {
+			fmt.Println("schema is up to date")
+		}
+	case "down":
+		steps := 1
+
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				fmt.Fprintln(os.Stderr, "steps must be a positive number")
+				return 2
+			}
+		}
+
+		done, err := db.MigrateDown(ctx, con, steps)
+
+		for _, migration := range done {
+			fmt.Printf("reverted %04d_%s\n", migration.Version, migration.Name)
+		}
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	case "status":
+		statuses, err := db.MigrationStatuses(ctx, con)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = "applied " + *status.AppliedAt
+			}
+
+			fmt.Printf("%04d_%-40s %s\n", status.Version, status.Name, appliedAt)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	return 0
+}
@@ -1,15 +1,15 @@
 package models
 
 type AuthRegisterRequest struct {
-	Fullname  string `json:"fullname"`
-	Email     string `json:"email"`
-	Phone     string `json:"phone"`
-	Password  string `json:"password"`
+	Fullname  string `json:"fullname" validate:"required,max=255"`
+	Email     string `json:"email" validate:"required,email,max=255"`
+	Phone     string `json:"phone" example:"+6281234567890" validate:"required,phone_id"`
+	Password  string `json:"password" validate:"required,min=8,max=72,not_breached"`
 	Role      string `json:"role"`
 	AccountId string `json:"account_id"`
 }
 
 type AuthRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required,max=255"`
+	Password string `json:"password" validate:"required,max=72"`
 }
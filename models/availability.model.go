@@ -0,0 +1,71 @@
+package models
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/config"
+)
+
+// DateLayout is the format of booking and availability dates
+const DateLayout = "2006-01-02"
+
+// MaxAvailabilityDays is the longest range that can be requested at once
+const MaxAvailabilityDays = 92
+
+// Today returns the current date in database.location, the time zone the
+// business runs in, at midnight UTC. That is the same form time.Parse gives
+// for DateLayout, so the two can be compared directly.
+func Today() time.Time {
+	now := time.Now().In(businessLocation())
+
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+var locations = struct {
+	sync.Mutex
+	byName map[string]*time.Location
+}{byName: make(map[string]*time.Location)}
+
+// businessLocation loads database.location once. The configuration is
+// validated at startup, UTC is only a fallback for an invalid one.
+func businessLocation() *time.Location {
+	name := config.GetConfig().Database.Location
+
+	locations.Lock()
+	defer locations.Unlock()
+
+	location, ok := locations.byName[name]
+	if !ok {
+		var err error
+
+		location, err = time.LoadLocation(name)
+		if err != nil {
+			location = time.UTC
+		}
+
+		locations.byName[name] = location
+	}
+
+	return location
+}
+
+// Availability is the number of tickets left for a destination on one day.
+// Quota and Remaining are null when the destination has no daily limit.
+type Availability struct {
+	Date      string `json:"date"`
+	Quota     *int   `json:"quota"`
+	Sold      int    `json:"sold"`
+	Remaining *int   `json:"remaining"`
+	Closed    bool   `json:"closed"`
+	Note      string `json:"note,omitempty"`
+}
+
+type DailyQuotaRequest struct {
+	DailyQuota *int `json:"daily_quota" validate:"omitempty,gte=0"`
+}
+
+type QuotaOverrideRequest struct {
+	Quota int    `json:"quota" validate:"gte=0"`
+	Note  string `json:"note" validate:"max=255"`
+}
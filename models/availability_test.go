@@ -0,0 +1,23 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/config"
+)
+
+func TestTodayUsesDatabaseLocation(t *testing.T) {
+	location, err := time.LoadLocation(config.GetConfig().Database.Location)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := time.Now().In(location).Format(DateLayout)
+	today := Today().Format(DateLayout)
+	after := time.Now().In(location).Format(DateLayout)
+
+	if today != before && today != after {
+		t.Errorf("Today() = %s, want %s in %s", today, before, location)
+	}
+}
@@ -1,159 +1,70 @@
 package models
 
 import (
-	"net/http"
+	"time"
 
-	"github.com/bryansamperura/ticket-booking/db"
-	"github.com/go-playground/validator/v10"
+	"github.com/bryansamperura/ticket-booking/config"
 )
 
 type Booking struct {
-	Id              int    `json:"id"`
-	CustomerName    string `json:"customer_name"`
-	Qty             int    `json:"qty"`
-	DestinationName string `json:"destination_name"`
-	Price           int    `json:"price"`
-	TanggalBooking  string `json:"booking_date"`
+	Id              int     `json:"id"`
+	CustomerID      int     `json:"customer_id"`
+	CustomerName    string  `json:"customer_name"`
+	Qty             int     `json:"qty"`
+	DestinationID   int     `json:"destination_id"`
+	DestinationName string  `json:"destination_name"`
+	CityName        string  `json:"city_name"`
+	TanggalBooking  string  `json:"booking_date"`
+	Currency        string  `json:"currency"`
+	UnitPrice       int64   `json:"unit_price"`
+	Subtotal        int64   `json:"subtotal"`
+	Discount        int64   `json:"discount"`
+	Tax             int64   `json:"tax"`
+	Total           int64   `json:"total"`
+	Status          string  `json:"status"`
+	CreatedAt       string  `json:"created_at"`
+	ExpiresAt       *string `json:"expires_at"`
+	ConfirmedAt     *string `json:"confirmed_at"`
+	CheckedInAt     *string `json:"checked_in_at"`
+	CancelledAt     *string `json:"cancelled_at"`
+	ExpiredAt       *string `json:"expired_at"`
+	RefundedAt      *string `json:"refunded_at"`
 }
 
-type BookingRequest struct {
-	CustomerID     int    `json:"customer_id"`
-	Qty            int    `json:"qty"`
-	DestinationID  int    `json:"destination_id"`
-	TanggalBooking string `json:"booking_date"`
-}
-
-func FindAllBooking() (Response, error) {
-	var obj Booking
-	var arrObj []Booking
-	var res Response
-
-	con := db.CreateConnection()
-
-	sqlStatement := `SELECT 
-						booking.id,
-						customers.fullname, 
-						booking.qty, 
-						destination.destination_name, 
-						destination.price, 
-						booking.booking_date 
-					FROM booking 
-					JOIN 
-						destination ON destination.id = booking.destination_id 
-					JOIN 
-						customers ON customers.id = booking.customer_id`
-
-	rows, err := con.Query(sqlStatement)
-
-	defer rows.Close()
-
-	if err != nil {
-		return res, err
-	}
-
-	for rows.Next() {
-		err = rows.Scan(&obj.Id, &obj.CustomerName, &obj.Qty, &obj.DestinationName, &obj.Price, &obj.TanggalBooking)
-		if err != nil {
-			return res, err
-		}
-		arrObj = append(arrObj, obj)
+// HoldExpiresAt returns when the hold of the booking on its tickets runs
+// out, or the zero time when it has none
+func (b Booking) HoldExpiresAt() time.Time {
+	if b.ExpiresAt == nil {
+		return time.Time{}
 	}
 
-	res.Status = http.StatusOK
-	res.Message = "OK"
-	res.Data = arrObj
-
-	return res, nil
-}
-
-func FindBookingById(customer_id int) (Response, error) {
-	var obj Booking
-	var arrObj []Booking
-	var res Response
-
-	con := db.CreateConnection()
-
-	sqlStatement := `SELECT 
-						booking.id,
-						customers.fullname, 
-						booking.qty, 
-						destination.destination_name, 
-						destination.price, 
-						booking.booking_date 
-					FROM booking 
-					JOIN 
-						destination ON destination.id = booking.destination_id 
-					JOIN 
-						customers ON customers.id = booking.customer_id
-					WHERE booking.customer_id = ?`
-
-	rows, err := con.Query(sqlStatement, customer_id)
-
-	defer rows.Close()
-
+	expiresAt, err := time.Parse(time.RFC3339Nano, *b.ExpiresAt)
 	if err != nil {
-		return res, err
+		return time.Time{}
 	}
 
-	for rows.Next() {
-		err = rows.Scan(&obj.Id, &obj.CustomerName, &obj.Qty, &obj.DestinationName, &obj.Price, &obj.TanggalBooking)
-		if err != nil {
-			return res, err
-		}
-		arrObj = append(arrObj, obj)
-	}
-
-	res.Status = http.StatusOK
-	res.Message = "OK"
-	res.Data = arrObj
-
-	return res, nil
+	return expiresAt
 }
 
-func StoreBooking(customer_id int, qty int, destination_id int, booking_date string) (Response, error) {
-	var res Response
-
-	v := validator.New()
-
-	booking := BookingRequest{
-		CustomerID:     customer_id,
-		Qty:            qty,
-		DestinationID:  destination_id,
-		TanggalBooking: booking_date,
-	}
-
-	err := v.Struct(booking)
-
-	if err != nil {
-		return res, err
-	}
-
-	con := db.CreateConnection()
-
-	sqlStatement := "INSERT INTO booking(customer_id, qty, destination_id,booking_date) VALUES (?, ?, ?,?)"
-
-	stmt, err := con.Prepare(sqlStatement)
-
-	if err != nil {
-		return res, err
-	}
-
-	result, err := stmt.Exec(customer_id, qty, destination_id, booking_date)
+type BookingRequest struct {
+	CustomerID     int    `json:"customer_id" validate:"gt=0"`
+	Qty            int    `json:"qty" validate:"gt=0,lte=100"`
+	DestinationID  int    `json:"destination_id" validate:"gt=0"`
+	TanggalBooking string `json:"booking_date" example:"2026-12-24" validate:"required,date,not_past"`
+}
 
-	if err != nil {
-		return res, err
-	}
+// BookingPolicy holds the configurable rules applied to new bookings
+type BookingPolicy struct {
+	TaxRateBps  int64
+	HoldMinutes int
+}
 
-	lastInsertedId, err := result.LastInsertId()
-	if err != nil {
-		return res, err
-	}
+// DefaultBookingPolicy reads the booking rules from the configuration
+func DefaultBookingPolicy() BookingPolicy {
+	conf := config.GetConfig().Booking
 
-	res.Status = http.StatusCreated
-	res.Message = "Inserted"
-	res.Data = map[string]int64{
-		"id": lastInsertedId,
+	return BookingPolicy{
+		TaxRateBps:  conf.TaxRateBps,
+		HoldMinutes: conf.HoldMinutes,
 	}
-
-	return res, nil
 }
@@ -0,0 +1,47 @@
+package models
+
+const (
+	BookingPending   = "pending"
+	BookingConfirmed = "confirmed"
+	BookingCheckedIn = "checked_in"
+	BookingCancelled = "cancelled"
+	BookingExpired   = "expired"
+	BookingRefunded  = "refunded"
+)
+
+// bookingTransitions lists the states a booking may move to from each state.
+// checked_in and refunded are final. Expired bookings can only be refunded,
+// for when a payment arrives after the hold ran out.
+var bookingTransitions = map[string][]string{
+	BookingPending:   {BookingConfirmed, BookingCancelled, BookingExpired},
+	BookingConfirmed: {BookingCheckedIn, BookingCancelled, BookingRefunded},
+	BookingCancelled: {BookingRefunded},
+	BookingExpired:   {BookingRefunded},
+}
+
+// IsValidBookingStatus reports whether status is one of the booking states
+func IsValidBookingStatus(status string) bool {
+	switch status {
+	case BookingPending, BookingConfirmed, BookingCheckedIn, BookingCancelled, BookingExpired, BookingRefunded:
+		return true
+	}
+
+	return false
+}
+
+// CanTransitionBooking reports whether a booking may move from one state to another
+func CanTransitionBooking(from string, to string) bool {
+	for _, allowed := range bookingTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HoldsCapacity reports whether a booking in the given state occupies
+// tickets in the destination's availability
+func HoldsCapacity(status string) bool {
+	return status == BookingPending || status == BookingConfirmed || status == BookingCheckedIn
+}
@@ -0,0 +1,38 @@
+package models
+
+type CheckInRequest struct {
+	Code          string `json:"code" validate:"required,max=512"`
+	DestinationID int    `json:"destination_id" validate:"gt=0"`
+}
+
+type CheckIn struct {
+	BookingID      int    `json:"booking_id"`
+	Seat           int    `json:"seat"`
+	Qty            int    `json:"qty"`
+	SeatsCheckedIn int    `json:"seats_checked_in"`
+	BookingDate    string `json:"booking_date"`
+	Status         string `json:"status"`
+}
+
+// CanCheckIn tells whether a seat of the booking may be let in at the gate
+// of the destination today. A seat the booking does not have gives
+// ErrBookingNotFound, as if the ticket did not exist.
+func CanCheckIn(booking Booking, seat int, destinationID int) error {
+	if booking.Status != BookingConfirmed && booking.Status != BookingCheckedIn {
+		return ErrTicketNotConfirmed
+	}
+
+	if seat > booking.Qty {
+		return ErrBookingNotFound
+	}
+
+	if booking.DestinationID != destinationID {
+		return ErrTicketWrongDestination
+	}
+
+	if booking.TanggalBooking != Today().Format(DateLayout) {
+		return ErrTicketWrongDate
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCanCheckIn(t *testing.T) {
+	today := Today().Format(DateLayout)
+	tomorrow := Today().AddDate(0, 0, 1).Format(DateLayout)
+
+	tests := []struct {
+		name    string
+		booking Booking
+		seat    int
+		wantErr error
+	}{
+		{name: "confirmed for today", booking: Booking{Status: BookingConfirmed, DestinationID: 1, TanggalBooking: today, Qty: 2}, seat: 2},
+		{name: "partly checked in", booking: Booking{Status: BookingCheckedIn, DestinationID: 1, TanggalBooking: today, Qty: 2}, seat: 1},
+		{name: "pending", booking: Booking{Status: BookingPending, DestinationID: 1, TanggalBooking: today, Qty: 2}, seat: 1, wantErr: ErrTicketNotConfirmed},
+		{name: "refunded", booking: Booking{Status: BookingRefunded, DestinationID: 1, TanggalBooking: today, Qty: 2}, seat: 1, wantErr: ErrTicketNotConfirmed},
+		{name: "seat past qty", booking: Booking{Status: BookingConfirmed, DestinationID: 1, TanggalBooking: today, Qty: 2}, seat: 3, wantErr: ErrBookingNotFound},
+		{name: "other destination", booking: Booking{Status: BookingConfirmed, DestinationID: 2, TanggalBooking: today, Qty: 2}, seat: 1, wantErr: ErrTicketWrongDestination},
+		{name: "other day", booking: Booking{Status: BookingConfirmed, DestinationID: 1, TanggalBooking: tomorrow, Qty: 2}, seat: 1, wantErr: ErrTicketWrongDate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CanCheckIn(tt.booking, tt.seat, 1); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CanCheckIn() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package models
+
+import "github.com/bryansamperura/ticket-booking/apperror"
+
+var (
+	ErrDestinationNotFound = apperror.NotFound("destination_not_found", "Destination not found")
+	ErrSoldOut             = apperror.Conflict("sold_out", "Sold out for the selected date")
+	ErrBookingNotFound     = apperror.NotFound("booking_not_found", "Booking not found")
+	ErrInvalidTransition   = apperror.Conflict("invalid_status_transition", "Invalid booking status transition")
+
+	ErrTicketNotConfirmed     = apperror.Unprocessable("ticket_not_confirmed", "Ticket belongs to a booking that is not confirmed")
+	ErrTicketWrongDestination = apperror.Unprocessable("ticket_wrong_destination", "Ticket is for another destination")
+	ErrTicketWrongDate        = apperror.Unprocessable("ticket_wrong_date", "Ticket is not valid today")
+	ErrTicketAlreadyUsed      = apperror.Conflict("ticket_already_used", "Ticket has already been used")
+)
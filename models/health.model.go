@@ -0,0 +1,8 @@
+package models
+
+// Health is the body of the health endpoints. Checks maps every dependency
+// to "ok" or to what is wrong with it.
+type Health struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
@@ -1,6 +0,0 @@
-package models
-
-type HTTPError struct {
-	Status  int    `json:"status"`
-	Message string `json:"message"`
-}
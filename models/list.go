@@ -0,0 +1,443 @@
+package models
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/apperror"
+)
+
+const (
+	DefaultPerPage = 20
+	MaxPerPage     = 100
+)
+
+// ErrInvalidListQuery is returned for unknown sort fields and malformed
+// paging or filter parameters
+var ErrInvalidListQuery = apperror.Validation("invalid_list_query", "invalid list query")
+
+// Filter operators
+const (
+	FilterEq   = "eq"
+	FilterLike = "like"
+	FilterGte  = "gte"
+	FilterLte  = "lte"
+)
+
+// Filter value types
+const (
+	FilterInt    = "int"
+	FilterString = "string"
+	FilterDate   = "date"
+)
+
+// FilterSpec maps a query parameter onto a column
+type FilterSpec struct {
+	Column string
+	Op     string
+	Type   string
+	// Valid optionally restricts the accepted values
+	Valid func(string) bool
+}
+
+// ListSpec whitelists how a list endpoint can be sorted and filtered. Sort
+// columns belong to Table, which is also where cursors are looked up, so
+// they must not be nullable.
+type ListSpec struct {
+	Table    string
+	Sortable map[string]string
+	Filters  map[string]FilterSpec
+}
+
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// Filter is a condition on a column. Value is an int or a string, for
+// FilterLike the text the column must contain.
+type Filter struct {
+	Column string
+	Op     string
+	Value  interface{}
+}
+
+// ListOptions are the parsed paging, sorting and filtering parameters of a
+// list request. Either Page or, when UseCursor is set, Cursor selects the
+// rows to return.
+type ListOptions struct {
+	Page      int
+	PerPage   int
+	UseCursor bool
+	Cursor    int
+	Sort      []SortField
+
+	table   string
+	filters []Filter
+}
+
+// Pagination is returned with every list response
+type Pagination struct {
+	Total      int    `json:"total"`
+	Page       int    `json:"page,omitempty"`
+	PerPage    int    `json:"per_page"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Next       string `json:"next,omitempty"`
+	Prev       string `json:"prev,omitempty"`
+}
+
+var CityListSpec = ListSpec{
+	Table: "cities",
+	Sortable: map[string]string{
+		"id":   "id",
+		"city": "city_name",
+	},
+	Filters: map[string]FilterSpec{
+		"city": {Column: "cities.city_name", Op: FilterLike, Type: FilterString},
+	},
+}
+
+var CustomerListSpec = ListSpec{
+	Table: "customers",
+	Sortable: map[string]string{
+		"id":       "id",
+		"fullname": "fullname",
+		"email":    "email",
+	},
+	Filters: map[string]FilterSpec{
+		"fullname": {Column: "customers.fullname", Op: FilterLike, Type: FilterString},
+		"email":    {Column: "customers.email", Op: FilterLike, Type: FilterString},
+		"phone":    {Column: "customers.phone", Op: FilterLike, Type: FilterString},
+	},
+}
+
+var AdminListSpec = ListSpec{
+	Table: "admin",
+	Sortable: map[string]string{
+		"id":       "id",
+		"fullname": "fullname",
+		"email":    "email",
+	},
+	Filters: map[string]FilterSpec{
+		"fullname": {Column: "admin.fullname", Op: FilterLike, Type: FilterString},
+		"email":    {Column: "admin.email", Op: FilterLike, Type: FilterString},
+	},
+}
+
+var DestinationListSpec = ListSpec{
+	Table: "destination",
+	Sortable: map[string]string{
+		"id":               "id",
+		"destination_name": "destination_name",
+		"price":            "price",
+		"city_id":          "city_id",
+	},
+	Filters: map[string]FilterSpec{
+		"city_id":          {Column: "destination.city_id", Op: FilterEq, Type: FilterInt},
+		"min_price":        {Column: "destination.price", Op: FilterGte, Type: FilterInt},
+		"max_price":        {Column: "destination.price", Op: FilterLte, Type: FilterInt},
+		"destination_name": {Column: "destination.destination_name", Op: FilterLike, Type: FilterString},
+	},
+}
+
+var BookingListSpec = ListSpec{
+	Table: "booking",
+	Sortable: map[string]string{
+		"id":           "id",
+		"booking_date": "booking_date",
+		"qty":          "qty",
+		"total":        "total",
+		"status":       "status",
+		"created_at":   "created_at",
+	},
+	Filters: map[string]FilterSpec{
+		"status":         {Column: "booking.status", Op: FilterEq, Type: FilterString, Valid: IsValidBookingStatus},
+		"customer_id":    {Column: "booking.customer_id", Op: FilterEq, Type: FilterInt},
+		"destination_id": {Column: "booking.destination_id", Op: FilterEq, Type: FilterInt},
+		"from":           {Column: "booking.booking_date", Op: FilterGte, Type: FilterDate},
+		"to":             {Column: "booking.booking_date", Op: FilterLte, Type: FilterDate},
+	},
+}
+
+// ParseListOptions reads ?page=&per_page=, ?cursor=, ?sort=price,-id and the
+// filters allowed by spec from a query string. Sending cursor, even empty,
+// switches to cursor pagination.
+func ParseListOptions(query url.Values, spec ListSpec) (ListOptions, error) {
+	opts := ListOptions{
+		Page:    1,
+		PerPage: DefaultPerPage,
+		table:   spec.Table,
+	}
+
+	if value := query.Get("per_page"); value != "" {
+		perPage, err := strconv.Atoi(value)
+		if err != nil || perPage < 1 || perPage > MaxPerPage {
+			return opts, fmt.Errorf("%w: per_page must be between 1 and %d", ErrInvalidListQuery, MaxPerPage)
+		}
+		opts.PerPage = perPage
+	}
+
+	if value := query.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 {
+			return opts, fmt.Errorf("%w: page must be a positive number", ErrInvalidListQuery)
+		}
+		opts.Page = page
+	}
+
+	if _, ok := query["cursor"]; ok {
+		opts.UseCursor = true
+		opts.Page = 0
+
+		if value := query.Get("cursor"); value != "" {
+			cursor, err := DecodeCursor(value)
+			if err != nil {
+				return opts, err
+			}
+			opts.Cursor = cursor
+		}
+	}
+
+	if value := query.Get("sort"); value != "" {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			desc := strings.HasPrefix(name, "-")
+			name = strings.TrimPrefix(name, "-")
+
+			column, ok := spec.Sortable[name]
+			if !ok {
+				return opts, fmt.Errorf("%w: cannot sort by %q", ErrInvalidListQuery, name)
+			}
+
+			opts.Sort = append(opts.Sort, SortField{Column: column, Desc: desc})
+		}
+	}
+
+	for name, filter := range spec.Filters {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+
+		if filter.Valid != nil && !filter.Valid(value) {
+			return opts, fmt.Errorf("%w: invalid %s", ErrInvalidListQuery, name)
+		}
+
+		var typed interface{} = value
+
+		switch filter.Type {
+		case FilterInt:
+			number, err := strconv.Atoi(value)
+			if err != nil {
+				return opts, fmt.Errorf("%w: %s must be a number", ErrInvalidListQuery, name)
+			}
+			typed = number
+		case FilterDate:
+			if _, err := time.Parse(DateLayout, value); err != nil {
+				return opts, fmt.Errorf("%w: %s must be a date (YYYY-MM-DD)", ErrInvalidListQuery, name)
+			}
+		}
+
+		opts.filters = append(opts.filters, Filter{Column: filter.Column, Op: filter.Op, Value: typed})
+	}
+
+	return opts, nil
+}
+
+// EncodeCursor turns the id of the last row of a page into an opaque cursor
+func EncodeCursor(id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(id)))
+}
+
+// DecodeCursor reads a cursor made by EncodeCursor
+func DecodeCursor(cursor string) (int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("%w: malformed cursor", ErrInvalidListQuery)
+	}
+
+	id, err := strconv.Atoi(string(b))
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("%w: malformed cursor", ErrInvalidListQuery)
+	}
+
+	return id, nil
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// where returns the filter conditions, plus the keyset condition when
+// withCursor is set
+func (o ListOptions) where(withCursor bool) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	for _, filter := range o.filters {
+		value := filter.Value
+
+		switch filter.Op {
+		case FilterEq:
+			conditions = append(conditions, filter.Column+" = ?")
+		case FilterLike:
+			conditions = append(conditions, filter.Column+" LIKE ?")
+			value = "%" + escapeLike(value.(string)) + "%"
+		case FilterGte:
+			conditions = append(conditions, filter.Column+" >= ?")
+		case FilterLte:
+			conditions = append(conditions, filter.Column+" <= ?")
+		}
+		args = append(args, value)
+	}
+
+	if withCursor && o.UseCursor && o.Cursor > 0 {
+		condition, cursorArgs := o.keyset()
+		conditions = append(conditions, condition)
+		args = append(args, cursorArgs...)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// keyset selects the rows after the cursor row in sort order. The values
+// of the cursor row are looked up by id, so a cursor stays valid when rows
+// are inserted before it.
+func (o ListOptions) keyset() (string, []interface{}) {
+	var alternatives []string
+	var args []interface{}
+
+	var equal []string
+	var equalArgs []interface{}
+
+	for _, field := range o.SortFields() {
+		lookup := fmt.Sprintf("(SELECT %s FROM %s WHERE id = ?)", field.Column, o.table)
+		column := o.table + "." + field.Column
+
+		op := ">"
+		if field.Desc {
+			op = "<"
+		}
+
+		parts := append(append([]string{}, equal...), column+" "+op+" "+lookup)
+		alternatives = append(alternatives, "("+strings.Join(parts, " AND ")+")")
+		args = append(append(args, equalArgs...), o.Cursor)
+
+		equal = append(equal, column+" = "+lookup)
+		equalArgs = append(equalArgs, o.Cursor)
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")", args
+}
+
+// Filters returns the conditions parsed from the query string
+func (o ListOptions) Filters() []Filter {
+	return o.filters
+}
+
+// SortFields is the requested order with id appended as a tie breaker so
+// pages never overlap
+func (o ListOptions) SortFields() []SortField {
+	fields := append([]SortField{}, o.Sort...)
+
+	for _, field := range fields {
+		if field.Column == "id" {
+			return fields
+		}
+	}
+
+	return append(fields, SortField{Column: "id"})
+}
+
+func (o ListOptions) orderBy() string {
+	var parts []string
+
+	for _, field := range o.SortFields() {
+		part := o.table + "." + field.Column
+		if field.Desc {
+			part += " DESC"
+		}
+		parts = append(parts, part)
+	}
+
+	return " ORDER BY " + strings.Join(parts, ", ")
+}
+
+// Query appends the conditions, order and limit of the options to a SELECT
+// without a WHERE clause. One row more than a page is fetched to tell
+// whether there is a next page.
+func (o ListOptions) Query(selectStatement string) (string, []interface{}) {
+	where, args := o.where(true)
+
+	sqlStatement := selectStatement + where + o.orderBy() + " LIMIT ?"
+	args = append(args, o.PerPage+1)
+
+	if !o.UseCursor {
+		sqlStatement += " OFFSET ?"
+		args = append(args, (o.Page-1)*o.PerPage)
+	}
+
+	return sqlStatement, args
+}
+
+// CountQuery returns the statement counting the rows that match the filters
+// of the options, fromStatement being the FROM clause of the list
+func (o ListOptions) CountQuery(fromStatement string) (string, []interface{}) {
+	where, args := o.where(false)
+
+	return "SELECT COUNT(*) " + fromStatement + where, args
+}
+
+// Page is one page of a list along with its pagination
+type Page[T any] struct {
+	Items      []T
+	Pagination Pagination
+}
+
+// NewPage trims the extra row fetched by Query and works out the pagination
+func NewPage[T any](items []T, total int, opts ListOptions, idOf func(T) int) Page[T] {
+	hasNext := len(items) > opts.PerPage
+	if hasNext {
+		items = items[:opts.PerPage]
+	}
+
+	if items == nil {
+		items = []T{}
+	}
+
+	page := Page[T]{
+		Items: items,
+		Pagination: Pagination{
+			Total:   total,
+			Page:    opts.Page,
+			PerPage: opts.PerPage,
+		},
+	}
+
+	if hasNext && opts.UseCursor {
+		page.Pagination.NextCursor = EncodeCursor(idOf(items[len(items)-1]))
+	}
+
+	return page
+}
+
+// Response wraps the page in the response envelope
+func (p Page[T]) Response() Response {
+	pagination := p.Pagination
+
+	return Response{
+		Status:     http.StatusOK,
+		Message:    "OK",
+		Data:       p.Items,
+		Pagination: &pagination,
+	}
+}
@@ -0,0 +1,241 @@
+package models
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseListOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    ListOptions
+		filters []Filter
+		wantErr bool
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			want:  ListOptions{Page: 1, PerPage: DefaultPerPage},
+		},
+		{
+			name:  "page and per_page",
+			query: "page=3&per_page=50",
+			want:  ListOptions{Page: 3, PerPage: 50},
+		},
+		{name: "per_page over the maximum", query: "per_page=101", wantErr: true},
+		{name: "per_page zero", query: "per_page=0", wantErr: true},
+		{name: "page not a number", query: "page=two", wantErr: true},
+		{
+			name:  "empty cursor starts cursor paging",
+			query: "cursor=",
+			want:  ListOptions{PerPage: DefaultPerPage, UseCursor: true},
+		},
+		{
+			name:  "cursor",
+			query: "cursor=" + EncodeCursor(42),
+			want:  ListOptions{PerPage: DefaultPerPage, UseCursor: true, Cursor: 42},
+		},
+		{name: "malformed cursor", query: "cursor=!!", wantErr: true},
+		{
+			name:  "sort",
+			query: "sort=-total,booking_date",
+			want: ListOptions{Page: 1, PerPage: DefaultPerPage, Sort: []SortField{
+				{Column: "total", Desc: true},
+				{Column: "booking_date"},
+			}},
+		},
+		{name: "unknown sort field", query: "sort=customer_name", wantErr: true},
+		{
+			name:    "filters",
+			query:   "status=confirmed&destination_id=7&from=2026-12-01",
+			want:    ListOptions{Page: 1, PerPage: DefaultPerPage},
+			filters: []Filter{{Column: "booking.status", Op: FilterEq, Value: "confirmed"}, {Column: "booking.destination_id", Op: FilterEq, Value: 7}, {Column: "booking.booking_date", Op: FilterGte, Value: "2026-12-01"}},
+		},
+		{name: "invalid status", query: "status=paid", wantErr: true},
+		{name: "int filter not a number", query: "customer_id=me", wantErr: true},
+		{name: "date filter not a date", query: "to=24-12-2026", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := ParseListOptions(query, BookingListSpec)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidListQuery) {
+					t.Errorf("ParseListOptions() error = %v, want %v", err, ErrInvalidListQuery)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got.Page != tt.want.Page || got.PerPage != tt.want.PerPage || got.UseCursor != tt.want.UseCursor || got.Cursor != tt.want.Cursor {
+				t.Errorf("ParseListOptions() = %+v, want %+v", got, tt.want)
+			}
+
+			if !reflect.DeepEqual(got.Sort, tt.want.Sort) {
+				t.Errorf("Sort = %+v, want %+v", got.Sort, tt.want.Sort)
+			}
+
+			if len(got.Filters()) != len(tt.filters) {
+				t.Fatalf("Filters() = %+v, want %+v", got.Filters(), tt.filters)
+			}
+
+			for _, want := range tt.filters {
+				found := false
+
+				for _, filter := range got.Filters() {
+					found = found || filter == want
+				}
+
+				if !found {
+					t.Errorf("Filters() = %+v, missing %+v", got.Filters(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	for _, id := range []int{1, 42, 1 << 30} {
+		got, err := DecodeCursor(EncodeCursor(id))
+		if err != nil || got != id {
+			t.Errorf("DecodeCursor(EncodeCursor(%d)) = %d, %v", id, got, err)
+		}
+	}
+
+	for _, cursor := range []string{"", "not base64!", EncodeCursor(0), "YWJj"} {
+		if _, err := DecodeCursor(cursor); !errors.Is(err, ErrInvalidListQuery) {
+			t.Errorf("DecodeCursor(%q) error = %v, want %v", cursor, err, ErrInvalidListQuery)
+		}
+	}
+}
+
+func TestListQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "offset paging",
+			query:    "page=2&per_page=10",
+			wantSQL:  "SELECT * FROM booking ORDER BY booking.id LIMIT ? OFFSET ?",
+			wantArgs: []interface{}{11, 10},
+		},
+		{
+			name:     "first cursor page",
+			query:    "cursor=&per_page=10",
+			wantSQL:  "SELECT * FROM booking ORDER BY booking.id LIMIT ?",
+			wantArgs: []interface{}{11},
+		},
+		{
+			name:     "keyset after the cursor row",
+			query:    "cursor=" + EncodeCursor(42) + "&sort=-total&per_page=10",
+			wantSQL:  "SELECT * FROM booking WHERE ((booking.total < (SELECT total FROM booking WHERE id = ?)) OR (booking.total = (SELECT total FROM booking WHERE id = ?) AND booking.id > (SELECT id FROM booking WHERE id = ?))) ORDER BY booking.total DESC, booking.id LIMIT ?",
+			wantArgs: []interface{}{42, 42, 42, 11},
+		},
+		{
+			name:     "filters before the keyset",
+			query:    "cursor=" + EncodeCursor(42) + "&status=pending&per_page=10",
+			wantSQL:  "SELECT * FROM booking WHERE booking.status = ? AND ((booking.id > (SELECT id FROM booking WHERE id = ?))) ORDER BY booking.id LIMIT ?",
+			wantArgs: []interface{}{"pending", 42, 11},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+
+			opts, err := ParseListOptions(query, BookingListSpec)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sqlStatement, args := opts.Query("SELECT * FROM booking")
+
+			if sqlStatement != tt.wantSQL {
+				t.Errorf("Query() =\n%s\nwant\n%s", sqlStatement, tt.wantSQL)
+			}
+
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestCountQueryIgnoresCursor(t *testing.T) {
+	query, _ := url.ParseQuery("cursor=" + EncodeCursor(42) + "&status=pending")
+
+	opts, err := ParseListOptions(query, BookingListSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sqlStatement, args := opts.CountQuery("FROM booking")
+
+	if sqlStatement != "SELECT COUNT(*) FROM booking WHERE booking.status = ?" || !reflect.DeepEqual(args, []interface{}{"pending"}) {
+		t.Errorf("CountQuery() = %q, %v", sqlStatement, args)
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	ids := func(n int) []int {
+		items := make([]int, n)
+		for i := range items {
+			items[i] = i + 1
+		}
+		return items
+	}
+	idOf := func(id int) int { return id }
+
+	tests := []struct {
+		name           string
+		opts           ListOptions
+		fetched        int
+		wantItems      int
+		wantNextCursor string
+	}{
+		{name: "last offset page", opts: ListOptions{Page: 2, PerPage: 3}, fetched: 2, wantItems: 2},
+		{name: "offset page with more", opts: ListOptions{Page: 1, PerPage: 3}, fetched: 4, wantItems: 3},
+		{name: "cursor page with more", opts: ListOptions{PerPage: 3, UseCursor: true}, fetched: 4, wantItems: 3, wantNextCursor: EncodeCursor(3)},
+		{name: "last cursor page", opts: ListOptions{PerPage: 3, UseCursor: true}, fetched: 3, wantItems: 3},
+		{name: "empty", opts: ListOptions{Page: 1, PerPage: 3}, fetched: 0, wantItems: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var items []int
+			if tt.fetched > 0 {
+				items = ids(tt.fetched)
+			}
+
+			page := NewPage(items, 10, tt.opts, idOf)
+
+			if page.Items == nil || len(page.Items) != tt.wantItems {
+				t.Errorf("Items = %v, want %d items", page.Items, tt.wantItems)
+			}
+
+			if page.Pagination.NextCursor != tt.wantNextCursor {
+				t.Errorf("NextCursor = %q, want %q", page.Pagination.NextCursor, tt.wantNextCursor)
+			}
+
+			if page.Pagination.Total != 10 {
+				t.Errorf("Total = %d, want 10", page.Pagination.Total)
+			}
+		})
+	}
+}
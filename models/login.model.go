@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/config"
+)
+
+// Reasons a login failed, recorded in the audit trail
+const (
+	LoginUnknownEmail  = "unknown_email"
+	LoginWrongPassword = "wrong_password"
+	LoginLocked        = "locked"
+)
+
+// LoginFailure is one failed login attempt. UserID is zero when no account
+// uses the email.
+type LoginFailure struct {
+	Email     string
+	UserID    int
+	IP        string
+	UserAgent string
+	Reason    string
+}
+
+// LockoutPolicy decides how long an email is locked after failed logins
+type LockoutPolicy struct {
+	Threshold int
+	Base      time.Duration
+	Max       time.Duration
+	// Window is how long failures are remembered since the last one
+	Window time.Duration
+}
+
+// DefaultLockoutPolicy reads the lockout rules from the configuration
+func DefaultLockoutPolicy() LockoutPolicy {
+	conf := config.GetConfig().Auth
+
+	return LockoutPolicy{
+		Threshold: conf.LockoutThreshold,
+		Base:      conf.LockoutBase.Duration,
+		Max:       conf.LockoutMax.Duration,
+		Window:    conf.LockoutWindow.Duration,
+	}
+}
+
+// LockFor returns how long an email is locked after failures failed logins
+// in a row. The lock doubles with every failure past the threshold.
+func (p LockoutPolicy) LockFor(failures int) time.Duration {
+	if failures < p.Threshold {
+		return 0
+	}
+
+	lock := p.Base
+
+	for i := p.Threshold; i < failures && lock < p.Max; i++ {
+		lock *= 2
+	}
+
+	if lock > p.Max {
+		lock = p.Max
+	}
+
+	return lock
+}
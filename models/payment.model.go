@@ -0,0 +1,29 @@
+package models
+
+type Payment struct {
+	Id          int     `json:"id"`
+	BookingID   int     `json:"booking_id"`
+	Provider    string  `json:"provider"`
+	OrderID     string  `json:"order_id"`
+	Reference   string  `json:"reference"`
+	Method      string  `json:"method"`
+	Amount      int64   `json:"amount"`
+	Status      string  `json:"status"`
+	Bank        string  `json:"bank,omitempty"`
+	VANumber    string  `json:"va_number,omitempty"`
+	QRString    string  `json:"qr_string,omitempty"`
+	RedirectURL string  `json:"redirect_url,omitempty"`
+	ExpiresAt   *string `json:"expires_at"`
+	PaidAt      *string `json:"paid_at"`
+	CreatedAt   string  `json:"created_at"`
+}
+
+type PaymentRequest struct {
+	Method  string `json:"method" example:"virtual_account" validate:"required,oneof=virtual_account qris ewallet"`
+	Bank    string `json:"bank" example:"bca" validate:"max=32"`
+	Channel string `json:"channel" example:"gopay" validate:"max=32"`
+}
+
+type RefundRequest struct {
+	Reason string `json:"reason" validate:"max=255"`
+}
@@ -0,0 +1,45 @@
+package models
+
+// Currency of every amount stored on a booking. Amounts are integer minor
+// units (sen), so Rp 25.000 is stored as 2500000.
+const Currency = "IDR"
+
+// minorUnitsPerRupiah converts destination prices, which are kept in whole
+// rupiah, to minor units
+const minorUnitsPerRupiah = 100
+
+// PriceBreakdown is the price of a booking at the moment it was made
+type PriceBreakdown struct {
+	UnitPrice int64 `json:"unit_price"`
+	Qty       int   `json:"qty"`
+	Subtotal  int64 `json:"subtotal"`
+	Discount  int64 `json:"discount"`
+	Tax       int64 `json:"tax"`
+	Total     int64 `json:"total"`
+}
+
+// CalculatePrice prices qty tickets of a destination costing price whole
+// rupiah each. The discount is taken off the subtotal before tax, and tax is
+// rounded half up to whole rupiah because payment gateways only charge
+// whole rupiah, so a booking without a fractional discount totals whole
+// rupiah too.
+func CalculatePrice(price int, qty int, discount int64, taxRateBps int64) PriceBreakdown {
+	unitPrice := int64(price) * minorUnitsPerRupiah
+	subtotal := unitPrice * int64(qty)
+
+	if discount > subtotal {
+		discount = subtotal
+	}
+
+	taxable := subtotal - discount
+	tax := (taxable*taxRateBps + 5000*minorUnitsPerRupiah) / (10000 * minorUnitsPerRupiah) * minorUnitsPerRupiah
+
+	return PriceBreakdown{
+		UnitPrice: unitPrice,
+		Qty:       qty,
+		Subtotal:  subtotal,
+		Discount:  discount,
+		Tax:       tax,
+		Total:     taxable + tax,
+	}
+}
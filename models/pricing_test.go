@@ -0,0 +1,59 @@
+package models
+
+import "testing"
+
+func TestCalculatePrice(t *testing.T) {
+	tests := []struct {
+		name       string
+		price      int
+		qty        int
+		discount   int64
+		taxRateBps int64
+		want       PriceBreakdown
+	}{
+		{
+			name:  "no tax",
+			price: 25000, qty: 2,
+			want: PriceBreakdown{UnitPrice: 2500000, Qty: 2, Subtotal: 5000000, Total: 5000000},
+		},
+		{
+			name:  "11% tax",
+			price: 25000, qty: 2, taxRateBps: 1100,
+			want: PriceBreakdown{UnitPrice: 2500000, Qty: 2, Subtotal: 5000000, Tax: 550000, Total: 5550000},
+		},
+		{
+			name:  "tax rounds half up to whole rupiah",
+			price: 15, qty: 1, taxRateBps: 1000,
+			want: PriceBreakdown{UnitPrice: 1500, Qty: 1, Subtotal: 1500, Tax: 200, Total: 1700},
+		},
+		{
+			name:  "tax rounds down below half a rupiah",
+			price: 14, qty: 1, taxRateBps: 1000,
+			want: PriceBreakdown{UnitPrice: 1400, Qty: 1, Subtotal: 1400, Tax: 100, Total: 1500},
+		},
+		{
+			name:  "discount before tax",
+			price: 10000, qty: 3, discount: 1000000, taxRateBps: 1100,
+			want: PriceBreakdown{UnitPrice: 1000000, Qty: 3, Subtotal: 3000000, Discount: 1000000, Tax: 220000, Total: 2220000},
+		},
+		{
+			name:  "discount capped at the subtotal",
+			price: 10000, qty: 1, discount: 5000000, taxRateBps: 1100,
+			want: PriceBreakdown{UnitPrice: 1000000, Qty: 1, Subtotal: 1000000, Discount: 1000000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CalculatePrice(tt.price, tt.qty, tt.discount, tt.taxRateBps)
+
+			if got != tt.want {
+				t.Errorf("CalculatePrice() = %+v, want %+v", got, tt.want)
+			}
+
+			if got.Total%minorUnitsPerRupiah != 0 {
+				t.Errorf("Total %d is not whole rupiah", got.Total)
+			}
+		})
+	}
+}
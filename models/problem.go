@@ -0,0 +1,17 @@
+package models
+
+// Problem is the body of every error response, an RFC 7807 problem details
+// object. Code is stable and meant for clients to switch on, Detail is for
+// people and may change.
+type Problem struct {
+	Type      string `json:"type" example:"about:blank"`
+	Title     string `json:"title" example:"Not Found"`
+	Status    int    `json:"status" example:"404"`
+	Detail    string `json:"detail,omitempty" example:"Destination not found"`
+	Instance  string `json:"instance,omitempty" example:"/destination/42"`
+	Code      string `json:"code" example:"destination_not_found"`
+	RequestID string `json:"request_id,omitempty"`
+
+	// Errors maps request fields to what is wrong with them
+	Errors map[string]string `json:"errors,omitempty"`
+}
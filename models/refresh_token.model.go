@@ -0,0 +1,10 @@
+package models
+
+type RefreshToken struct {
+	Id     int `json:"id"`
+	UserID int `json:"user_id"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
@@ -0,0 +1,5 @@
+package models
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
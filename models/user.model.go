@@ -1,20 +1,13 @@
 package models
 
-import (
-	"database/sql"
-	"net/http"
-	"strconv"
-
-	"github.com/bryansamperura/ticket-booking/db"
-	"github.com/go-playground/validator/v10"
-)
-
 type User struct {
 	Id        int    `json:"id"`
 	Email     string `json:"email"`
 	Password  string `json:"password"`
 	Role      string `json:"role"`
 	AccountID string `json:"account_id"`
+	// EmailVerifiedAt is nil until the owner follows the verification link
+	EmailVerifiedAt *string `json:"email_verified_at"`
 }
 
 type UserRequest struct {
@@ -24,77 +17,46 @@ type UserRequest struct {
 	AccountID string `json:"account_id"`
 }
 
-func StoreAccount(email string, password string, role string, account_id int) (Response, error) {
-	var res Response
-
-	v := validator.New()
-
-	accounts := User{
-		Email:     email,
-		Password:  password,
-		Role:      role,
-		AccountID: strconv.Itoa(account_id),
-	}
-
-	err := v.Struct(accounts)
-
-	if err != nil {
-		return res, err
-	}
-
-	con := db.CreateConnection()
-
-	sqlStatement := "INSERT INTO users (email, password, role, account_id) VALUES (?, ?, ?, ?)"
-
-	stmt, err := con.Prepare(sqlStatement)
-
-	if err != nil {
-		return res, err
-	}
-
-	result, err := stmt.Exec(email, password, role, account_id)
-
-	if err != nil {
-		return res, err
-	}
-
-	lastInsertedId, err := result.LastInsertId()
-	if err != nil {
-		return res, err
-	}
-
-	res.Status = http.StatusCreated
-	res.Message = "Inserted"
-	res.Data = map[string]int64{
-		"id": lastInsertedId,
-	}
-
-	return res, nil
+// Purposes of the single-use tokens mailed to account owners
+const (
+	TokenVerifyEmail   = "verify_email"
+	TokenResetPassword = "reset_password"
+)
 
+type EmailRequest struct {
+	Email string `json:"email" validate:"required,email,max=255"`
 }
 
-func FindUserByEmail(email string) (Response, error) {
-	var user User
-	var res Response
-
-	con := db.CreateConnection()
-
-	sqlStatement := "SELECT * FROM users WHERE email = ?"
-
-	row := con.QueryRow(sqlStatement, email)
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required,max=128"`
+	Password string `json:"password" validate:"required,min=8,max=72,not_breached"`
+}
 
-	err := row.Scan(&user.Id, &user.Email, &user.Password, &user.Role, &user.AccountID)
+// Profile is the account of the logged in user, its login and the customer
+// or admin record behind it
+type Profile struct {
+	Id              int     `json:"id"`
+	AccountID       int     `json:"account_id"`
+	Role            string  `json:"role"`
+	FullName        string  `json:"fullname"`
+	Email           string  `json:"email"`
+	Phone           string  `json:"phone"`
+	EmailVerifiedAt *string `json:"email_verified_at"`
+}
 
-	if err == sql.ErrNoRows {
-		// Return a custom error response if the record is not found
-		return Response{Status: http.StatusNotFound, Message: "Not Found"}, nil
-	} else if err != nil {
-		return Response{}, err
-	}
+type ProfileRequest struct {
+	FullName string `json:"fullname" validate:"required,max=255"`
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Phone    string `json:"phone" example:"+6281234567890" validate:"required,phone_id"`
+	// CurrentPassword is only needed to change the email
+	CurrentPassword string `json:"current_password" validate:"max=72"`
+}
 
-	res.Status = http.StatusOK
-	res.Message = "OK"
-	res.Data = user
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required,max=72"`
+	NewPassword     string `json:"new_password" validate:"required,min=8,max=72,not_breached"`
+}
 
-	return res, nil
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required,max=72"`
 }
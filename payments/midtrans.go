@@ -0,0 +1,309 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MidtransProvider talks to a gateway following the Midtrans Core API:
+// bank transfer virtual accounts, QRIS and e-wallets (GoPay, ShopeePay),
+// HTTP basic auth with the server key and SHA-512 signed notifications.
+type MidtransProvider struct {
+	baseURL   string
+	serverKey string
+	client    *http.Client
+}
+
+type midtransCharge struct {
+	StatusCode        string `json:"status_code"`
+	StatusMessage     string `json:"status_message"`
+	TransactionID     string `json:"transaction_id"`
+	OrderID           string `json:"order_id"`
+	GrossAmount       string `json:"gross_amount"`
+	PaymentType       string `json:"payment_type"`
+	TransactionStatus string `json:"transaction_status"`
+	FraudStatus       string `json:"fraud_status"`
+	VANumbers         []struct {
+		Bank     string `json:"bank"`
+		VANumber string `json:"va_number"`
+	} `json:"va_numbers"`
+	PermataVANumber string `json:"permata_va_number"`
+	Actions         []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"actions"`
+	QRString     string `json:"qr_string"`
+	ExpiryTime   string `json:"expiry_time"`
+	SignatureKey string `json:"signature_key"`
+}
+
+// midtransTimeLayout is the format of expiry_time, in Western Indonesian Time
+const midtransTimeLayout = "2006-01-02 15:04:05"
+
+// midtransOrderTimeLayout is the format of custom_expiry.order_time
+const midtransOrderTimeLayout = "2006-01-02 15:04:05 -0700"
+
+var midtransLocation = time.FixedZone("WIB", 7*60*60)
+
+func NewMidtransProvider(baseURL string, serverKey string) *MidtransProvider {
+	return &MidtransProvider{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		serverKey: serverKey,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (p *MidtransProvider) Name() string {
+	return "midtrans"
+}
+
+func (p *MidtransProvider) CreateCharge(ctx context.Context, req ChargeRequest) (Charge, error) {
+	grossAmount, err := rupiah(req.Amount)
+	if err != nil {
+		return Charge{}, err
+	}
+
+	payload := map[string]interface{}{
+		"transaction_details": map[string]interface{}{
+			"order_id":     req.OrderID,
+			"gross_amount": grossAmount,
+		},
+		"customer_details": map[string]string{
+			"first_name": req.CustomerName,
+			"email":      req.CustomerEmail,
+			"phone":      req.CustomerPhone,
+		},
+	}
+
+	if !req.ExpiresAt.IsZero() {
+		now := time.Now()
+
+		seconds := int64(req.ExpiresAt.Sub(now) / time.Second)
+		if seconds < 1 {
+			seconds = 1
+		}
+
+		payload["custom_expiry"] = map[string]interface{}{
+			"order_time":      now.In(midtransLocation).Format(midtransOrderTimeLayout),
+			"expiry_duration": seconds,
+			"unit":            "second",
+		}
+	}
+
+	switch req.Method {
+	case MethodVirtualAccount:
+		payload["payment_type"] = "bank_transfer"
+		payload["bank_transfer"] = map[string]string{"bank": req.Bank}
+	case MethodQRIS:
+		payload["payment_type"] = "qris"
+	case MethodEWallet:
+		channel := req.Channel
+		if channel == "" {
+			channel = "gopay"
+		}
+		if channel != "gopay" && channel != "shopeepay" {
+			return Charge{}, ErrUnsupportedMethod
+		}
+		payload["payment_type"] = channel
+	default:
+		return Charge{}, ErrUnsupportedMethod
+	}
+
+	var res midtransCharge
+	if err := p.do(ctx, http.MethodPost, "/v2/charge", payload, &res); err != nil {
+		return Charge{}, err
+	}
+
+	charge := p.toCharge(res)
+	charge.Method = req.Method
+	charge.Amount = req.Amount
+
+	return charge, nil
+}
+
+func (p *MidtransProvider) QueryStatus(ctx context.Context, orderID string) (Charge, error) {
+	var res midtransCharge
+	if err := p.do(ctx, http.MethodGet, "/v2/"+orderID+"/status", nil, &res); err != nil {
+		return Charge{}, err
+	}
+
+	if res.StatusCode == "404" {
+		return Charge{}, ErrChargeNotFound
+	}
+
+	return p.toCharge(res), nil
+}
+
+func (p *MidtransProvider) HandleWebhook(r *http.Request) (Notification, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return Notification{}, err
+	}
+
+	var res midtransCharge
+	if err := json.Unmarshal(body, &res); err != nil {
+		return Notification{}, err
+	}
+
+	sum := sha512.Sum512([]byte(res.OrderID + res.StatusCode + res.GrossAmount + p.serverKey))
+	expected := hex.EncodeToString(sum[:])
+
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(res.SignatureKey)) != 1 {
+		return Notification{}, ErrInvalidSignature
+	}
+
+	charge := p.toCharge(res)
+
+	return Notification{
+		OrderID:   charge.OrderID,
+		Reference: charge.Reference,
+		Status:    charge.Status,
+		Amount:    charge.Amount,
+	}, nil
+}
+
+func (p *MidtransProvider) Refund(ctx context.Context, orderID string, amount int64, reason string) error {
+	refundAmount, err := rupiah(amount)
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"refund_key": orderID + "-refund",
+		"amount":     refundAmount,
+		"reason":     reason,
+	}
+
+	var res midtransCharge
+	if err := p.do(ctx, http.MethodPost, "/v2/"+orderID+"/refund", payload, &res); err != nil {
+		return err
+	}
+
+	if res.StatusCode != "200" {
+		return fmt.Errorf("refund rejected: %s %s", res.StatusCode, res.StatusMessage)
+	}
+
+	return nil
+}
+
+func (p *MidtransProvider) do(ctx context.Context, method string, path string, payload interface{}, out *midtransCharge) error {
+	var body io.Reader
+
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+
+	req.SetBasicAuth(p.serverKey, "")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("payment gateway returned %s", resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return err
+	}
+
+	// The API reports errors in status_code with an HTTP 200
+	if code, _ := strconv.Atoi(out.StatusCode); code >= 400 && code != 404 {
+		return fmt.Errorf("payment gateway error %s: %s", out.StatusCode, out.StatusMessage)
+	}
+
+	return nil
+}
+
+func (p *MidtransProvider) toCharge(res midtransCharge) Charge {
+	charge := Charge{
+		OrderID:   res.OrderID,
+		Reference: res.TransactionID,
+		Status:    midtransStatus(res.TransactionStatus, res.FraudStatus),
+		QRString:  res.QRString,
+	}
+
+	if amount, err := strconv.ParseFloat(res.GrossAmount, 64); err == nil {
+		charge.Amount = int64(math.Round(amount * 100))
+	}
+
+	if len(res.VANumbers) > 0 {
+		charge.Bank = res.VANumbers[0].Bank
+		charge.VANumber = res.VANumbers[0].VANumber
+	} else if res.PermataVANumber != "" {
+		charge.Bank = "permata"
+		charge.VANumber = res.PermataVANumber
+	}
+
+	for _, action := range res.Actions {
+		switch action.Name {
+		case "deeplink-redirect":
+			charge.RedirectURL = action.URL
+		case "generate-qr-code":
+			if charge.RedirectURL == "" {
+				charge.RedirectURL = action.URL
+			}
+		}
+	}
+
+	if expiry, err := time.ParseInLocation(midtransTimeLayout, res.ExpiryTime, midtransLocation); err == nil {
+		charge.ExpiresAt = expiry
+	}
+
+	return charge
+}
+
+// rupiah converts an amount in minor units to the whole rupiah the API
+// expects. Dropping the sen would make the gateway collect less than the
+// booking total, so fractional amounts are refused instead.
+func rupiah(amount int64) (int64, error) {
+	if amount%100 != 0 {
+		return 0, ErrFractionalAmount
+	}
+
+	return amount / 100, nil
+}
+
+func midtransStatus(transactionStatus string, fraudStatus string) string {
+	switch transactionStatus {
+	case "settlement":
+		return StatusPaid
+	case "capture":
+		if fraudStatus == "" || fraudStatus == "accept" {
+			return StatusPaid
+		}
+		return StatusPending
+	case "deny", "cancel", "failure":
+		return StatusFailed
+	case "expire":
+		return StatusExpired
+	case "refund", "partial_refund":
+		return StatusRefunded
+	default:
+		return StatusPending
+	}
+}
@@ -0,0 +1,143 @@
+package payments
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func midtransSignature(orderID string, statusCode string, grossAmount string, serverKey string) string {
+	sum := sha512.Sum512([]byte(orderID + statusCode + grossAmount + serverKey))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func TestMidtransWebhookSignature(t *testing.T) {
+	provider := NewMidtransProvider("http://midtrans.test", "server-key")
+
+	tests := []struct {
+		name       string
+		amount     string
+		signature  string
+		wantErr    error
+		wantAmount int64
+	}{
+		{
+			name:       "signed with the server key",
+			amount:     "111000.00",
+			signature:  midtransSignature("BK1-1", "200", "111000.00", "server-key"),
+			wantAmount: 11100000,
+		},
+		{
+			name:      "signed with another key",
+			amount:    "111000.00",
+			signature: midtransSignature("BK1-1", "200", "111000.00", "other-key"),
+			wantErr:   ErrInvalidSignature,
+		},
+		{
+			name:      "amount changed after signing",
+			amount:    "100.00",
+			signature: midtransSignature("BK1-1", "200", "111000.00", "server-key"),
+			wantErr:   ErrInvalidSignature,
+		},
+		{
+			name:    "unsigned",
+			amount:  "111000.00",
+			wantErr: ErrInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(map[string]string{
+				"order_id":           "BK1-1",
+				"status_code":        "200",
+				"gross_amount":       tt.amount,
+				"transaction_id":     "trx-1",
+				"transaction_status": "settlement",
+				"signature_key":      tt.signature,
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/payments/webhook/midtrans", strings.NewReader(string(body)))
+
+			notification, err := provider.HandleWebhook(req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("HandleWebhook() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if notification.Status != StatusPaid || notification.Amount != tt.wantAmount {
+				t.Errorf("HandleWebhook() = %+v, want paid %d", notification, tt.wantAmount)
+			}
+		})
+	}
+}
+
+func TestMidtransChargeAmountAndExpiry(t *testing.T) {
+	var payload struct {
+		TransactionDetails struct {
+			GrossAmount int64 `json:"gross_amount"`
+		} `json:"transaction_details"`
+		CustomExpiry struct {
+			ExpiryDuration int64  `json:"expiry_duration"`
+			Unit           string `json:"unit"`
+		} `json:"custom_expiry"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Error(err)
+		}
+
+		w.Write([]byte(`{"status_code":"201","order_id":"BK1-1","gross_amount":"111000.00","transaction_status":"pending"}`))
+	}))
+	defer server.Close()
+
+	provider := NewMidtransProvider(server.URL, "server-key")
+
+	charge, err := provider.CreateCharge(context.Background(), ChargeRequest{
+		OrderID:   "BK1-1",
+		Amount:    11100000,
+		Method:    MethodQRIS,
+		ExpiresAt: time.Now().Add(15 * time.Minute),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if payload.TransactionDetails.GrossAmount != 111000 {
+		t.Errorf("gross_amount = %d, want 111000", payload.TransactionDetails.GrossAmount)
+	}
+
+	if payload.CustomExpiry.Unit != "second" || payload.CustomExpiry.ExpiryDuration > 15*60 || payload.CustomExpiry.ExpiryDuration < 15*60-5 {
+		t.Errorf("custom_expiry = %+v, want about 900 seconds", payload.CustomExpiry)
+	}
+
+	if charge.Amount != 11100000 {
+		t.Errorf("Amount = %d, want 11100000", charge.Amount)
+	}
+}
+
+func TestMidtransRefusesFractionalRupiah(t *testing.T) {
+	provider := NewMidtransProvider("http://midtrans.test", "server-key")
+
+	_, err := provider.CreateCharge(context.Background(), ChargeRequest{OrderID: "BK1-1", Amount: 11100050, Method: MethodQRIS})
+	if !errors.Is(err, ErrFractionalAmount) {
+		t.Errorf("CreateCharge() error = %v, want %v", err, ErrFractionalAmount)
+	}
+
+	err = provider.Refund(context.Background(), "BK1-1", 11100050, "")
+	if !errors.Is(err, ErrFractionalAmount) {
+		t.Errorf("Refund() error = %v, want %v", err, ErrFractionalAmount)
+	}
+}
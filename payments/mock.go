@@ -0,0 +1,166 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// MockSignatureHeader carries the HMAC-SHA256 of the webhook body
+const MockSignatureHeader = "X-Mock-Signature"
+
+// MockProvider is an offline provider for development and tests. Charges
+// live in memory and are only paid when SimulatePayment is called.
+type MockProvider struct {
+	secret []byte
+
+	mu      sync.Mutex
+	charges map[string]Charge
+}
+
+type mockNotification struct {
+	OrderID   string `json:"order_id"`
+	Reference string `json:"reference"`
+	Status    string `json:"status"`
+	Amount    int64  `json:"amount"`
+}
+
+func NewMockProvider(secret string) *MockProvider {
+	return &MockProvider{
+		secret:  []byte(secret),
+		charges: make(map[string]Charge),
+	}
+}
+
+func (p *MockProvider) Name() string {
+	return "mock"
+}
+
+func (p *MockProvider) CreateCharge(ctx context.Context, req ChargeRequest) (Charge, error) {
+	expiresAt := req.ExpiresAt
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(24 * time.Hour)
+	}
+
+	charge := Charge{
+		OrderID:   req.OrderID,
+		Reference: "MOCK-" + req.OrderID,
+		Method:    req.Method,
+		Status:    StatusPending,
+		Amount:    req.Amount,
+		ExpiresAt: expiresAt,
+	}
+
+	switch req.Method {
+	case MethodVirtualAccount:
+		charge.Bank = req.Bank
+		charge.VANumber = fmt.Sprintf("8808%012d", time.Now().UnixNano()%1e12)
+	case MethodQRIS:
+		charge.QRString = "00020101021226590013ID.MOCK.QRIS" + req.OrderID
+	case MethodEWallet:
+		charge.RedirectURL = "https://mock.payment.local/ewallet/" + req.OrderID
+	default:
+		return Charge{}, ErrUnsupportedMethod
+	}
+
+	p.mu.Lock()
+	p.charges[req.OrderID] = charge
+	p.mu.Unlock()
+
+	return charge, nil
+}
+
+func (p *MockProvider) QueryStatus(ctx context.Context, orderID string) (Charge, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	charge, ok := p.charges[orderID]
+	if !ok {
+		return Charge{}, ErrChargeNotFound
+	}
+
+	return charge, nil
+}
+
+func (p *MockProvider) HandleWebhook(r *http.Request) (Notification, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return Notification{}, err
+	}
+
+	expected := p.sign(body)
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get(MockSignatureHeader))) {
+		return Notification{}, ErrInvalidSignature
+	}
+
+	var payload mockNotification
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return Notification{}, err
+	}
+
+	p.mu.Lock()
+	if charge, ok := p.charges[payload.OrderID]; ok {
+		charge.Status = payload.Status
+		p.charges[payload.OrderID] = charge
+	}
+	p.mu.Unlock()
+
+	return Notification{
+		OrderID:   payload.OrderID,
+		Reference: payload.Reference,
+		Status:    payload.Status,
+		Amount:    payload.Amount,
+	}, nil
+}
+
+func (p *MockProvider) Refund(ctx context.Context, orderID string, amount int64, reason string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	charge, ok := p.charges[orderID]
+	if ok {
+		charge.Status = StatusRefunded
+		p.charges[orderID] = charge
+	}
+
+	return nil
+}
+
+// SimulatePayment builds the signed webhook request the mock gateway would
+// send once the visitor paid amount for the order
+func (p *MockProvider) SimulatePayment(orderID string, amount int64) (*http.Request, error) {
+	body, err := json.Marshal(mockNotification{
+		OrderID:   orderID,
+		Reference: "MOCK-" + orderID,
+		Status:    StatusPaid,
+		Amount:    amount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "/payments/webhook/mock", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(MockSignatureHeader, p.sign(body))
+
+	return req, nil
+}
+
+func (p *MockProvider) sign(body []byte) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,86 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestMockWebhookSignature(t *testing.T) {
+	provider := NewMockProvider("secret")
+
+	tests := []struct {
+		name    string
+		tamper  func(body []byte) []byte
+		signer  *MockProvider
+		wantErr error
+	}{
+		{name: "signed by the provider", signer: provider},
+		{name: "signed with another secret", signer: NewMockProvider("other"), wantErr: ErrInvalidSignature},
+		{
+			name:   "amount changed after signing",
+			signer: provider,
+			tamper: func(body []byte) []byte {
+				return bytes.Replace(body, []byte(`"amount":1500000`), []byte(`"amount":100`), 1)
+			},
+			wantErr: ErrInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := tt.signer.SimulatePayment("BK1-1700000000", 1500000)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.tamper != nil {
+				body, _ := io.ReadAll(req.Body)
+				req.Body = io.NopCloser(bytes.NewReader(tt.tamper(body)))
+			}
+
+			notification, err := provider.HandleWebhook(req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("HandleWebhook() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			want := Notification{OrderID: "BK1-1700000000", Reference: "MOCK-BK1-1700000000", Status: StatusPaid, Amount: 1500000}
+			if notification != want {
+				t.Errorf("HandleWebhook() = %+v, want %+v", notification, want)
+			}
+		})
+	}
+}
+
+func TestMockChargeMarkedPaid(t *testing.T) {
+	ctx := context.Background()
+	provider := NewMockProvider("secret")
+
+	if _, err := provider.CreateCharge(ctx, ChargeRequest{OrderID: "BK2-1", Amount: 100, Method: MethodQRIS}); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := provider.SimulatePayment("BK2-1", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = provider.HandleWebhook(req); err != nil {
+		t.Fatal(err)
+	}
+
+	charge, err := provider.QueryStatus(ctx, "BK2-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if charge.Status != StatusPaid {
+		t.Errorf("Status = %q, want %q", charge.Status, StatusPaid)
+	}
+}
@@ -0,0 +1,51 @@
+package payments
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bryansamperura/ticket-booking/config"
+	"github.com/bryansamperura/ticket-booking/helper"
+)
+
+var provider Provider
+
+// Init sets up the provider selected by payment.provider
+func Init() {
+	conf := config.GetConfig().Payment
+
+	if conf.ServerKey == "" {
+		helper.PanicIfError(errors.New("payment.server_key is required"))
+	}
+
+	switch conf.Provider {
+	case "", "mock":
+		provider = NewMockProvider(conf.ServerKey)
+	case "midtrans":
+		provider = NewMidtransProvider(conf.BaseURL, conf.ServerKey)
+	default:
+		helper.PanicIfError(fmt.Errorf("%w: %s", ErrUnknownProvider, conf.Provider))
+	}
+}
+
+// Default returns the configured provider
+func Default() Provider {
+	return provider
+}
+
+// Get returns the configured provider if its name matches, so webhooks
+// addressed to any other provider are rejected
+func Get(name string) (Provider, error) {
+	if provider == nil || provider.Name() != name {
+		return nil, ErrUnknownProvider
+	}
+
+	return provider, nil
+}
+
+// Mock returns the mock provider when it is the configured one
+func Mock() (*MockProvider, bool) {
+	mock, ok := provider.(*MockProvider)
+
+	return mock, ok
+}
@@ -0,0 +1,101 @@
+// Package payments talks to payment gateways. Every gateway is wrapped in a
+// Provider so bookings can be paid the same way no matter which one is
+// configured.
+package payments
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/apperror"
+)
+
+// Payment methods offered to visitors
+const (
+	MethodVirtualAccount = "virtual_account"
+	MethodQRIS           = "qris"
+	MethodEWallet        = "ewallet"
+)
+
+// Charge statuses, normalised across providers
+const (
+	StatusPending  = "pending"
+	StatusPaid     = "paid"
+	StatusFailed   = "failed"
+	StatusExpired  = "expired"
+	StatusRefunded = "refunded"
+
+	// StatusRefunding marks a paid charge whose refund is being sent to the
+	// gateway. It is never reported by a provider.
+	StatusRefunding = "refunding"
+)
+
+var (
+	ErrInvalidSignature  = apperror.Unauthorized("invalid_signature", "invalid webhook signature")
+	ErrUnsupportedMethod = apperror.Validation("unsupported_payment_method", "unsupported payment method")
+	ErrChargeNotFound    = apperror.NotFound("charge_not_found", "charge not found")
+	ErrUnknownProvider   = apperror.NotFound("unknown_payment_provider", "unknown payment provider")
+	ErrFractionalAmount  = apperror.Validation("fractional_amount", "The payment gateway only charges whole rupiah")
+
+	// ErrGateway wraps failures of the payment gateway itself
+	ErrGateway = apperror.Upstream("payment_gateway_error", "The payment gateway could not process the request")
+)
+
+// ChargeRequest asks a provider to collect Amount for an order.
+// Amount is in IDR minor units and must be whole rupiah. The charge can no
+// longer be paid after ExpiresAt, or after the provider's default expiry
+// when it is zero.
+type ChargeRequest struct {
+	OrderID       string
+	Amount        int64
+	ExpiresAt     time.Time
+	Method        string
+	Bank          string
+	Channel       string
+	CustomerName  string
+	CustomerEmail string
+	CustomerPhone string
+}
+
+// Charge is what the visitor needs to pay an order: a virtual account
+// number, a QRIS payload or an e-wallet redirect, depending on the method
+type Charge struct {
+	OrderID     string
+	Reference   string
+	Method      string
+	Status      string
+	Amount      int64
+	Bank        string
+	VANumber    string
+	QRString    string
+	RedirectURL string
+	ExpiresAt   time.Time
+}
+
+// Notification is a verified status update pushed by a provider
+type Notification struct {
+	OrderID   string
+	Reference string
+	Status    string
+	Amount    int64
+}
+
+// Provider is implemented by every payment gateway
+type Provider interface {
+	// Name identifies the provider in stored payments and webhook URLs
+	Name() string
+
+	// CreateCharge starts collecting a payment
+	CreateCharge(ctx context.Context, req ChargeRequest) (Charge, error)
+
+	// QueryStatus asks the provider for the current state of an order
+	QueryStatus(ctx context.Context, orderID string) (Charge, error)
+
+	// HandleWebhook verifies the signature of a notification request and
+	// parses it. It returns ErrInvalidSignature for forged requests.
+	HandleWebhook(r *http.Request) (Notification, error)
+
+	// Refund gives amount back to the payer of a paid order
+	Refund(ctx context.Context, orderID string, amount int64, reason string) error
+}
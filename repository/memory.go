@@ -0,0 +1,1539 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/models"
+	"github.com/bryansamperura/ticket-booking/payments"
+)
+
+// memoryTimeLayout matches how MySQL datetimes are scanned into strings
+const memoryTimeLayout = time.RFC3339
+
+// memoryStore holds the tables of the in-memory repositories. They share
+// one store so bookings can see destinations and customers, and accounts
+// can be created together with their profile.
+type memoryStore struct {
+	mu sync.Mutex
+
+	lastID       map[string]int
+	cities       map[int]models.City
+	customers    map[int]models.Customer
+	admins       map[int]models.Admin
+	destinations map[int]models.Destination
+	users        map[int]models.User
+	bookings     map[int]models.Booking
+	throttles    map[string]loginThrottle
+	tokens       map[string]accountToken
+	failures     []models.LoginFailure
+	// reserved counts the tickets taken per destination and date, overrides
+	// are the quota overrides per destination and date
+	reserved  map[string]int
+	overrides map[string]quotaOverride
+	payments  map[int]models.Payment
+	// checkIns holds the seats let in per booking and seat
+	checkIns      map[string]bool
+	refreshTokens map[int]memoryRefreshToken
+	revokedTokens map[string]bool
+	generations   map[int]int
+}
+
+// NewMemory returns repositories keeping everything in memory, for tests
+func NewMemory() Repositories {
+	store := &memoryStore{
+		lastID:       make(map[string]int),
+		cities:       make(map[int]models.City),
+		customers:    make(map[int]models.Customer),
+		admins:       make(map[int]models.Admin),
+		destinations: make(map[int]models.Destination),
+		users:        make(map[int]models.User),
+		bookings:     make(map[int]models.Booking),
+		throttles:    make(map[string]loginThrottle),
+		tokens:       make(map[string]accountToken),
+		reserved:     make(map[string]int),
+		overrides:    make(map[string]quotaOverride),
+		payments:     make(map[int]models.Payment),
+		checkIns:     make(map[string]bool),
+
+		refreshTokens: make(map[int]memoryRefreshToken),
+		revokedTokens: make(map[string]bool),
+		generations:   make(map[int]int),
+	}
+
+	return Repositories{
+		Cities:       &MemoryCityRepository{store},
+		Customers:    &MemoryCustomerRepository{store},
+		Admins:       &MemoryAdminRepository{store},
+		Destinations: &MemoryDestinationRepository{store},
+		Users:        &MemoryUserRepository{store},
+		Bookings:     &MemoryBookingRepository{store},
+		Availability: &MemoryAvailabilityRepository{store},
+		Payments:     &MemoryPaymentRepository{store},
+		CheckIns:     &MemoryCheckInRepository{store},
+		Tokens:       &MemoryTokenRepository{store},
+		Logins:       &MemoryLoginRepository{store},
+		Health:       &MemoryHealthRepository{},
+	}
+}
+
+func (s *memoryStore) nextID(table string) int {
+	s.lastID[table]++
+
+	return s.lastID[table]
+}
+
+// values returns the rows of a table ordered by id
+func values[T any](table map[int]T) []T {
+	ids := make([]int, 0, len(table))
+	for id := range table {
+		ids = append(ids, id)
+	}
+
+	sort.Ints(ids)
+
+	items := make([]T, 0, len(ids))
+	for _, id := range ids {
+		items = append(items, table[id])
+	}
+
+	return items
+}
+
+// listItems applies the filters, order and paging of opts the way the
+// MySQL implementation does. field returns the value of a column of an item.
+func listItems[T any](items []T, opts models.ListOptions, field func(T, string) interface{}, idOf func(T) int) models.Page[T] {
+	var matching []T
+
+	for _, item := range items {
+		if matchesFilters(item, opts.Filters(), field) {
+			matching = append(matching, item)
+		}
+	}
+
+	sortFields := opts.SortFields()
+
+	less := func(a T, b T) int {
+		for _, sortField := range sortFields {
+			result := compare(field(a, sortField.Column), field(b, sortField.Column))
+			if sortField.Desc {
+				result = -result
+			}
+			if result != 0 {
+				return result
+			}
+		}
+
+		return 0
+	}
+
+	sort.SliceStable(matching, func(i, j int) bool {
+		return less(matching[i], matching[j]) < 0
+	})
+
+	start := 0
+
+	if opts.UseCursor {
+		if opts.Cursor > 0 {
+			start = len(matching)
+
+			for i, item := range matching {
+				if idOf(item) == opts.Cursor {
+					start = i + 1
+					break
+				}
+			}
+		}
+	} else {
+		start = (opts.Page - 1) * opts.PerPage
+	}
+
+	if start > len(matching) {
+		start = len(matching)
+	}
+
+	end := start + opts.PerPage + 1
+	if end > len(matching) {
+		end = len(matching)
+	}
+
+	return models.NewPage(matching[start:end], len(matching), opts, idOf)
+}
+
+func matchesFilters[T any](item T, filters []models.Filter, field func(T, string) interface{}) bool {
+	for _, filter := range filters {
+		column := filter.Column[strings.LastIndex(filter.Column, ".")+1:]
+		value := field(item, column)
+
+		switch filter.Op {
+		case models.FilterEq:
+			if compare(value, filter.Value) != 0 {
+				return false
+			}
+		case models.FilterLike:
+			if !strings.Contains(strings.ToLower(fmt.Sprint(value)), strings.ToLower(fmt.Sprint(filter.Value))) {
+				return false
+			}
+		case models.FilterGte:
+			if compare(value, filter.Value) < 0 {
+				return false
+			}
+		case models.FilterLte:
+			if compare(value, filter.Value) > 0 {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// compare orders two column values, numbers numerically and everything else
+// as text
+func compare(a interface{}, b interface{}) int {
+	x, xNumber := toInt64(a)
+	y, yNumber := toInt64(b)
+
+	if xNumber && yNumber {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func toInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	}
+
+	return 0, false
+}
+
+// emailTaken compares emails ignoring case like the MySQL collation does
+func (s *memoryStore) emailTaken(email string) bool {
+	for _, user := range s.users {
+		if strings.EqualFold(user.Email, email) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// accountTableOf is the table holding the record of a login account
+func accountTableOf(user models.User) string {
+	if user.Role == "customer" {
+		return "customers"
+	}
+
+	return "admin"
+}
+
+// updateLoginEmail changes the email of the login account of a customer or
+// admin record the way updateAccount does
+func (s *memoryStore) updateLoginEmail(table string, accountID int, email string) error {
+	for _, user := range s.users {
+		if strings.EqualFold(user.Email, email) && (accountTableOf(user) != table || user.AccountID != strconv.Itoa(accountID)) {
+			return ErrDuplicate
+		}
+	}
+
+	for id, user := range s.users {
+		if accountTableOf(user) == table && user.AccountID == strconv.Itoa(accountID) && user.Email != email {
+			user.Email = email
+			user.EmailVerifiedAt = nil
+			s.users[id] = user
+		}
+	}
+
+	return nil
+}
+
+type MemoryCityRepository struct {
+	store *memoryStore
+}
+
+func cityField(city models.City, column string) interface{} {
+	switch column {
+	case "id":
+		return city.Id
+	case "city_name":
+		return city.CityName
+	}
+
+	return nil
+}
+
+func (r *MemoryCityRepository) List(ctx context.Context, opts models.ListOptions) (models.Page[models.City], error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return listItems(values(r.store.cities), opts, cityField, func(city models.City) int { return city.Id }), nil
+}
+
+func (r *MemoryCityRepository) FindByID(ctx context.Context, id int) (models.City, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	city, ok := r.store.cities[id]
+	if !ok {
+		return city, ErrNotFound
+	}
+
+	return city, nil
+}
+
+func (r *MemoryCityRepository) Create(ctx context.Context, name string) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	id := r.store.nextID("cities")
+	r.store.cities[id] = models.City{Id: id, CityName: name}
+
+	return id, nil
+}
+
+func (r *MemoryCityRepository) Update(ctx context.Context, id int, name string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.cities[id]; !ok {
+		return ErrNotFound
+	}
+
+	r.store.cities[id] = models.City{Id: id, CityName: name}
+
+	return nil
+}
+
+func (r *MemoryCityRepository) Delete(ctx context.Context, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.cities[id]; !ok {
+		return ErrNotFound
+	}
+
+	delete(r.store.cities, id)
+
+	return nil
+}
+
+type MemoryCustomerRepository struct {
+	store *memoryStore
+}
+
+func customerField(customer models.Customer, column string) interface{} {
+	switch column {
+	case "id":
+		return customer.Id
+	case "fullname":
+		return customer.FullName
+	case "email":
+		return customer.Email
+	case "phone":
+		return customer.Phone
+	}
+
+	return nil
+}
+
+func (r *MemoryCustomerRepository) List(ctx context.Context, opts models.ListOptions) (models.Page[models.Customer], error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return listItems(values(r.store.customers), opts, customerField, func(customer models.Customer) int { return customer.Id }), nil
+}
+
+func (r *MemoryCustomerRepository) FindByID(ctx context.Context, id int) (models.Customer, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	customer, ok := r.store.customers[id]
+	if !ok {
+		return customer, ErrNotFound
+	}
+
+	return customer, nil
+}
+
+func (r *MemoryCustomerRepository) Create(ctx context.Context, customer models.CustomerRequest) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	id := r.store.nextID("customers")
+	r.store.customers[id] = models.Customer{Id: id, FullName: customer.FullName, Email: customer.Email, Phone: customer.Phone}
+
+	return id, nil
+}
+
+func (r *MemoryCustomerRepository) Update(ctx context.Context, id int, customer models.CustomerRequest) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.customers[id]; !ok {
+		return ErrNotFound
+	}
+
+	if err := r.store.updateLoginEmail("customers", id, customer.Email); err != nil {
+		return err
+	}
+
+	r.store.customers[id] = models.Customer{Id: id, FullName: customer.FullName, Email: customer.Email, Phone: customer.Phone}
+
+	return nil
+}
+
+func (r *MemoryCustomerRepository) Delete(ctx context.Context, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.customers[id]; !ok {
+		return ErrNotFound
+	}
+
+	delete(r.store.customers, id)
+	r.store.deleteLoginOf("customers", id)
+
+	return nil
+}
+
+type MemoryAdminRepository struct {
+	store *memoryStore
+}
+
+func adminField(admin models.Admin, column string) interface{} {
+	switch column {
+	case "id":
+		return admin.Id
+	case "fullname":
+		return admin.FullName
+	case "email":
+		return admin.Email
+	case "phone":
+		return admin.Phone
+	}
+
+	return nil
+}
+
+func (r *MemoryAdminRepository) List(ctx context.Context, opts models.ListOptions) (models.Page[models.Admin], error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return listItems(values(r.store.admins), opts, adminField, func(admin models.Admin) int { return admin.Id }), nil
+}
+
+func (r *MemoryAdminRepository) FindByID(ctx context.Context, id int) (models.Admin, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	admin, ok := r.store.admins[id]
+	if !ok {
+		return admin, ErrNotFound
+	}
+
+	return admin, nil
+}
+
+func (r *MemoryAdminRepository) Update(ctx context.Context, id int, admin models.AdminRequest) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.admins[id]; !ok {
+		return ErrNotFound
+	}
+
+	if err := r.store.updateLoginEmail("admin", id, admin.Email); err != nil {
+		return err
+	}
+
+	r.store.admins[id] = models.Admin{Id: id, FullName: admin.FullName, Email: admin.Email, Phone: admin.Phone}
+
+	return nil
+}
+
+func (r *MemoryAdminRepository) Delete(ctx context.Context, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.admins[id]; !ok {
+		return ErrNotFound
+	}
+
+	delete(r.store.admins, id)
+	r.store.deleteLoginOf("admin", id)
+
+	return nil
+}
+
+type MemoryDestinationRepository struct {
+	store *memoryStore
+}
+
+func destinationField(destination models.Destination, column string) interface{} {
+	switch column {
+	case "id":
+		return destination.Id
+	case "destination_name":
+		return destination.DestinationName
+	case "price":
+		return destination.Price
+	case "city_id":
+		cityID, _ := strconv.Atoi(destination.City)
+		return cityID
+	}
+
+	return nil
+}
+
+func (r *MemoryDestinationRepository) List(ctx context.Context, opts models.ListOptions) (models.Page[models.Destination], error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return listItems(values(r.store.destinations), opts, destinationField, func(destination models.Destination) int { return destination.Id }), nil
+}
+
+func (r *MemoryDestinationRepository) FindByID(ctx context.Context, id int) (models.Destination, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	destination, ok := r.store.destinations[id]
+	if !ok {
+		return destination, ErrNotFound
+	}
+
+	return destination, nil
+}
+
+func (r *MemoryDestinationRepository) Create(ctx context.Context, destination models.Destination) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	destination.Id = r.store.nextID("destination")
+	r.store.destinations[destination.Id] = destination
+
+	return destination.Id, nil
+}
+
+func (r *MemoryDestinationRepository) Update(ctx context.Context, destination models.Destination) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.destinations[destination.Id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	// The quota is managed through the availability endpoints
+	destination.DailyQuota = existing.DailyQuota
+	r.store.destinations[destination.Id] = destination
+
+	return nil
+}
+
+func (r *MemoryDestinationRepository) Delete(ctx context.Context, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.destinations[id]; !ok {
+		return ErrNotFound
+	}
+
+	delete(r.store.destinations, id)
+
+	return nil
+}
+
+func (r *MemoryDestinationRepository) SearchDocuments(ctx context.Context) ([]models.DestinationDocument, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var documents []models.DestinationDocument
+
+	for _, destination := range values(r.store.destinations) {
+		cityID, _ := strconv.Atoi(destination.City)
+
+		documents = append(documents, models.DestinationDocument{
+			Id:              destination.Id,
+			DestinationName: destination.DestinationName,
+			Image:           destination.Image,
+			CityID:          cityID,
+			CityName:        r.store.cities[cityID].CityName,
+			Description:     destination.Description,
+			Price:           destination.Price,
+		})
+	}
+
+	return documents, nil
+}
+
+type MemoryUserRepository struct {
+	store *memoryStore
+}
+
+func (r *MemoryUserRepository) FindByID(ctx context.Context, id int) (models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return user, ErrNotFound
+	}
+
+	return user, nil
+}
+
+func (r *MemoryUserRepository) FindByEmail(ctx context.Context, email string) (models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, user := range r.store.users {
+		if strings.EqualFold(user.Email, email) {
+			return user, nil
+		}
+	}
+
+	return models.User{}, ErrNotFound
+}
+
+func (r *MemoryUserRepository) CreateCustomer(ctx context.Context, customer models.CustomerRequest, passwordHash string) (models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.store.emailTaken(customer.Email) {
+		return models.User{}, ErrDuplicate
+	}
+
+	accountID := r.store.nextID("customers")
+	r.store.customers[accountID] = models.Customer{Id: accountID, FullName: customer.FullName, Email: customer.Email, Phone: customer.Phone}
+
+	return r.store.createUser(customer.Email, passwordHash, "customer", accountID), nil
+}
+
+func (r *MemoryUserRepository) CreateAdmin(ctx context.Context, admin models.AdminRequest, passwordHash string, role string) (models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.store.emailTaken(admin.Email) {
+		return models.User{}, ErrDuplicate
+	}
+
+	accountID := r.store.nextID("admin")
+	r.store.admins[accountID] = models.Admin{Id: accountID, FullName: admin.FullName, Email: admin.Email, Phone: admin.Phone}
+
+	return r.store.createUser(admin.Email, passwordHash, role, accountID), nil
+}
+
+func (s *memoryStore) createUser(email string, passwordHash string, role string, accountID int) models.User {
+	user := models.User{
+		Id:        s.nextID("users"),
+		Email:     email,
+		Password:  passwordHash,
+		Role:      role,
+		AccountID: strconv.Itoa(accountID),
+	}
+
+	s.users[user.Id] = user
+
+	return user
+}
+
+func (r *MemoryUserRepository) FindProfile(ctx context.Context, id int) (models.Profile, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return models.Profile{}, ErrNotFound
+	}
+
+	profile := models.Profile{Id: user.Id, Role: user.Role, Email: user.Email, EmailVerifiedAt: user.EmailVerifiedAt}
+	profile.AccountID, _ = strconv.Atoi(user.AccountID)
+
+	if accountTableOf(user) == "customers" {
+		customer, ok := r.store.customers[profile.AccountID]
+		if !ok {
+			return models.Profile{}, ErrNotFound
+		}
+
+		profile.FullName, profile.Phone = customer.FullName, customer.Phone
+	} else {
+		admin, ok := r.store.admins[profile.AccountID]
+		if !ok {
+			return models.Profile{}, ErrNotFound
+		}
+
+		profile.FullName, profile.Phone = admin.FullName, admin.Phone
+	}
+
+	return profile, nil
+}
+
+func (r *MemoryUserRepository) UpdateProfile(ctx context.Context, id int, profile models.ProfileRequest) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	table := accountTableOf(user)
+	accountID, _ := strconv.Atoi(user.AccountID)
+
+	if err := r.store.updateLoginEmail(table, accountID, profile.Email); err != nil {
+		return err
+	}
+
+	if table == "customers" {
+		r.store.customers[accountID] = models.Customer{Id: accountID, FullName: profile.FullName, Email: profile.Email, Phone: profile.Phone}
+	} else {
+		r.store.admins[accountID] = models.Admin{Id: accountID, FullName: profile.FullName, Email: profile.Email, Phone: profile.Phone}
+	}
+
+	return nil
+}
+
+func (r *MemoryUserRepository) UpdatePassword(ctx context.Context, id int, passwordHash string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	user.Password = passwordHash
+	r.store.users[id] = user
+
+	return nil
+}
+
+func (r *MemoryUserRepository) Delete(ctx context.Context, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	accountID, _ := strconv.Atoi(user.AccountID)
+
+	if accountTableOf(user) == "customers" {
+		r.store.customers[accountID] = models.Customer{Id: accountID, FullName: deletedCustomerName}
+
+		for _, booking := range r.store.bookings {
+			if booking.CustomerID == accountID {
+				booking.CustomerName = deletedCustomerName
+				r.store.bookings[booking.Id] = booking
+			}
+		}
+	} else {
+		delete(r.store.admins, accountID)
+	}
+
+	r.store.deleteLogin(id)
+
+	return nil
+}
+
+// deleteLoginOf deletes the login account using a customer or admin record,
+// if any
+func (s *memoryStore) deleteLoginOf(table string, accountID int) {
+	for id, user := range s.users {
+		if accountTableOf(user) == table && user.AccountID == strconv.Itoa(accountID) {
+			s.deleteLogin(id)
+		}
+	}
+}
+
+// deleteLogin removes a login account with its tokens and moves it on to the
+// next token generation the way deleteLogin of the MySQL store does
+func (s *memoryStore) deleteLogin(id int) {
+	for hash, token := range s.tokens {
+		if token.userID == id {
+			delete(s.tokens, hash)
+		}
+	}
+
+	for tokenID, token := range s.refreshTokens {
+		if token.userID == id {
+			delete(s.refreshTokens, tokenID)
+		}
+	}
+
+	s.generations[id]++
+	delete(s.users, id)
+}
+
+type accountToken struct {
+	userID    int
+	purpose   string
+	expiresAt time.Time
+	used      bool
+}
+
+func (r *MemoryUserRepository) MarkEmailVerified(ctx context.Context, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	r.store.verifyEmail(&user)
+
+	return nil
+}
+
+func (r *MemoryUserRepository) IssueToken(ctx context.Context, id int, purpose string, tokenHash string, ttl time.Duration) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for hash, token := range r.store.tokens {
+		if token.userID == id && token.purpose == purpose {
+			token.used = true
+			r.store.tokens[hash] = token
+		}
+	}
+
+	r.store.tokens[tokenHash] = accountToken{userID: id, purpose: purpose, expiresAt: time.Now().Add(ttl)}
+
+	return nil
+}
+
+func (r *MemoryUserRepository) VerifyEmail(ctx context.Context, tokenHash string) (models.User, error) {
+	return r.useToken(models.TokenVerifyEmail, tokenHash, func(user *models.User) {})
+}
+
+func (r *MemoryUserRepository) ResetPassword(ctx context.Context, tokenHash string, passwordHash string) (models.User, error) {
+	return r.useToken(models.TokenResetPassword, tokenHash, func(user *models.User) {
+		user.Password = passwordHash
+	})
+}
+
+func (r *MemoryUserRepository) useToken(purpose string, tokenHash string, apply func(user *models.User)) (models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	token, ok := r.store.tokens[tokenHash]
+	if !ok || token.purpose != purpose || token.used || !time.Now().Before(token.expiresAt) {
+		return models.User{}, ErrNotFound
+	}
+
+	user, ok := r.store.users[token.userID]
+	if !ok {
+		return models.User{}, ErrNotFound
+	}
+
+	token.used = true
+	r.store.tokens[tokenHash] = token
+
+	apply(&user)
+	r.store.verifyEmail(&user)
+
+	return user, nil
+}
+
+// verifyEmail marks the email of user verified and saves it
+func (s *memoryStore) verifyEmail(user *models.User) {
+	if user.EmailVerifiedAt == nil {
+		now := time.Now().Format(memoryTimeLayout)
+		user.EmailVerifiedAt = &now
+	}
+
+	s.users[user.Id] = *user
+}
+
+type MemoryBookingRepository struct {
+	store *memoryStore
+}
+
+func bookingField(booking models.Booking, column string) interface{} {
+	switch column {
+	case "id":
+		return booking.Id
+	case "customer_id":
+		return booking.CustomerID
+	case "destination_id":
+		return booking.DestinationID
+	case "booking_date":
+		return booking.TanggalBooking
+	case "qty":
+		return booking.Qty
+	case "total":
+		return booking.Total
+	case "status":
+		return booking.Status
+	case "created_at":
+		return booking.CreatedAt
+	}
+
+	return nil
+}
+
+func (r *MemoryBookingRepository) List(ctx context.Context, opts models.ListOptions) (models.Page[models.Booking], error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return listItems(values(r.store.bookings), opts, bookingField, func(booking models.Booking) int { return booking.Id }), nil
+}
+
+func (r *MemoryBookingRepository) FindByID(ctx context.Context, id int) (models.Booking, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	booking, ok := r.store.bookings[id]
+	if !ok {
+		return booking, ErrNotFound
+	}
+
+	return booking, nil
+}
+
+func (r *MemoryBookingRepository) ListByCustomer(ctx context.Context, customerID int) ([]models.Booking, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	bookings := []models.Booking{}
+
+	for _, booking := range values(r.store.bookings) {
+		if booking.CustomerID == customerID {
+			bookings = append(bookings, booking)
+		}
+	}
+
+	return bookings, nil
+}
+
+func (r *MemoryBookingRepository) Create(ctx context.Context, request models.BookingRequest, policy models.BookingPolicy) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	destination, ok := r.store.destinations[request.DestinationID]
+	if !ok {
+		return 0, models.ErrDestinationNotFound
+	}
+
+	key := dateKey(request.DestinationID, request.TanggalBooking)
+
+	quota := destination.DailyQuota
+	if override, ok := r.store.overrides[key]; ok {
+		quota = &override.quota
+	}
+
+	if quota != nil && r.store.reserved[key]+request.Qty > *quota {
+		return 0, models.ErrSoldOut
+	}
+
+	r.store.reserved[key] += request.Qty
+
+	cityID, _ := strconv.Atoi(destination.City)
+	price := models.CalculatePrice(destination.Price, request.Qty, 0, policy.TaxRateBps)
+	now := time.Now()
+	expiresAt := now.Add(time.Duration(policy.HoldMinutes) * time.Minute).Format(memoryTimeLayout)
+
+	booking := models.Booking{
+		Id:              r.store.nextID("booking"),
+		CustomerID:      request.CustomerID,
+		CustomerName:    r.store.customers[request.CustomerID].FullName,
+		Qty:             request.Qty,
+		DestinationID:   request.DestinationID,
+		DestinationName: destination.DestinationName,
+		CityName:        r.store.cities[cityID].CityName,
+		TanggalBooking:  request.TanggalBooking,
+		Currency:        models.Currency,
+		UnitPrice:       price.UnitPrice,
+		Subtotal:        price.Subtotal,
+		Discount:        price.Discount,
+		Tax:             price.Tax,
+		Total:           price.Total,
+		Status:          models.BookingPending,
+		CreatedAt:       now.Format(memoryTimeLayout),
+		ExpiresAt:       &expiresAt,
+	}
+
+	r.store.bookings[booking.Id] = booking
+
+	return booking.Id, nil
+}
+
+func (r *MemoryBookingRepository) Transition(ctx context.Context, id int, to string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.transition(id, to)
+}
+
+func (r *MemoryBookingRepository) Expire(ctx context.Context, limit int) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	expired := 0
+
+	for _, booking := range values(r.store.bookings) {
+		if expired == limit {
+			break
+		}
+
+		if booking.Status != models.BookingPending || booking.ExpiresAt == nil {
+			continue
+		}
+
+		expiresAt, err := time.Parse(memoryTimeLayout, *booking.ExpiresAt)
+		if err != nil || expiresAt.After(now) {
+			continue
+		}
+
+		if err = r.store.transition(booking.Id, models.BookingExpired); err != nil {
+			return expired, err
+		}
+
+		expired++
+	}
+
+	return expired, nil
+}
+
+// transition moves a booking to another status the way transitionBooking
+// does
+func (s *memoryStore) transition(id int, to string) error {
+	booking, ok := s.bookings[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	if !models.CanTransitionBooking(booking.Status, to) {
+		return fmt.Errorf("%w from %s to %s", models.ErrInvalidTransition, booking.Status, to)
+	}
+
+	if models.HoldsCapacity(booking.Status) && !models.HoldsCapacity(to) {
+		s.reserved[dateKey(booking.DestinationID, booking.TanggalBooking)] -= booking.Qty
+	}
+
+	now := time.Now().Format(memoryTimeLayout)
+
+	switch to {
+	case models.BookingConfirmed:
+		booking.ConfirmedAt = &now
+	case models.BookingCheckedIn:
+		booking.CheckedInAt = &now
+	case models.BookingCancelled:
+		booking.CancelledAt = &now
+	case models.BookingExpired:
+		booking.ExpiredAt = &now
+	case models.BookingRefunded:
+		booking.RefundedAt = &now
+	}
+
+	booking.Status = to
+	s.bookings[id] = booking
+
+	return nil
+}
+
+// dateKey is the key of the tickets reserved and the quota overrides of a
+// destination on one date
+func dateKey(destinationID int, date string) string {
+	return strconv.Itoa(destinationID) + "|" + date
+}
+
+type MemoryAvailabilityRepository struct {
+	store *memoryStore
+}
+
+func (r *MemoryAvailabilityRepository) Find(ctx context.Context, destinationID int, from time.Time, to time.Time) ([]models.Availability, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	destination, ok := r.store.destinations[destinationID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	overrides := make(map[string]quotaOverride)
+	sold := make(map[string]int)
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(models.DateLayout)
+		key := dateKey(destinationID, date)
+
+		if override, ok := r.store.overrides[key]; ok {
+			overrides[date] = override
+		}
+
+		sold[date] = r.store.reserved[key]
+	}
+
+	return availabilityDays(from, to, destination.DailyQuota, overrides, sold), nil
+}
+
+func (r *MemoryAvailabilityRepository) UpdateDailyQuota(ctx context.Context, destinationID int, quota *int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	destination, ok := r.store.destinations[destinationID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	destination.DailyQuota = quota
+	r.store.destinations[destinationID] = destination
+
+	return nil
+}
+
+func (r *MemoryAvailabilityRepository) StoreOverride(ctx context.Context, destinationID int, date string, quota int, note string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.overrides[dateKey(destinationID, date)] = quotaOverride{quota: quota, note: note}
+
+	return nil
+}
+
+func (r *MemoryAvailabilityRepository) DeleteOverride(ctx context.Context, destinationID int, date string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := dateKey(destinationID, date)
+
+	if _, ok := r.store.overrides[key]; !ok {
+		return ErrNotFound
+	}
+
+	delete(r.store.overrides, key)
+
+	return nil
+}
+
+type MemoryPaymentRepository struct {
+	store *memoryStore
+}
+
+func (r *MemoryPaymentRepository) Create(ctx context.Context, payment models.Payment, expiresAt time.Time) (models.Payment, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.payments {
+		if existing.OrderID == payment.OrderID {
+			return models.Payment{}, ErrDuplicate
+		}
+	}
+
+	payment.Id = r.store.nextID("payments")
+	payment.CreatedAt = time.Now().Format(memoryTimeLayout)
+	payment.ExpiresAt = nil
+
+	if !expiresAt.IsZero() {
+		expires := expiresAt.Format(memoryTimeLayout)
+		payment.ExpiresAt = &expires
+	}
+
+	r.store.payments[payment.Id] = payment
+
+	return payment, nil
+}
+
+func (r *MemoryPaymentRepository) FindByOrderID(ctx context.Context, orderID string) (models.Payment, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	payment, ok := r.store.paymentByOrderID(orderID)
+	if !ok {
+		return models.Payment{}, ErrNotFound
+	}
+
+	return payment, nil
+}
+
+func (r *MemoryPaymentRepository) FindLatestByBooking(ctx context.Context, bookingID int) (models.Payment, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	payments := values(r.store.payments)
+
+	for i := len(payments) - 1; i >= 0; i-- {
+		if payments[i].BookingID == bookingID {
+			return payments[i], nil
+		}
+	}
+
+	return models.Payment{}, ErrNotFound
+}
+
+func (r *MemoryPaymentRepository) UpdateStatus(ctx context.Context, orderID string, from string, to string) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.updatePaymentStatus(orderID, from, to), nil
+}
+
+func (r *MemoryPaymentRepository) MarkPaid(ctx context.Context, orderID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if !r.store.updatePaymentStatus(orderID, payments.StatusPending, payments.StatusPaid) {
+		return nil
+	}
+
+	payment, _ := r.store.paymentByOrderID(orderID)
+
+	err := r.store.transition(payment.BookingID, models.BookingConfirmed)
+	if errors.Is(err, models.ErrInvalidTransition) {
+		return nil
+	}
+
+	return err
+}
+
+func (r *MemoryPaymentRepository) MarkRefunded(ctx context.Context, orderID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if !r.store.updatePaymentStatus(orderID, payments.StatusPaid, payments.StatusRefunded) {
+		return nil
+	}
+
+	payment, _ := r.store.paymentByOrderID(orderID)
+
+	err := r.store.transition(payment.BookingID, models.BookingRefunded)
+	if errors.Is(err, models.ErrInvalidTransition) {
+		return nil
+	}
+
+	return err
+}
+
+func (r *MemoryPaymentRepository) ClaimRefund(ctx context.Context, bookingID int) (models.Payment, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	booking, ok := r.store.bookings[bookingID]
+	if !ok {
+		return models.Payment{}, ErrNotFound
+	}
+
+	if !models.CanTransitionBooking(booking.Status, models.BookingRefunded) {
+		return models.Payment{}, fmt.Errorf("%w from %s to %s", models.ErrInvalidTransition, booking.Status, models.BookingRefunded)
+	}
+
+	stored := values(r.store.payments)
+
+	for i := len(stored) - 1; i >= 0; i-- {
+		if stored[i].BookingID != bookingID {
+			continue
+		}
+
+		if !r.store.updatePaymentStatus(stored[i].OrderID, payments.StatusPaid, payments.StatusRefunding) {
+			return models.Payment{}, ErrNotFound
+		}
+
+		payment, _ := r.store.paymentByOrderID(stored[i].OrderID)
+
+		return payment, nil
+	}
+
+	return models.Payment{}, ErrNotFound
+}
+
+func (r *MemoryPaymentRepository) CompleteRefund(ctx context.Context, orderID string, bookingID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	booking, ok := r.store.bookings[bookingID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	if !models.CanTransitionBooking(booking.Status, models.BookingRefunded) {
+		return fmt.Errorf("%w from %s to %s", models.ErrInvalidTransition, booking.Status, models.BookingRefunded)
+	}
+
+	if !r.store.updatePaymentStatus(orderID, payments.StatusRefunding, payments.StatusRefunded) {
+		return ErrNotFound
+	}
+
+	return r.store.transition(bookingID, models.BookingRefunded)
+}
+
+func (r *MemoryPaymentRepository) ReleaseRefund(ctx context.Context, orderID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if !r.store.updatePaymentStatus(orderID, payments.StatusRefunding, payments.StatusPaid) {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *memoryStore) paymentByOrderID(orderID string) (models.Payment, bool) {
+	for _, payment := range s.payments {
+		if payment.OrderID == orderID {
+			return payment, true
+		}
+	}
+
+	return models.Payment{}, false
+}
+
+// updatePaymentStatus moves a payment from one status to another the way
+// the MySQL implementation does, reporting whether it did
+func (s *memoryStore) updatePaymentStatus(orderID string, from string, to string) bool {
+	payment, ok := s.paymentByOrderID(orderID)
+	if !ok || payment.Status != from {
+		return false
+	}
+
+	payment.Status = to
+
+	if from == payments.StatusPending && to == payments.StatusPaid {
+		now := time.Now().Format(memoryTimeLayout)
+		payment.PaidAt = &now
+	}
+
+	s.payments[payment.Id] = payment
+
+	return true
+}
+
+type MemoryCheckInRepository struct {
+	store *memoryStore
+}
+
+func (r *MemoryCheckInRepository) CheckIn(ctx context.Context, bookingID int, seat int, destinationID int, staffID int) (models.CheckIn, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	booking, ok := r.store.bookings[bookingID]
+	if !ok {
+		return models.CheckIn{}, ErrNotFound
+	}
+
+	if err := models.CanCheckIn(booking, seat, destinationID); err != nil {
+		return models.CheckIn{}, err
+	}
+
+	key := strconv.Itoa(bookingID) + "|" + strconv.Itoa(seat)
+
+	if r.store.checkIns[key] {
+		return models.CheckIn{}, models.ErrTicketAlreadyUsed
+	}
+
+	r.store.checkIns[key] = true
+
+	used := 0
+
+	for s := 1; s <= booking.Qty; s++ {
+		if r.store.checkIns[strconv.Itoa(bookingID)+"|"+strconv.Itoa(s)] {
+			used++
+		}
+	}
+
+	if used == booking.Qty && booking.Status == models.BookingConfirmed {
+		if err := r.store.transition(bookingID, models.BookingCheckedIn); err != nil {
+			return models.CheckIn{}, err
+		}
+
+		booking.Status = models.BookingCheckedIn
+	}
+
+	return models.CheckIn{
+		BookingID:      bookingID,
+		Seat:           seat,
+		Qty:            booking.Qty,
+		SeatsCheckedIn: used,
+		BookingDate:    booking.TanggalBooking,
+		Status:         booking.Status,
+	}, nil
+}
+
+type memoryRefreshToken struct {
+	userID    int
+	tokenHash string
+	expiresAt time.Time
+	revoked   bool
+}
+
+type MemoryTokenRepository struct {
+	store *memoryStore
+}
+
+func (r *MemoryTokenRepository) StoreRefresh(ctx context.Context, userID int, tokenHash string, ttl time.Duration) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	id := r.store.nextID("refresh_tokens")
+	r.store.refreshTokens[id] = memoryRefreshToken{userID: userID, tokenHash: tokenHash, expiresAt: time.Now().Add(ttl)}
+
+	return nil
+}
+
+func (r *MemoryTokenRepository) FindRefresh(ctx context.Context, tokenHash string) (models.RefreshToken, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, token := range r.store.refreshTokens {
+		if token.tokenHash == tokenHash && !token.revoked && time.Now().Before(token.expiresAt) {
+			return models.RefreshToken{Id: id, UserID: token.userID}, nil
+		}
+	}
+
+	return models.RefreshToken{}, ErrNotFound
+}
+
+func (r *MemoryTokenRepository) UseRefresh(ctx context.Context, id int) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	token, ok := r.store.refreshTokens[id]
+	if !ok || token.revoked {
+		return false, nil
+	}
+
+	token.revoked = true
+	r.store.refreshTokens[id] = token
+
+	return true, nil
+}
+
+func (r *MemoryTokenRepository) RevokeRefresh(ctx context.Context, userID int, tokenHash string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, token := range r.store.refreshTokens {
+		if token.userID == userID && token.tokenHash == tokenHash {
+			token.revoked = true
+			r.store.refreshTokens[id] = token
+		}
+	}
+
+	return nil
+}
+
+func (r *MemoryTokenRepository) Revoke(ctx context.Context, jti string, userID int, expiresAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.revokedTokens[jti] = true
+
+	return nil
+}
+
+func (r *MemoryTokenRepository) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.revokedTokens[jti], nil
+}
+
+func (r *MemoryTokenRepository) RevokeAll(ctx context.Context, userID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.generations[userID]++
+
+	for id, token := range r.store.refreshTokens {
+		if token.userID == userID {
+			token.revoked = true
+			r.store.refreshTokens[id] = token
+		}
+	}
+
+	return nil
+}
+
+func (r *MemoryTokenRepository) Generation(ctx context.Context, userID int) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.generations[userID], nil
+}
+
+type loginThrottle struct {
+	failures      int
+	lastFailureAt time.Time
+	lockedUntil   time.Time
+}
+
+type MemoryLoginRepository struct {
+	store *memoryStore
+}
+
+func (r *MemoryLoginRepository) LockedUntil(ctx context.Context, email string) (time.Time, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.throttles[strings.ToLower(email)].lockedUntil, nil
+}
+
+func (r *MemoryLoginRepository) RecordFailure(ctx context.Context, failure models.LoginFailure, policy models.LockoutPolicy) (time.Time, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.failures = append(r.store.failures, failure)
+
+	now := time.Now()
+	key := strings.ToLower(failure.Email)
+	throttle := r.store.throttles[key]
+
+	if now.Sub(throttle.lastFailureAt) > policy.Window {
+		throttle.failures = 0
+	}
+
+	throttle.failures++
+	throttle.lastFailureAt = now
+
+	if lock := policy.LockFor(throttle.failures); lock > 0 {
+		throttle.lockedUntil = now.Add(lock)
+	}
+
+	r.store.throttles[key] = throttle
+
+	return throttle.lockedUntil, nil
+}
+
+func (r *MemoryLoginRepository) Audit(ctx context.Context, failure models.LoginFailure) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.failures = append(r.store.failures, failure)
+
+	return nil
+}
+
+func (r *MemoryLoginRepository) Reset(ctx context.Context, email string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.throttles, strings.ToLower(email))
+
+	return nil
+}
+
+type MemoryHealthRepository struct{}
+
+// Ping always succeeds, memory is always there
+func (r *MemoryHealthRepository) Ping(ctx context.Context) error {
+	return nil
+}
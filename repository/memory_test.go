@@ -0,0 +1,558 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/models"
+	"github.com/bryansamperura/ticket-booking/payments"
+)
+
+var testPolicy = models.BookingPolicy{TaxRateBps: 1100, HoldMinutes: 15}
+
+// newTestDestination stores a destination costing Rp 50.000 selling
+// dailyQuota tickets a day, nil for no limit
+func newTestDestination(t *testing.T, repos Repositories, dailyQuota *int) int {
+	t.Helper()
+
+	id, err := repos.Destinations.Create(context.Background(), models.Destination{
+		DestinationName: "Raja Ampat",
+		Price:           50000,
+		DailyQuota:      dailyQuota,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return id
+}
+
+func newTestCustomer(t *testing.T, repos Repositories) int {
+	t.Helper()
+
+	id, err := repos.Customers.Create(context.Background(), models.CustomerRequest{
+		FullName: "Yohana Wanma",
+		Email:    "yohana@example.com",
+		Phone:    "+6281234567890",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return id
+}
+
+func tomorrow() string {
+	return time.Now().AddDate(0, 0, 1).Format(models.DateLayout)
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestMemoryBookingCreate(t *testing.T) {
+	ctx := context.Background()
+	repos := NewMemory()
+	destinationID := newTestDestination(t, repos, nil)
+	customerID := newTestCustomer(t, repos)
+
+	tests := []struct {
+		name    string
+		request models.BookingRequest
+		wantErr error
+	}{
+		{
+			name:    "prices and holds the booking",
+			request: models.BookingRequest{CustomerID: customerID, DestinationID: destinationID, Qty: 2, TanggalBooking: tomorrow()},
+		},
+		{
+			name:    "unknown destination",
+			request: models.BookingRequest{CustomerID: customerID, DestinationID: destinationID + 100, Qty: 1, TanggalBooking: tomorrow()},
+			wantErr: models.ErrDestinationNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := repos.Bookings.Create(ctx, tt.request, testPolicy)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			booking, err := repos.Bookings.FindByID(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := models.CalculatePrice(50000, tt.request.Qty, 0, testPolicy.TaxRateBps)
+
+			if booking.Status != models.BookingPending {
+				t.Errorf("Status = %q, want %q", booking.Status, models.BookingPending)
+			}
+
+			if booking.Total != want.Total || booking.Tax != want.Tax {
+				t.Errorf("Total, Tax = %d, %d, want %d, %d", booking.Total, booking.Tax, want.Total, want.Tax)
+			}
+
+			if booking.CustomerName != "Yohana Wanma" || booking.DestinationName != "Raja Ampat" {
+				t.Errorf("names = %q, %q", booking.CustomerName, booking.DestinationName)
+			}
+
+			if booking.HoldExpiresAt().Sub(time.Now()) > 15*time.Minute {
+				t.Errorf("hold expires at %v, more than 15 minutes from now", booking.HoldExpiresAt())
+			}
+		})
+	}
+}
+
+func TestMemoryBookingQuota(t *testing.T) {
+	ctx := context.Background()
+	date := tomorrow()
+
+	tests := []struct {
+		name       string
+		dailyQuota *int
+		override   *int
+		booked     []int
+		qty        int
+		wantErr    error
+	}{
+		{name: "no limit", qty: 100, booked: []int{100}},
+		{name: "within the daily quota", dailyQuota: intPtr(5), booked: []int{2}, qty: 3},
+		{name: "past the daily quota", dailyQuota: intPtr(5), booked: []int{2, 2}, qty: 2, wantErr: models.ErrSoldOut},
+		{name: "override raises the quota", dailyQuota: intPtr(5), override: intPtr(10), booked: []int{5}, qty: 5},
+		{name: "override closes the day", dailyQuota: intPtr(5), override: intPtr(0), qty: 1, wantErr: models.ErrSoldOut},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := NewMemory()
+			destinationID := newTestDestination(t, repos, tt.dailyQuota)
+			customerID := newTestCustomer(t, repos)
+
+			if tt.override != nil {
+				if err := repos.Availability.StoreOverride(ctx, destinationID, date, *tt.override, "holiday"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for _, qty := range tt.booked {
+				request := models.BookingRequest{CustomerID: customerID, DestinationID: destinationID, Qty: qty, TanggalBooking: date}
+
+				if _, err := repos.Bookings.Create(ctx, request, testPolicy); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			request := models.BookingRequest{CustomerID: customerID, DestinationID: destinationID, Qty: tt.qty, TanggalBooking: date}
+
+			if _, err := repos.Bookings.Create(ctx, request, testPolicy); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMemoryBookingCancelReleasesQuota(t *testing.T) {
+	ctx := context.Background()
+	repos := NewMemory()
+	destinationID := newTestDestination(t, repos, intPtr(2))
+	customerID := newTestCustomer(t, repos)
+	request := models.BookingRequest{CustomerID: customerID, DestinationID: destinationID, Qty: 2, TanggalBooking: tomorrow()}
+
+	id, err := repos.Bookings.Create(ctx, request, testPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = repos.Bookings.Create(ctx, request, testPolicy); !errors.Is(err, models.ErrSoldOut) {
+		t.Fatalf("Create() error = %v, want %v", err, models.ErrSoldOut)
+	}
+
+	if err = repos.Bookings.Transition(ctx, id, models.BookingCancelled); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = repos.Bookings.Create(ctx, request, testPolicy); err != nil {
+		t.Errorf("Create() after cancelling error = %v", err)
+	}
+}
+
+func TestMemoryBookingTransition(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		path    []string
+		to      string
+		wantErr error
+	}{
+		{name: "pending to confirmed", to: models.BookingConfirmed},
+		{name: "pending to cancelled", to: models.BookingCancelled},
+		{name: "pending to expired", to: models.BookingExpired},
+		{name: "pending to checked in", to: models.BookingCheckedIn, wantErr: models.ErrInvalidTransition},
+		{name: "pending to refunded", to: models.BookingRefunded, wantErr: models.ErrInvalidTransition},
+		{name: "confirmed to checked in", path: []string{models.BookingConfirmed}, to: models.BookingCheckedIn},
+		{name: "confirmed to refunded", path: []string{models.BookingConfirmed}, to: models.BookingRefunded},
+		{name: "confirmed to expired", path: []string{models.BookingConfirmed}, to: models.BookingExpired, wantErr: models.ErrInvalidTransition},
+		{name: "cancelled to refunded", path: []string{models.BookingCancelled}, to: models.BookingRefunded},
+		{name: "cancelled to confirmed", path: []string{models.BookingCancelled}, to: models.BookingConfirmed, wantErr: models.ErrInvalidTransition},
+		{name: "expired to refunded", path: []string{models.BookingExpired}, to: models.BookingRefunded},
+		{name: "expired to confirmed", path: []string{models.BookingExpired}, to: models.BookingConfirmed, wantErr: models.ErrInvalidTransition},
+		{name: "checked in is final", path: []string{models.BookingConfirmed, models.BookingCheckedIn}, to: models.BookingCancelled, wantErr: models.ErrInvalidTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := NewMemory()
+			destinationID := newTestDestination(t, repos, nil)
+			customerID := newTestCustomer(t, repos)
+			request := models.BookingRequest{CustomerID: customerID, DestinationID: destinationID, Qty: 1, TanggalBooking: tomorrow()}
+
+			id, err := repos.Bookings.Create(ctx, request, testPolicy)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, status := range tt.path {
+				if err = repos.Bookings.Transition(ctx, id, status); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err = repos.Bookings.Transition(ctx, id, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Transition() error = %v, want %v", err, tt.wantErr)
+			}
+
+			booking, err := repos.Bookings.FindByID(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.wantErr == nil && booking.Status != tt.to {
+				t.Errorf("Status = %q, want %q", booking.Status, tt.to)
+			}
+		})
+	}
+
+	t.Run("unknown booking", func(t *testing.T) {
+		err := NewMemory().Bookings.Transition(ctx, 42, models.BookingConfirmed)
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("Transition() error = %v, want %v", err, ErrNotFound)
+		}
+	})
+}
+
+func TestMemoryRegisterAndLogin(t *testing.T) {
+	ctx := context.Background()
+	repos := NewMemory()
+	request := models.CustomerRequest{FullName: "Yohana Wanma", Email: "yohana@example.com", Phone: "+6281234567890"}
+
+	user, err := repos.Users.CreateCustomer(ctx, request, "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		email   string
+		wantErr error
+	}{
+		{name: "registered email", email: "yohana@example.com"},
+		{name: "email in another case", email: "Yohana@Example.com"},
+		{name: "unknown email", email: "someone@example.com", wantErr: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := repos.Users.FindByEmail(ctx, tt.email)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FindByEmail() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && (found.Id != user.Id || found.Password != "hash") {
+				t.Errorf("FindByEmail() = %+v, want user %d", found, user.Id)
+			}
+		})
+	}
+
+	t.Run("email taken", func(t *testing.T) {
+		if _, err := repos.Users.CreateCustomer(ctx, request, "hash"); !errors.Is(err, ErrDuplicate) {
+			t.Errorf("CreateCustomer() error = %v, want %v", err, ErrDuplicate)
+		}
+	})
+}
+
+func TestMemoryLoginLockout(t *testing.T) {
+	ctx := context.Background()
+	policy := models.LockoutPolicy{Threshold: 3, Base: time.Minute, Max: 4 * time.Minute, Window: time.Hour}
+
+	tests := []struct {
+		name     string
+		failures int
+		reset    bool
+		wantLock time.Duration
+	}{
+		{name: "below the threshold", failures: 2},
+		{name: "at the threshold", failures: 3, wantLock: time.Minute},
+		{name: "doubles past the threshold", failures: 4, wantLock: 2 * time.Minute},
+		{name: "capped at the maximum", failures: 10, wantLock: 4 * time.Minute},
+		{name: "reset after a login", failures: 5, reset: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := NewMemory()
+			failure := models.LoginFailure{Email: "yohana@example.com", IP: "203.0.113.7", Reason: models.LoginWrongPassword}
+
+			for i := 0; i < tt.failures; i++ {
+				if _, err := repos.Logins.RecordFailure(ctx, failure, policy); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if tt.reset {
+				if err := repos.Logins.Reset(ctx, "Yohana@example.com"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			lockedUntil, err := repos.Logins.LockedUntil(ctx, "YOHANA@example.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.wantLock == 0 {
+				if time.Now().Before(lockedUntil) {
+					t.Errorf("locked until %v, want unlocked", lockedUntil)
+				}
+
+				return
+			}
+
+			if lock := time.Until(lockedUntil); lock <= tt.wantLock-time.Second || lock > tt.wantLock {
+				t.Errorf("locked for %v, want %v", lock, tt.wantLock)
+			}
+		})
+	}
+}
+
+func TestMemoryRefundClaim(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		paid     bool
+		claimed  bool
+		gateway  bool
+		wantErr  error
+		wantPay  string
+		wantBook string
+	}{
+		{name: "unpaid booking", wantErr: models.ErrInvalidTransition, wantPay: payments.StatusPending, wantBook: models.BookingPending},
+		{name: "refunded by the gateway", paid: true, gateway: true, wantPay: payments.StatusRefunded, wantBook: models.BookingRefunded},
+		{name: "refused by the gateway", paid: true, wantPay: payments.StatusPaid, wantBook: models.BookingConfirmed},
+		{name: "claimed already", paid: true, claimed: true, wantErr: ErrNotFound, wantPay: payments.StatusRefunding, wantBook: models.BookingConfirmed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := NewMemory()
+			destinationID := newTestDestination(t, repos, nil)
+			customerID := newTestCustomer(t, repos)
+			request := models.BookingRequest{CustomerID: customerID, DestinationID: destinationID, Qty: 1, TanggalBooking: tomorrow()}
+
+			bookingID, err := repos.Bookings.Create(ctx, request, testPolicy)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			payment := models.Payment{BookingID: bookingID, Provider: "mock", OrderID: "BK1-1", Amount: 100, Status: payments.StatusPending}
+
+			if _, err = repos.Payments.Create(ctx, payment, time.Time{}); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.paid {
+				if err = repos.Payments.MarkPaid(ctx, "BK1-1"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if tt.claimed {
+				if _, err = repos.Payments.ClaimRefund(ctx, bookingID); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			claimed, err := repos.Payments.ClaimRefund(ctx, bookingID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ClaimRefund() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil {
+				if claimed.Status != payments.StatusRefunding {
+					t.Errorf("claimed Status = %q, want %q", claimed.Status, payments.StatusRefunding)
+				}
+
+				if tt.gateway {
+					err = repos.Payments.CompleteRefund(ctx, claimed.OrderID, bookingID)
+				} else {
+					err = repos.Payments.ReleaseRefund(ctx, claimed.OrderID)
+				}
+
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			stored, _ := repos.Payments.FindByOrderID(ctx, "BK1-1")
+			booking, _ := repos.Bookings.FindByID(ctx, bookingID)
+
+			if stored.Status != tt.wantPay || booking.Status != tt.wantBook {
+				t.Errorf("payment, booking = %q, %q, want %q, %q", stored.Status, booking.Status, tt.wantPay, tt.wantBook)
+			}
+		})
+	}
+}
+
+func TestMemoryMarkRefunded(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		expired   bool
+		paid      bool
+		checkedIn bool
+		wantPay   string
+		wantBook  string
+	}{
+		{name: "confirmed booking", paid: true, wantPay: payments.StatusRefunded, wantBook: models.BookingRefunded},
+		{name: "paid after the hold expired", expired: true, paid: true, wantPay: payments.StatusRefunded, wantBook: models.BookingRefunded},
+		{name: "checked in", paid: true, checkedIn: true, wantPay: payments.StatusRefunded, wantBook: models.BookingCheckedIn},
+		{name: "unpaid charge", wantPay: payments.StatusPending, wantBook: models.BookingPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := NewMemory()
+			destinationID := newTestDestination(t, repos, nil)
+			customerID := newTestCustomer(t, repos)
+			request := models.BookingRequest{CustomerID: customerID, DestinationID: destinationID, Qty: 1, TanggalBooking: tomorrow()}
+
+			bookingID, err := repos.Bookings.Create(ctx, request, testPolicy)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			payment := models.Payment{BookingID: bookingID, Provider: "mock", OrderID: "BK1-1", Amount: 100, Status: payments.StatusPending}
+
+			if _, err = repos.Payments.Create(ctx, payment, time.Time{}); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.expired {
+				if err = repos.Bookings.Transition(ctx, bookingID, models.BookingExpired); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if tt.paid {
+				if err = repos.Payments.MarkPaid(ctx, "BK1-1"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if tt.checkedIn {
+				if err = repos.Bookings.Transition(ctx, bookingID, models.BookingCheckedIn); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err = repos.Payments.MarkRefunded(ctx, "BK1-1"); err != nil {
+				t.Fatalf("MarkRefunded() error = %v", err)
+			}
+
+			stored, _ := repos.Payments.FindByOrderID(ctx, "BK1-1")
+			booking, _ := repos.Bookings.FindByID(ctx, bookingID)
+
+			if stored.Status != tt.wantPay || booking.Status != tt.wantBook {
+				t.Errorf("payment, booking = %q, %q, want %q, %q", stored.Status, booking.Status, tt.wantPay, tt.wantBook)
+			}
+		})
+	}
+}
+
+func TestMemoryDeleteAccountRemovesLogin(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		create func(repos Repositories) (models.User, error)
+		delete func(repos Repositories, accountID int) error
+	}{
+		{
+			name: "customer",
+			create: func(repos Repositories) (models.User, error) {
+				return repos.Users.CreateCustomer(ctx, models.CustomerRequest{FullName: "Yohana Wanma", Email: "yohana@example.com", Phone: "+6281234567890"}, "hash")
+			},
+			delete: func(repos Repositories, accountID int) error { return repos.Customers.Delete(ctx, accountID) },
+		},
+		{
+			name: "admin",
+			create: func(repos Repositories) (models.User, error) {
+				return repos.Users.CreateAdmin(ctx, models.AdminRequest{FullName: "Markus Rumbino", Email: "markus@example.com", Phone: "+6281234567891"}, "hash", "admin")
+			},
+			delete: func(repos Repositories, accountID int) error { return repos.Admins.Delete(ctx, accountID) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := NewMemory()
+
+			user, err := tt.create(repos)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err = repos.Tokens.StoreRefresh(ctx, user.Id, "refresh-hash", time.Hour); err != nil {
+				t.Fatal(err)
+			}
+
+			if err = repos.Users.IssueToken(ctx, user.Id, models.TokenResetPassword, "reset-hash", time.Hour); err != nil {
+				t.Fatal(err)
+			}
+
+			accountID, _ := strconv.Atoi(user.AccountID)
+
+			if err = tt.delete(repos, accountID); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+
+			if _, err = repos.Users.FindByID(ctx, user.Id); !errors.Is(err, ErrNotFound) {
+				t.Errorf("FindByID() error = %v, want %v", err, ErrNotFound)
+			}
+
+			if _, err = repos.Tokens.FindRefresh(ctx, "refresh-hash"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("FindRefresh() error = %v, want %v", err, ErrNotFound)
+			}
+
+			if _, err = repos.Users.ResetPassword(ctx, "reset-hash", "new-hash"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("ResetPassword() error = %v, want %v", err, ErrNotFound)
+			}
+
+			if generation, _ := repos.Tokens.Generation(ctx, user.Id); generation != 1 {
+				t.Errorf("Generation() = %d, want 1", generation)
+			}
+		})
+	}
+}
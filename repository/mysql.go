@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/bryansamperura/ticket-booking/logging"
+	"github.com/bryansamperura/ticket-booking/models"
+	"github.com/go-sql-driver/mysql"
+)
+
+// translate turns driver errors into the errors of this package. Other
+// errors are logged with the request they happened in.
+func translate(ctx context.Context, err error) error {
+	translated := translateError(err)
+
+	if translated != nil && translated != ErrNotFound && translated != ErrDuplicate {
+		logging.FromContext(ctx).Error("query failed", slog.String("error", err.Error()))
+	}
+
+	return translated
+}
+
+// translateError is translate for callers without a context
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrNotFound
+	case isDuplicateEntry(err):
+		return ErrDuplicate
+	}
+
+	return err
+}
+
+// listRows runs the count and the page query of a list and scans every row
+// with scan
+func listRows[T any](ctx context.Context, con *sql.DB, opts models.ListOptions, fromStatement string, selectStatement string, scan func(*sql.Rows) (T, error), idOf func(T) int) (models.Page[T], error) {
+	var items []T
+	var total int
+
+	countStatement, args := opts.CountQuery(fromStatement)
+
+	if err := con.QueryRowContext(ctx, countStatement, args...).Scan(&total); err != nil {
+		return models.Page[T]{}, translate(ctx, err)
+	}
+
+	sqlStatement, args := opts.Query(selectStatement)
+
+	rows, err := con.QueryContext(ctx, sqlStatement, args...)
+	if err != nil {
+		return models.Page[T]{}, translate(ctx, err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		obj, err := scan(rows)
+		if err != nil {
+			return models.Page[T]{}, err
+		}
+		items = append(items, obj)
+	}
+
+	if err := rows.Err(); err != nil {
+		return models.Page[T]{}, translate(ctx, err)
+	}
+
+	return models.NewPage(items, total, opts, idOf), nil
+}
+
+// execAffecting runs a statement that must change a row of table with the
+// given id, returning ErrNotFound when there is no such row
+func execAffecting(ctx context.Context, con dbtx, table string, id int, sqlStatement string, args ...interface{}) error {
+	result, err := con.ExecContext(ctx, sqlStatement, args...)
+	if err != nil {
+		return translate(ctx, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	// An update setting the values a row already has affects nothing either
+	var exists int
+
+	err = con.QueryRowContext(ctx, "SELECT 1 FROM "+table+" WHERE id = ?", id).Scan(&exists)
+
+	return translate(ctx, err)
+}
+
+func insertID(result sql.Result, err error) (int, error) {
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	lastInsertedId, err := result.LastInsertId()
+
+	return int(lastInsertedId), err
+}
+
+// isDuplicateEntry reports whether err is a MySQL unique key violation
+func isDuplicateEntry(err error) bool {
+	var mysqlErr *mysql.MySQLError
+
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// dbtx is a pool or a transaction
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type MySQLHealthRepository struct {
+	con *sql.DB
+}
+
+func (r *MySQLHealthRepository) Ping(ctx context.Context) error {
+	return r.con.PingContext(ctx)
+}
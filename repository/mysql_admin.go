@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bryansamperura/ticket-booking/db"
+	"github.com/bryansamperura/ticket-booking/models"
+)
+
+type MySQLAdminRepository struct {
+	con *sql.DB
+}
+
+func NewMySQLAdminRepository(con *sql.DB) *MySQLAdminRepository {
+	return &MySQLAdminRepository{con: con}
+}
+
+func scanAdmin(row rowScanner) (models.Admin, error) {
+	var admin models.Admin
+
+	err := row.Scan(&admin.Id, &admin.FullName, &admin.Email, &admin.Phone)
+
+	return admin, err
+}
+
+func (r *MySQLAdminRepository) List(ctx context.Context, opts models.ListOptions) (models.Page[models.Admin], error) {
+	return listRows(ctx, r.con, opts, "FROM admin", "SELECT admin.id, admin.fullname, admin.email, admin.phone FROM admin",
+		func(rows *sql.Rows) (models.Admin, error) { return scanAdmin(rows) },
+		func(admin models.Admin) int { return admin.Id })
+}
+
+func (r *MySQLAdminRepository) FindByID(ctx context.Context, id int) (models.Admin, error) {
+	admin, err := scanAdmin(r.con.QueryRowContext(ctx, "SELECT id, fullname, email, phone FROM admin WHERE id = ?", id))
+
+	return admin, translate(ctx, err)
+}
+
+func (r *MySQLAdminRepository) Update(ctx context.Context, id int, admin models.AdminRequest) error {
+	return db.TransactionContext(ctx, r.con, func(tx *sql.Tx) error {
+		return updateAccount(ctx, tx, "admin", id, admin.FullName, admin.Email, admin.Phone)
+	})
+}
+
+func (r *MySQLAdminRepository) Delete(ctx context.Context, id int) error {
+	return deleteAccount(ctx, r.con, "admin", id)
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/models"
+)
+
+type MySQLAvailabilityRepository struct {
+	con *sql.DB
+}
+
+type quotaOverride struct {
+	quota int
+	note  string
+}
+
+func (r *MySQLAvailabilityRepository) Find(ctx context.Context, destinationID int, from time.Time, to time.Time) ([]models.Availability, error) {
+	var dailyQuota *int
+
+	err := r.con.QueryRowContext(ctx, "SELECT daily_quota FROM destination WHERE id = ?", destinationID).Scan(&dailyQuota)
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+
+	overrides := make(map[string]quotaOverride)
+
+	sqlStatement := `SELECT DATE_FORMAT(quota_date, '%Y-%m-%d'), quota, note
+					FROM destination_quota_overrides
+					WHERE destination_id = ? AND quota_date BETWEEN ? AND ?`
+
+	rows, err := r.con.QueryContext(ctx, sqlStatement, destinationID, from.Format(models.DateLayout), to.Format(models.DateLayout))
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var date string
+		var override quotaOverride
+
+		if err = rows.Scan(&date, &override.quota, &override.note); err != nil {
+			return nil, err
+		}
+
+		overrides[date] = override
+	}
+
+	sold := make(map[string]int)
+
+	sqlStatement = `SELECT DATE_FORMAT(inventory_date, '%Y-%m-%d'), sold
+					FROM destination_inventory
+					WHERE destination_id = ? AND inventory_date BETWEEN ? AND ?`
+
+	inventoryRows, err := r.con.QueryContext(ctx, sqlStatement, destinationID, from.Format(models.DateLayout), to.Format(models.DateLayout))
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+
+	defer inventoryRows.Close()
+
+	for inventoryRows.Next() {
+		var date string
+		var qty int
+
+		if err = inventoryRows.Scan(&date, &qty); err != nil {
+			return nil, err
+		}
+
+		sold[date] = qty
+	}
+
+	return availabilityDays(from, to, dailyQuota, overrides, sold), nil
+}
+
+// availabilityDays works out the availability of every day between from and
+// to from the default quota, the overrides and the tickets sold per date
+func availabilityDays(from time.Time, to time.Time, dailyQuota *int, overrides map[string]quotaOverride, sold map[string]int) []models.Availability {
+	var days []models.Availability
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(models.DateLayout)
+
+		obj := models.Availability{Date: date, Quota: dailyQuota, Sold: sold[date]}
+
+		if override, ok := overrides[date]; ok {
+			quota := override.quota
+			obj.Quota = &quota
+			obj.Note = override.note
+		}
+
+		if obj.Quota != nil {
+			remaining := *obj.Quota - obj.Sold
+			if remaining < 0 {
+				remaining = 0
+			}
+
+			obj.Remaining = &remaining
+			obj.Closed = *obj.Quota == 0
+		}
+
+		days = append(days, obj)
+	}
+
+	return days
+}
+
+func (r *MySQLAvailabilityRepository) UpdateDailyQuota(ctx context.Context, destinationID int, quota *int) error {
+	return execAffecting(ctx, r.con, "destination", destinationID, "UPDATE destination SET daily_quota = ? WHERE id = ?", quota, destinationID)
+}
+
+func (r *MySQLAvailabilityRepository) StoreOverride(ctx context.Context, destinationID int, date string, quota int, note string) error {
+	sqlStatement := `INSERT INTO destination_quota_overrides(destination_id, quota_date, quota, note) VALUES (?, ?, ?, ?)
+					ON DUPLICATE KEY UPDATE quota = VALUES(quota), note = VALUES(note)`
+
+	_, err := r.con.ExecContext(ctx, sqlStatement, destinationID, date, quota, note)
+
+	return translate(ctx, err)
+}
+
+func (r *MySQLAvailabilityRepository) DeleteOverride(ctx context.Context, destinationID int, date string) error {
+	sqlStatement := "DELETE FROM destination_quota_overrides WHERE destination_id = ? AND quota_date = ?"
+
+	result, err := r.con.ExecContext(ctx, sqlStatement, destinationID, date)
+	if err != nil {
+		return translate(ctx, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// reserveCapacity takes qty tickets out of the destination's availability
+// for the given date. The inventory row stays locked until tx ends, so
+// concurrent bookings for the same day are serialised. It returns
+// models.ErrDestinationNotFound or models.ErrSoldOut when it cannot.
+func reserveCapacity(ctx context.Context, tx *sql.Tx, destinationID int, bookingDate string, qty int) error {
+	var dailyQuota *int
+
+	err := tx.QueryRowContext(ctx, "SELECT daily_quota FROM destination WHERE id = ?", destinationID).Scan(&dailyQuota)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ErrDestinationNotFound
+	} else if err != nil {
+		return translate(ctx, err)
+	}
+
+	sqlStatement := `INSERT INTO destination_inventory(destination_id, inventory_date, sold) VALUES (?, ?, 0)
+					ON DUPLICATE KEY UPDATE sold = sold`
+
+	if _, err = tx.ExecContext(ctx, sqlStatement, destinationID, bookingDate); err != nil {
+		return translate(ctx, err)
+	}
+
+	var sold int
+
+	sqlStatement = "SELECT sold FROM destination_inventory WHERE destination_id = ? AND inventory_date = ? FOR UPDATE"
+
+	if err = tx.QueryRowContext(ctx, sqlStatement, destinationID, bookingDate).Scan(&sold); err != nil {
+		return translate(ctx, err)
+	}
+
+	quota := dailyQuota
+
+	var overrideQuota int
+
+	sqlStatement = "SELECT quota FROM destination_quota_overrides WHERE destination_id = ? AND quota_date = ?"
+
+	err = tx.QueryRowContext(ctx, sqlStatement, destinationID, bookingDate).Scan(&overrideQuota)
+	if err == nil {
+		quota = &overrideQuota
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return translate(ctx, err)
+	}
+
+	if quota != nil && sold+qty > *quota {
+		return models.ErrSoldOut
+	}
+
+	sqlStatement = "UPDATE destination_inventory SET sold = sold + ? WHERE destination_id = ? AND inventory_date = ?"
+
+	_, err = tx.ExecContext(ctx, sqlStatement, qty, destinationID, bookingDate)
+
+	return translate(ctx, err)
+}
+
+// releaseCapacity gives qty tickets back to the destination's availability
+// for the given date
+func releaseCapacity(ctx context.Context, tx *sql.Tx, destinationID int, bookingDate string, qty int) error {
+	sqlStatement := "UPDATE destination_inventory SET sold = GREATEST(sold - ?, 0) WHERE destination_id = ? AND inventory_date = ?"
+
+	_, err := tx.ExecContext(ctx, sqlStatement, qty, destinationID, bookingDate)
+
+	return translate(ctx, err)
+}
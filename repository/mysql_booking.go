@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/bryansamperura/ticket-booking/db"
+	"github.com/bryansamperura/ticket-booking/models"
+)
+
+type MySQLBookingRepository struct {
+	con *sql.DB
+}
+
+func NewMySQLBookingRepository(con *sql.DB) *MySQLBookingRepository {
+	return &MySQLBookingRepository{con: con}
+}
+
+const bookingSelect = `SELECT 
+						booking.id,
+						booking.customer_id,
+						COALESCE(customers.fullname, ''), 
+						booking.qty, 
+						booking.destination_id,
+						destination.destination_name, 
+						COALESCE(cities.city_name, ''),
+						DATE_FORMAT(booking.booking_date, '%Y-%m-%d'),
+						booking.currency,
+						booking.unit_price,
+						booking.subtotal,
+						booking.discount,
+						booking.tax,
+						booking.total,
+						booking.status,
+						booking.created_at,
+						booking.expires_at,
+						booking.confirmed_at,
+						booking.checked_in_at,
+						booking.cancelled_at,
+						booking.expired_at,
+						booking.refunded_at ` + bookingFrom
+
+// bookingFrom joins the names shown on a booking. Lists count with the same
+// joins so the total matches the rows returned.
+const bookingFrom = `FROM booking 
+					JOIN 
+						destination ON destination.id = booking.destination_id 
+					LEFT JOIN 
+						customers ON customers.id = booking.customer_id
+					LEFT JOIN
+						cities ON cities.id = destination.city_id`
+
+func scanBooking(row rowScanner) (models.Booking, error) {
+	var obj models.Booking
+
+	err := row.Scan(
+		&obj.Id, &obj.CustomerID, &obj.CustomerName, &obj.Qty, &obj.DestinationID, &obj.DestinationName, &obj.CityName, &obj.TanggalBooking,
+		&obj.Currency, &obj.UnitPrice, &obj.Subtotal, &obj.Discount, &obj.Tax, &obj.Total,
+		&obj.Status, &obj.CreatedAt, &obj.ExpiresAt, &obj.ConfirmedAt, &obj.CheckedInAt, &obj.CancelledAt, &obj.ExpiredAt, &obj.RefundedAt,
+	)
+
+	return obj, err
+}
+
+func (r *MySQLBookingRepository) List(ctx context.Context, opts models.ListOptions) (models.Page[models.Booking], error) {
+	return listRows(ctx, r.con, opts, bookingFrom, bookingSelect,
+		func(rows *sql.Rows) (models.Booking, error) { return scanBooking(rows) },
+		func(booking models.Booking) int { return booking.Id })
+}
+
+func (r *MySQLBookingRepository) FindByID(ctx context.Context, id int) (models.Booking, error) {
+	booking, err := scanBooking(r.con.QueryRowContext(ctx, bookingSelect+" WHERE booking.id = ?", id))
+
+	return booking, translate(ctx, err)
+}
+
+func (r *MySQLBookingRepository) ListByCustomer(ctx context.Context, customerID int) ([]models.Booking, error) {
+	bookings := []models.Booking{}
+
+	rows, err := r.con.QueryContext(ctx, bookingSelect+" WHERE booking.customer_id = ? ORDER BY booking.id", customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		booking, err := scanBooking(rows)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, booking)
+	}
+
+	return bookings, rows.Err()
+}
+
+func (r *MySQLBookingRepository) Create(ctx context.Context, request models.BookingRequest, policy models.BookingPolicy) (int, error) {
+	var id int
+
+	// The tickets are taken out of the day's availability in the same
+	// transaction that records the booking
+	err := db.TransactionContext(ctx, r.con, func(tx *sql.Tx) error {
+		if err := reserveCapacity(ctx, tx, request.DestinationID, request.TanggalBooking, request.Qty); err != nil {
+			return err
+		}
+
+		var unitPrice int
+
+		err := tx.QueryRowContext(ctx, "SELECT price FROM destination WHERE id = ?", request.DestinationID).Scan(&unitPrice)
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrDestinationNotFound
+		} else if err != nil {
+			return err
+		}
+
+		// The price is copied onto the booking so later price changes of
+		// the destination never alter what was charged
+		price := models.CalculatePrice(unitPrice, request.Qty, 0, policy.TaxRateBps)
+
+		sqlStatement := `INSERT INTO booking(customer_id, qty, destination_id, booking_date, status, expires_at, currency, unit_price, subtotal, discount, tax, total)
+						VALUES (?, ?, ?, ?, ?, DATE_ADD(NOW(), INTERVAL ? MINUTE), ?, ?, ?, ?, ?, ?)`
+
+		id, err = insertID(tx.ExecContext(ctx, sqlStatement, request.CustomerID, price.Qty, request.DestinationID, request.TanggalBooking,
+			models.BookingPending, policy.HoldMinutes, models.Currency, price.UnitPrice, price.Subtotal, price.Discount, price.Tax, price.Total))
+
+		return err
+	})
+
+	return id, err
+}
+
+func (r *MySQLBookingRepository) Transition(ctx context.Context, id int, to string) error {
+	return db.TransactionContext(ctx, r.con, func(tx *sql.Tx) error {
+		return transitionBooking(ctx, tx, id, to)
+	})
+}
+
+func (r *MySQLBookingRepository) Expire(ctx context.Context, limit int) (int, error) {
+	sqlStatement := "SELECT id FROM booking WHERE status = ? AND expires_at <= NOW() ORDER BY expires_at LIMIT ?"
+
+	rows, err := r.con.QueryContext(ctx, sqlStatement, models.BookingPending, limit)
+	if err != nil {
+		return 0, translate(ctx, err)
+	}
+
+	var ids []int
+
+	for rows.Next() {
+		var id int
+
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	rows.Close()
+
+	expired := 0
+
+	for _, id := range ids {
+		// The booking may have been paid or cancelled since it was selected,
+		// Transition re-checks its status under a row lock
+		err := r.Transition(ctx, id, models.BookingExpired)
+		if errors.Is(err, models.ErrInvalidTransition) {
+			continue
+		}
+
+		if err != nil {
+			return expired, err
+		}
+
+		expired++
+	}
+
+	return expired, nil
+}
+
+// bookingTimestampColumns is the column recording when a booking entered
+// each state
+var bookingTimestampColumns = map[string]string{
+	models.BookingConfirmed: "confirmed_at",
+	models.BookingCheckedIn: "checked_in_at",
+	models.BookingCancelled: "cancelled_at",
+	models.BookingExpired:   "expired_at",
+	models.BookingRefunded:  "refunded_at",
+}
+
+// transitionBooking moves a booking to a new state inside tx and records
+// when it did so. Tickets are given back to the destination's availability
+// when the booking stops holding them. It returns ErrNotFound or
+// models.ErrInvalidTransition when the move is not possible.
+func transitionBooking(ctx context.Context, tx *sql.Tx, id int, to string) error {
+	var from, bookingDate string
+	var destinationID, qty int
+
+	sqlStatement := "SELECT status, destination_id, DATE_FORMAT(booking_date, '%Y-%m-%d'), qty FROM booking WHERE id = ? FOR UPDATE"
+
+	err := tx.QueryRowContext(ctx, sqlStatement, id).Scan(&from, &destinationID, &bookingDate, &qty)
+	if err != nil {
+		return translate(ctx, err)
+	}
+
+	if !models.CanTransitionBooking(from, to) {
+		return fmt.Errorf("%w from %s to %s", models.ErrInvalidTransition, from, to)
+	}
+
+	sqlStatement = "UPDATE booking SET status = ?, " + bookingTimestampColumns[to] + " = NOW() WHERE id = ?"
+
+	if _, err = tx.ExecContext(ctx, sqlStatement, to, id); err != nil {
+		return translate(ctx, err)
+	}
+
+	if models.HoldsCapacity(from) && !models.HoldsCapacity(to) {
+		return releaseCapacity(ctx, tx, destinationID, bookingDate, qty)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bryansamperura/ticket-booking/db"
+	"github.com/bryansamperura/ticket-booking/models"
+)
+
+type MySQLCheckInRepository struct {
+	con *sql.DB
+}
+
+func (r *MySQLCheckInRepository) CheckIn(ctx context.Context, bookingID int, seat int, destinationID int, staffID int) (models.CheckIn, error) {
+	var checkIn models.CheckIn
+
+	err := db.TransactionContext(ctx, r.con, func(tx *sql.Tx) error {
+		var booking models.Booking
+
+		sqlStatement := "SELECT status, destination_id, DATE_FORMAT(booking_date, '%Y-%m-%d'), qty FROM booking WHERE id = ? FOR UPDATE"
+
+		err := tx.QueryRowContext(ctx, sqlStatement, bookingID).Scan(&booking.Status, &booking.DestinationID, &booking.TanggalBooking, &booking.Qty)
+		if err != nil {
+			return translate(ctx, err)
+		}
+
+		if err = models.CanCheckIn(booking, seat, destinationID); err != nil {
+			return err
+		}
+
+		sqlStatement = "INSERT INTO ticket_checkins(booking_id, seat_no, checked_in_by) VALUES (?, ?, ?)"
+
+		_, err = tx.ExecContext(ctx, sqlStatement, bookingID, seat, staffID)
+		if isDuplicateEntry(err) {
+			return models.ErrTicketAlreadyUsed
+		} else if err != nil {
+			return translate(ctx, err)
+		}
+
+		var used int
+
+		err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM ticket_checkins WHERE booking_id = ?", bookingID).Scan(&used)
+		if err != nil {
+			return translate(ctx, err)
+		}
+
+		if used == booking.Qty && booking.Status == models.BookingConfirmed {
+			if err = transitionBooking(ctx, tx, bookingID, models.BookingCheckedIn); err != nil {
+				return err
+			}
+
+			booking.Status = models.BookingCheckedIn
+		}
+
+		checkIn = models.CheckIn{
+			BookingID:      bookingID,
+			Seat:           seat,
+			Qty:            booking.Qty,
+			SeatsCheckedIn: used,
+			BookingDate:    booking.TanggalBooking,
+			Status:         booking.Status,
+		}
+
+		return nil
+	})
+
+	return checkIn, err
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bryansamperura/ticket-booking/models"
+)
+
+type MySQLCityRepository struct {
+	con *sql.DB
+}
+
+func NewMySQLCityRepository(con *sql.DB) *MySQLCityRepository {
+	return &MySQLCityRepository{con: con}
+}
+
+func scanCity(row rowScanner) (models.City, error) {
+	var city models.City
+
+	err := row.Scan(&city.Id, &city.CityName)
+
+	return city, err
+}
+
+func (r *MySQLCityRepository) List(ctx context.Context, opts models.ListOptions) (models.Page[models.City], error) {
+	return listRows(ctx, r.con, opts, "FROM cities", "SELECT cities.id, cities.city_name FROM cities",
+		func(rows *sql.Rows) (models.City, error) { return scanCity(rows) },
+		func(city models.City) int { return city.Id })
+}
+
+func (r *MySQLCityRepository) FindByID(ctx context.Context, id int) (models.City, error) {
+	city, err := scanCity(r.con.QueryRowContext(ctx, "SELECT id, city_name FROM cities WHERE id = ?", id))
+
+	return city, translate(ctx, err)
+}
+
+func (r *MySQLCityRepository) Create(ctx context.Context, name string) (int, error) {
+	return insertID(r.con.ExecContext(ctx, "INSERT INTO cities(city_name) VALUES (?)", name))
+}
+
+func (r *MySQLCityRepository) Update(ctx context.Context, id int, name string) error {
+	return execAffecting(ctx, r.con, "cities", id, "UPDATE cities SET city_name = ? WHERE id = ?", name, id)
+}
+
+func (r *MySQLCityRepository) Delete(ctx context.Context, id int) error {
+	return execAffecting(ctx, r.con, "cities", id, "DELETE FROM cities WHERE id = ?", id)
+}
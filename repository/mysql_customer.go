@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bryansamperura/ticket-booking/db"
+	"github.com/bryansamperura/ticket-booking/models"
+)
+
+type MySQLCustomerRepository struct {
+	con *sql.DB
+}
+
+func NewMySQLCustomerRepository(con *sql.DB) *MySQLCustomerRepository {
+	return &MySQLCustomerRepository{con: con}
+}
+
+func scanCustomer(row rowScanner) (models.Customer, error) {
+	var customer models.Customer
+
+	err := row.Scan(&customer.Id, &customer.FullName, &customer.Email, &customer.Phone)
+
+	return customer, err
+}
+
+func (r *MySQLCustomerRepository) List(ctx context.Context, opts models.ListOptions) (models.Page[models.Customer], error) {
+	return listRows(ctx, r.con, opts, "FROM customers", "SELECT customers.id, customers.fullname, customers.email, customers.phone FROM customers",
+		func(rows *sql.Rows) (models.Customer, error) { return scanCustomer(rows) },
+		func(customer models.Customer) int { return customer.Id })
+}
+
+func (r *MySQLCustomerRepository) FindByID(ctx context.Context, id int) (models.Customer, error) {
+	customer, err := scanCustomer(r.con.QueryRowContext(ctx, "SELECT id, fullname, email, phone FROM customers WHERE id = ?", id))
+
+	return customer, translate(ctx, err)
+}
+
+func (r *MySQLCustomerRepository) Create(ctx context.Context, customer models.CustomerRequest) (int, error) {
+	return insertID(r.con.ExecContext(ctx, "INSERT INTO customers(fullname, email, phone) VALUES (?, ?, ?)",
+		customer.FullName, customer.Email, customer.Phone))
+}
+
+func (r *MySQLCustomerRepository) Update(ctx context.Context, id int, customer models.CustomerRequest) error {
+	return db.TransactionContext(ctx, r.con, func(tx *sql.Tx) error {
+		return updateAccount(ctx, tx, "customers", id, customer.FullName, customer.Email, customer.Phone)
+	})
+}
+
+func (r *MySQLCustomerRepository) Delete(ctx context.Context, id int) error {
+	return deleteAccount(ctx, r.con, "customers", id)
+}
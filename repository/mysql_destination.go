@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bryansamperura/ticket-booking/models"
+)
+
+type MySQLDestinationRepository struct {
+	con *sql.DB
+}
+
+func NewMySQLDestinationRepository(con *sql.DB) *MySQLDestinationRepository {
+	return &MySQLDestinationRepository{con: con}
+}
+
+const destinationSelect = `SELECT destination.id, destination.destination_name, destination.image, destination.city_id,
+							destination.description, destination.price, destination.daily_quota
+						FROM destination`
+
+func scanDestination(row rowScanner) (models.Destination, error) {
+	var destination models.Destination
+
+	err := row.Scan(&destination.Id, &destination.DestinationName, &destination.Image, &destination.City,
+		&destination.Description, &destination.Price, &destination.DailyQuota)
+
+	return destination, err
+}
+
+func (r *MySQLDestinationRepository) List(ctx context.Context, opts models.ListOptions) (models.Page[models.Destination], error) {
+	return listRows(ctx, r.con, opts, "FROM destination", destinationSelect,
+		func(rows *sql.Rows) (models.Destination, error) { return scanDestination(rows) },
+		func(destination models.Destination) int { return destination.Id })
+}
+
+func (r *MySQLDestinationRepository) FindByID(ctx context.Context, id int) (models.Destination, error) {
+	destination, err := scanDestination(r.con.QueryRowContext(ctx, destinationSelect+" WHERE destination.id = ?", id))
+
+	return destination, translate(ctx, err)
+}
+
+func (r *MySQLDestinationRepository) Create(ctx context.Context, destination models.Destination) (int, error) {
+	return insertID(r.con.ExecContext(ctx, "INSERT INTO destination(destination_name, image, city_id, description, price, daily_quota) VALUES (?, ?, ?, ?, ?, ?)",
+		destination.DestinationName, destination.Image, destination.City, destination.Description, destination.Price, destination.DailyQuota))
+}
+
+func (r *MySQLDestinationRepository) Update(ctx context.Context, destination models.Destination) error {
+	return execAffecting(ctx, r.con, "destination", destination.Id,
+		"UPDATE destination SET destination_name = ?, image = ?, city_id = ?, description = ?, price = ? WHERE id = ?",
+		destination.DestinationName, destination.Image, destination.City, destination.Description, destination.Price, destination.Id)
+}
+
+func (r *MySQLDestinationRepository) Delete(ctx context.Context, id int) error {
+	return execAffecting(ctx, r.con, "destination", id, "DELETE FROM destination WHERE id = ?", id)
+}
+
+func (r *MySQLDestinationRepository) SearchDocuments(ctx context.Context) ([]models.DestinationDocument, error) {
+	var documents []models.DestinationDocument
+
+	sqlStatement := `SELECT destination.id, destination.destination_name, destination.image, destination.city_id,
+						COALESCE(cities.city_name, ''), destination.description, destination.price
+					FROM destination
+					LEFT JOIN cities ON cities.id = destination.city_id`
+
+	rows, err := r.con.QueryContext(ctx, sqlStatement)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var obj models.DestinationDocument
+
+		err = rows.Scan(&obj.Id, &obj.DestinationName, &obj.Image, &obj.CityID, &obj.CityName, &obj.Description, &obj.Price)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, obj)
+	}
+
+	return documents, rows.Err()
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/db"
+	"github.com/bryansamperura/ticket-booking/models"
+)
+
+type MySQLLoginRepository struct {
+	con *sql.DB
+}
+
+func (r *MySQLLoginRepository) LockedUntil(ctx context.Context, email string) (time.Time, error) {
+	var lockedUntil sql.NullTime
+
+	err := r.con.QueryRowContext(ctx, "SELECT locked_until FROM login_throttles WHERE email = ?", email).Scan(&lockedUntil)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+
+	return lockedUntil.Time, translate(ctx, err)
+}
+
+func (r *MySQLLoginRepository) RecordFailure(ctx context.Context, failure models.LoginFailure, policy models.LockoutPolicy) (time.Time, error) {
+	var lockedUntil sql.NullTime
+
+	now := time.Now()
+
+	err := db.TransactionContext(ctx, r.con, func(tx *sql.Tx) error {
+		if err := audit(ctx, tx, failure); err != nil {
+			return err
+		}
+
+		// The count starts over when the previous failure is older than the
+		// window. MySQL applies the assignments in order, so failures is
+		// computed from the previous last_failure_at.
+		_, err := tx.ExecContext(ctx, `INSERT INTO login_throttles (email, failures, last_failure_at) VALUES (?, 1, ?)
+			ON DUPLICATE KEY UPDATE failures = IF(last_failure_at < ?, 1, failures + 1), last_failure_at = VALUES(last_failure_at)`,
+			failure.Email, now, now.Add(-policy.Window))
+		if err != nil {
+			return err
+		}
+
+		var failures int
+
+		err = tx.QueryRowContext(ctx, "SELECT failures, locked_until FROM login_throttles WHERE email = ?", failure.Email).Scan(&failures, &lockedUntil)
+		if err != nil {
+			return err
+		}
+
+		if lock := policy.LockFor(failures); lock > 0 {
+			lockedUntil = sql.NullTime{Time: now.Add(lock), Valid: true}
+
+			_, err = tx.ExecContext(ctx, "UPDATE login_throttles SET locked_until = ? WHERE email = ?", lockedUntil.Time, failure.Email)
+		}
+
+		return err
+	})
+
+	return lockedUntil.Time, translate(ctx, err)
+}
+
+func (r *MySQLLoginRepository) Audit(ctx context.Context, failure models.LoginFailure) error {
+	return translate(ctx, audit(ctx, r.con, failure))
+}
+
+func (r *MySQLLoginRepository) Reset(ctx context.Context, email string) error {
+	_, err := r.con.ExecContext(ctx, "DELETE FROM login_throttles WHERE email = ?", email)
+
+	return translate(ctx, err)
+}
+
+func audit(ctx context.Context, con dbtx, failure models.LoginFailure) error {
+	var userID sql.NullInt64
+	if failure.UserID > 0 {
+		userID = sql.NullInt64{Int64: int64(failure.UserID), Valid: true}
+	}
+
+	userAgent := failure.UserAgent
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	_, err := con.ExecContext(ctx, "INSERT INTO login_failures (email, user_id, ip, user_agent, reason) VALUES (?, ?, ?, ?, ?)",
+		failure.Email, userID, failure.IP, userAgent, failure.Reason)
+
+	return err
+}
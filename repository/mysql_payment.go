@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/db"
+	"github.com/bryansamperura/ticket-booking/models"
+	"github.com/bryansamperura/ticket-booking/payments"
+)
+
+type MySQLPaymentRepository struct {
+	con *sql.DB
+}
+
+const paymentSelect = `SELECT id, booking_id, provider, order_id, reference, method, amount, status,
+						bank, va_number, qr_string, redirect_url, expires_at, paid_at, created_at
+					FROM payments`
+
+func scanPayment(row rowScanner) (models.Payment, error) {
+	var obj models.Payment
+
+	err := row.Scan(
+		&obj.Id, &obj.BookingID, &obj.Provider, &obj.OrderID, &obj.Reference, &obj.Method, &obj.Amount, &obj.Status,
+		&obj.Bank, &obj.VANumber, &obj.QRString, &obj.RedirectURL, &obj.ExpiresAt, &obj.PaidAt, &obj.CreatedAt,
+	)
+
+	return obj, err
+}
+
+func (r *MySQLPaymentRepository) Create(ctx context.Context, payment models.Payment, expiresAt time.Time) (models.Payment, error) {
+	var expires interface{}
+	if !expiresAt.IsZero() {
+		expires = expiresAt.Unix()
+	}
+
+	sqlStatement := `INSERT INTO payments(booking_id, provider, order_id, reference, method, amount, status, bank, va_number, qr_string, redirect_url, expires_at)
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, FROM_UNIXTIME(?))`
+
+	_, err := r.con.ExecContext(ctx, sqlStatement, payment.BookingID, payment.Provider, payment.OrderID, payment.Reference, payment.Method, payment.Amount,
+		payment.Status, payment.Bank, payment.VANumber, payment.QRString, payment.RedirectURL, expires)
+	if err != nil {
+		return models.Payment{}, translate(ctx, err)
+	}
+
+	return r.FindByOrderID(ctx, payment.OrderID)
+}
+
+func (r *MySQLPaymentRepository) FindByOrderID(ctx context.Context, orderID string) (models.Payment, error) {
+	payment, err := scanPayment(r.con.QueryRowContext(ctx, paymentSelect+" WHERE order_id = ?", orderID))
+
+	return payment, translate(ctx, err)
+}
+
+func (r *MySQLPaymentRepository) FindLatestByBooking(ctx context.Context, bookingID int) (models.Payment, error) {
+	payment, err := scanPayment(r.con.QueryRowContext(ctx, paymentSelect+" WHERE booking_id = ? ORDER BY id DESC LIMIT 1", bookingID))
+
+	return payment, translate(ctx, err)
+}
+
+func (r *MySQLPaymentRepository) UpdateStatus(ctx context.Context, orderID string, from string, to string) (bool, error) {
+	return updatePaymentStatus(ctx, r.con, orderID, from, to)
+}
+
+func (r *MySQLPaymentRepository) MarkPaid(ctx context.Context, orderID string) error {
+	return db.TransactionContext(ctx, r.con, func(tx *sql.Tx) error {
+		changed, err := updatePaymentStatus(ctx, tx, orderID, payments.StatusPending, payments.StatusPaid)
+		if err != nil || !changed {
+			return err
+		}
+
+		bookingID, err := paymentBookingID(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+
+		// A booking that expired or was cancelled while the visitor was
+		// paying stays as it is, the payment is kept as paid so it can be
+		// refunded
+		err = transitionBooking(ctx, tx, bookingID, models.BookingConfirmed)
+		if errors.Is(err, models.ErrInvalidTransition) {
+			return nil
+		}
+
+		return err
+	})
+}
+
+func (r *MySQLPaymentRepository) MarkRefunded(ctx context.Context, orderID string) error {
+	return db.TransactionContext(ctx, r.con, func(tx *sql.Tx) error {
+		changed, err := updatePaymentStatus(ctx, tx, orderID, payments.StatusPaid, payments.StatusRefunded)
+		if err != nil || !changed {
+			return err
+		}
+
+		bookingID, err := paymentBookingID(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+
+		err = transitionBooking(ctx, tx, bookingID, models.BookingRefunded)
+		if errors.Is(err, models.ErrInvalidTransition) {
+			return nil
+		}
+
+		return err
+	})
+}
+
+func (r *MySQLPaymentRepository) ClaimRefund(ctx context.Context, bookingID int) (models.Payment, error) {
+	var payment models.Payment
+
+	err := db.TransactionContext(ctx, r.con, func(tx *sql.Tx) error {
+		var status string
+
+		err := tx.QueryRowContext(ctx, "SELECT status FROM booking WHERE id = ? FOR UPDATE", bookingID).Scan(&status)
+		if err != nil {
+			return translate(ctx, err)
+		}
+
+		if !models.CanTransitionBooking(status, models.BookingRefunded) {
+			return fmt.Errorf("%w from %s to %s", models.ErrInvalidTransition, status, models.BookingRefunded)
+		}
+
+		payment, err = scanPayment(tx.QueryRowContext(ctx, paymentSelect+" WHERE booking_id = ? ORDER BY id DESC LIMIT 1 FOR UPDATE", bookingID))
+		if err != nil {
+			return translate(ctx, err)
+		}
+
+		if payment.Status != payments.StatusPaid {
+			return ErrNotFound
+		}
+
+		if _, err = updatePaymentStatus(ctx, tx, payment.OrderID, payments.StatusPaid, payments.StatusRefunding); err != nil {
+			return err
+		}
+
+		payment.Status = payments.StatusRefunding
+
+		return nil
+	})
+
+	return payment, err
+}
+
+func (r *MySQLPaymentRepository) CompleteRefund(ctx context.Context, orderID string, bookingID int) error {
+	return db.TransactionContext(ctx, r.con, func(tx *sql.Tx) error {
+		changed, err := updatePaymentStatus(ctx, tx, orderID, payments.StatusRefunding, payments.StatusRefunded)
+		if err != nil {
+			return err
+		}
+
+		if !changed {
+			return ErrNotFound
+		}
+
+		return transitionBooking(ctx, tx, bookingID, models.BookingRefunded)
+	})
+}
+
+func (r *MySQLPaymentRepository) ReleaseRefund(ctx context.Context, orderID string) error {
+	changed, err := updatePaymentStatus(ctx, r.con, orderID, payments.StatusRefunding, payments.StatusPaid)
+	if err != nil {
+		return err
+	}
+
+	if !changed {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// paymentBookingID returns the booking a payment was made for
+func paymentBookingID(ctx context.Context, con dbtx, orderID string) (int, error) {
+	var bookingID int
+
+	err := con.QueryRowContext(ctx, "SELECT booking_id FROM payments WHERE order_id = ?", orderID).Scan(&bookingID)
+
+	return bookingID, translate(ctx, err)
+}
+
+// updatePaymentStatus moves a payment from one status to another, reporting
+// whether it was still in the from status
+func updatePaymentStatus(ctx context.Context, con dbtx, orderID string, from string, to string) (bool, error) {
+	sqlStatement := "UPDATE payments SET status = ? WHERE order_id = ? AND status = ?"
+
+	if from == payments.StatusPending && to == payments.StatusPaid {
+		sqlStatement = "UPDATE payments SET status = ?, paid_at = NOW() WHERE order_id = ? AND status = ?"
+	}
+
+	result, err := con.ExecContext(ctx, sqlStatement, to, orderID, from)
+	if err != nil {
+		return false, translate(ctx, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/models"
+)
+
+type MySQLTokenRepository struct {
+	con *sql.DB
+}
+
+func (r *MySQLTokenRepository) StoreRefresh(ctx context.Context, userID int, tokenHash string, ttl time.Duration) error {
+	sqlStatement := "INSERT INTO refresh_tokens(user_id, token_hash, expires_at) VALUES (?, ?, DATE_ADD(NOW(), INTERVAL ? SECOND))"
+
+	_, err := r.con.ExecContext(ctx, sqlStatement, userID, tokenHash, int64(ttl.Seconds()))
+
+	return translate(ctx, err)
+}
+
+func (r *MySQLTokenRepository) FindRefresh(ctx context.Context, tokenHash string) (models.RefreshToken, error) {
+	var token models.RefreshToken
+
+	sqlStatement := "SELECT id, user_id FROM refresh_tokens WHERE token_hash = ? AND revoked_at IS NULL AND expires_at > NOW()"
+
+	err := r.con.QueryRowContext(ctx, sqlStatement, tokenHash).Scan(&token.Id, &token.UserID)
+
+	return token, translate(ctx, err)
+}
+
+func (r *MySQLTokenRepository) UseRefresh(ctx context.Context, id int) (bool, error) {
+	sqlStatement := "UPDATE refresh_tokens SET revoked_at = NOW() WHERE id = ? AND revoked_at IS NULL"
+
+	result, err := r.con.ExecContext(ctx, sqlStatement, id)
+	if err != nil {
+		return false, translate(ctx, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+func (r *MySQLTokenRepository) RevokeRefresh(ctx context.Context, userID int, tokenHash string) error {
+	sqlStatement := "UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = ? AND token_hash = ? AND revoked_at IS NULL"
+
+	_, err := r.con.ExecContext(ctx, sqlStatement, userID, tokenHash)
+
+	return translate(ctx, err)
+}
+
+func (r *MySQLTokenRepository) Revoke(ctx context.Context, jti string, userID int, expiresAt time.Time) error {
+	sqlStatement := "INSERT IGNORE INTO revoked_tokens(jti, user_id, expires_at) VALUES (?, ?, FROM_UNIXTIME(?))"
+
+	_, err := r.con.ExecContext(ctx, sqlStatement, jti, userID, expiresAt.Unix())
+
+	return translate(ctx, err)
+}
+
+func (r *MySQLTokenRepository) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	var found int
+
+	err := r.con.QueryRowContext(ctx, "SELECT 1 FROM revoked_tokens WHERE jti = ?", jti).Scan(&found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, translate(ctx, err)
+	}
+
+	return true, nil
+}
+
+func (r *MySQLTokenRepository) RevokeAll(ctx context.Context, userID int) error {
+	sqlStatement := "INSERT INTO revoked_users(user_id, generation) VALUES (?, 1) ON DUPLICATE KEY UPDATE generation = generation + 1"
+
+	if _, err := r.con.ExecContext(ctx, sqlStatement, userID); err != nil {
+		return translate(ctx, err)
+	}
+
+	sqlStatement = "UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = ? AND revoked_at IS NULL"
+
+	_, err := r.con.ExecContext(ctx, sqlStatement, userID)
+
+	return translate(ctx, err)
+}
+
+func (r *MySQLTokenRepository) Generation(ctx context.Context, userID int) (int, error) {
+	var generation int
+
+	err := r.con.QueryRowContext(ctx, "SELECT generation FROM revoked_users WHERE user_id = ?", userID).Scan(&generation)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	} else if err != nil {
+		return 0, translate(ctx, err)
+	}
+
+	return generation, nil
+}
@@ -0,0 +1,288 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/db"
+	"github.com/bryansamperura/ticket-booking/models"
+)
+
+type MySQLUserRepository struct {
+	con *sql.DB
+}
+
+func NewMySQLUserRepository(con *sql.DB) *MySQLUserRepository {
+	return &MySQLUserRepository{con: con}
+}
+
+const userSelect = "SELECT id, email, password, role, account_id, email_verified_at FROM users"
+
+func scanUser(row rowScanner) (models.User, error) {
+	var user models.User
+
+	err := row.Scan(&user.Id, &user.Email, &user.Password, &user.Role, &user.AccountID, &user.EmailVerifiedAt)
+
+	return user, err
+}
+
+func (r *MySQLUserRepository) FindByID(ctx context.Context, id int) (models.User, error) {
+	user, err := scanUser(r.con.QueryRowContext(ctx, userSelect+" WHERE id = ?", id))
+
+	return user, translate(ctx, err)
+}
+
+func (r *MySQLUserRepository) FindByEmail(ctx context.Context, email string) (models.User, error) {
+	user, err := scanUser(r.con.QueryRowContext(ctx, userSelect+" WHERE email = ?", email))
+
+	return user, translate(ctx, err)
+}
+
+func (r *MySQLUserRepository) CreateCustomer(ctx context.Context, customer models.CustomerRequest, passwordHash string) (models.User, error) {
+	return r.createAccount(ctx, "customers", customer.FullName, customer.Email, customer.Phone, passwordHash, "customer")
+}
+
+func (r *MySQLUserRepository) CreateAdmin(ctx context.Context, admin models.AdminRequest, passwordHash string, role string) (models.User, error) {
+	return r.createAccount(ctx, "admin", admin.FullName, admin.Email, admin.Phone, passwordHash, role)
+}
+
+// createAccount inserts the profile into table and its login account in
+// one transaction
+func (r *MySQLUserRepository) createAccount(ctx context.Context, table string, fullname string, email string, phone string, passwordHash string, role string) (models.User, error) {
+	user := models.User{
+		Email:    email,
+		Password: passwordHash,
+		Role:     role,
+	}
+
+	err := db.TransactionContext(ctx, r.con, func(tx *sql.Tx) error {
+		accountID, err := insertID(tx.ExecContext(ctx, "INSERT INTO "+table+"(fullname, email, phone) VALUES (?, ?, ?)", fullname, email, phone))
+		if err != nil {
+			return err
+		}
+
+		user.AccountID = strconv.Itoa(accountID)
+
+		user.Id, err = insertID(tx.ExecContext(ctx, "INSERT INTO users (email, password, role, account_id) VALUES (?, ?, ?, ?)",
+			email, passwordHash, role, accountID))
+
+		return err
+	})
+
+	return user, err
+}
+
+func (r *MySQLUserRepository) MarkEmailVerified(ctx context.Context, id int) error {
+	return execAffecting(ctx, r.con, "users", id, "UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW()) WHERE id = ?", id)
+}
+
+func (r *MySQLUserRepository) IssueToken(ctx context.Context, id int, purpose string, tokenHash string, ttl time.Duration) error {
+	err := db.TransactionContext(ctx, r.con, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "UPDATE account_tokens SET used_at = NOW() WHERE user_id = ? AND purpose = ? AND used_at IS NULL", id, purpose)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, "INSERT INTO account_tokens (user_id, purpose, token_hash, expires_at) VALUES (?, ?, ?, DATE_ADD(NOW(), INTERVAL ? SECOND))",
+			id, purpose, tokenHash, int64(ttl.Seconds()))
+
+		return err
+	})
+
+	return translate(ctx, err)
+}
+
+func (r *MySQLUserRepository) VerifyEmail(ctx context.Context, tokenHash string) (models.User, error) {
+	return r.useToken(ctx, models.TokenVerifyEmail, tokenHash, func(tx *sql.Tx, userID int) error {
+		_, err := tx.ExecContext(ctx, "UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW()) WHERE id = ?", userID)
+
+		return err
+	})
+}
+
+func (r *MySQLUserRepository) ResetPassword(ctx context.Context, tokenHash string, passwordHash string) (models.User, error) {
+	return r.useToken(ctx, models.TokenResetPassword, tokenHash, func(tx *sql.Tx, userID int) error {
+		_, err := tx.ExecContext(ctx, "UPDATE users SET password = ?, email_verified_at = COALESCE(email_verified_at, NOW()) WHERE id = ?", passwordHash, userID)
+
+		return err
+	})
+}
+
+// useToken marks a live token as used and applies it to its account with
+// apply, all in one transaction so a token cannot be used twice
+func (r *MySQLUserRepository) useToken(ctx context.Context, purpose string, tokenHash string, apply func(tx *sql.Tx, userID int) error) (models.User, error) {
+	var user models.User
+
+	err := db.TransactionContext(ctx, r.con, func(tx *sql.Tx) error {
+		var tokenID, userID int
+
+		err := tx.QueryRowContext(ctx, `SELECT id, user_id FROM account_tokens
+			WHERE token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > NOW() FOR UPDATE`, tokenHash, purpose).Scan(&tokenID, &userID)
+		if err != nil {
+			return err
+		}
+
+		if _, err = tx.ExecContext(ctx, "UPDATE account_tokens SET used_at = NOW() WHERE id = ?", tokenID); err != nil {
+			return err
+		}
+
+		if err = apply(tx, userID); err != nil {
+			return err
+		}
+
+		user, err = scanUser(tx.QueryRowContext(ctx, userSelect+" WHERE id = ?", userID))
+
+		return err
+	})
+
+	return user, translate(ctx, err)
+}
+
+// accountTable is the table holding the customer or admin record of a login
+// account with the given role
+func accountTable(role string) string {
+	if role == "customer" {
+		return "customers"
+	}
+
+	return "admin"
+}
+
+// accountRoles matches the login accounts whose record is in a table
+var accountRoles = map[string]string{
+	"customers": "role = 'customer'",
+	"admin":     "role <> 'customer'",
+}
+
+// updateAccount changes a customer or admin record and the email of its
+// login account, if it has one. The login account has to verify a changed
+// email again.
+func updateAccount(ctx context.Context, tx *sql.Tx, table string, id int, fullname string, email string, phone string) error {
+	err := execAffecting(ctx, tx, table, id, "UPDATE "+table+" SET fullname = ?, email = ?, phone = ? WHERE id = ?", fullname, email, phone, id)
+	if err != nil {
+		return err
+	}
+
+	// email_verified_at is assigned first, so it compares with the old email
+	_, err = tx.ExecContext(ctx, "UPDATE users SET email_verified_at = IF(email = ?, email_verified_at, NULL), email = ? WHERE account_id = ? AND "+accountRoles[table],
+		email, email, id)
+
+	return translate(ctx, err)
+}
+
+func (r *MySQLUserRepository) FindProfile(ctx context.Context, id int) (models.Profile, error) {
+	user, err := r.FindByID(ctx, id)
+	if err != nil {
+		return models.Profile{}, err
+	}
+
+	profile := models.Profile{Id: user.Id, Role: user.Role, Email: user.Email, EmailVerifiedAt: user.EmailVerifiedAt}
+
+	profile.AccountID, err = strconv.Atoi(user.AccountID)
+	if err != nil {
+		return profile, err
+	}
+
+	err = r.con.QueryRowContext(ctx, "SELECT fullname, phone FROM "+accountTable(user.Role)+" WHERE id = ?", profile.AccountID).Scan(&profile.FullName, &profile.Phone)
+
+	return profile, translate(ctx, err)
+}
+
+func (r *MySQLUserRepository) UpdateProfile(ctx context.Context, id int, profile models.ProfileRequest) error {
+	return db.TransactionContext(ctx, r.con, func(tx *sql.Tx) error {
+		table, accountID, err := lockAccount(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		return updateAccount(ctx, tx, table, accountID, profile.FullName, profile.Email, profile.Phone)
+	})
+}
+
+func (r *MySQLUserRepository) UpdatePassword(ctx context.Context, id int, passwordHash string) error {
+	return execAffecting(ctx, r.con, "users", id, "UPDATE users SET password = ? WHERE id = ?", passwordHash, id)
+}
+
+func (r *MySQLUserRepository) Delete(ctx context.Context, id int) error {
+	return db.TransactionContext(ctx, r.con, func(tx *sql.Tx) error {
+		table, accountID, err := lockAccount(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		sqlStatement := "DELETE FROM admin WHERE id = ?"
+		args := []interface{}{accountID}
+
+		if table == "customers" {
+			sqlStatement = "UPDATE customers SET fullname = ?, email = '', phone = '' WHERE id = ?"
+			args = []interface{}{deletedCustomerName, accountID}
+		}
+
+		if _, err = tx.ExecContext(ctx, sqlStatement, args...); err != nil {
+			return translate(ctx, err)
+		}
+
+		return deleteLogin(ctx, tx, id)
+	})
+}
+
+// deleteAccount deletes a customer or admin record by id together with the
+// login account using it, if any
+func deleteAccount(ctx context.Context, con *sql.DB, table string, id int) error {
+	return db.TransactionContext(ctx, con, func(tx *sql.Tx) error {
+		var userID int
+
+		err := tx.QueryRowContext(ctx, "SELECT id FROM users WHERE account_id = ? AND "+accountRoles[table]+" FOR UPDATE", id).Scan(&userID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return translate(ctx, err)
+		}
+
+		if err = execAffecting(ctx, tx, table, id, "DELETE FROM "+table+" WHERE id = ?", id); err != nil {
+			return err
+		}
+
+		if userID == 0 {
+			return nil
+		}
+
+		return deleteLogin(ctx, tx, userID)
+	})
+}
+
+// deleteLogin removes a login account with its account and refresh tokens.
+// It also moves the account on to the next token generation, so the access
+// tokens it was issued are refused before they expire.
+func deleteLogin(ctx context.Context, tx *sql.Tx, id int) error {
+	statements := []string{
+		"DELETE FROM account_tokens WHERE user_id = ?",
+		"DELETE FROM refresh_tokens WHERE user_id = ?",
+		"INSERT INTO revoked_users(user_id, generation) VALUES (?, 1) ON DUPLICATE KEY UPDATE generation = generation + 1",
+		"DELETE FROM users WHERE id = ?",
+	}
+
+	for _, sqlStatement := range statements {
+		if _, err := tx.ExecContext(ctx, sqlStatement, id); err != nil {
+			return translate(ctx, err)
+		}
+	}
+
+	return nil
+}
+
+// deletedCustomerName replaces the name of a customer who deleted their
+// account on the bookings they leave behind
+const deletedCustomerName = "Deleted customer"
+
+// lockAccount locks a login account for the rest of tx and returns the
+// table and id of its customer or admin record
+func lockAccount(ctx context.Context, tx *sql.Tx, id int) (string, int, error) {
+	var role string
+	var accountID int
+
+	err := tx.QueryRowContext(ctx, "SELECT role, account_id FROM users WHERE id = ? FOR UPDATE", id).Scan(&role, &accountID)
+
+	return accountTable(role), accountID, translate(ctx, err)
+}
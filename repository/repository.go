@@ -0,0 +1,249 @@
+// Package repository is the persistence layer of the service. Every
+// aggregate has an interface speaking in the domain types of the models
+// package, a MySQL implementation and an in-memory one for tests.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/apperror"
+	"github.com/bryansamperura/ticket-booking/models"
+)
+
+var (
+	ErrNotFound  = apperror.NotFound("not_found", "Not Found")
+	ErrDuplicate = apperror.Conflict("already_exists", "Already exists")
+)
+
+type CityRepository interface {
+	List(ctx context.Context, opts models.ListOptions) (models.Page[models.City], error)
+	FindByID(ctx context.Context, id int) (models.City, error)
+	Create(ctx context.Context, name string) (int, error)
+	Update(ctx context.Context, id int, name string) error
+	Delete(ctx context.Context, id int) error
+}
+
+type CustomerRepository interface {
+	List(ctx context.Context, opts models.ListOptions) (models.Page[models.Customer], error)
+	FindByID(ctx context.Context, id int) (models.Customer, error)
+	Create(ctx context.Context, customer models.CustomerRequest) (int, error)
+	// Update keeps the email of the customer's login account, if any, the
+	// same
+	Update(ctx context.Context, id int, customer models.CustomerRequest) error
+	// Delete removes the customer together with their login account, if
+	// any, like UserRepository.Delete
+	Delete(ctx context.Context, id int) error
+}
+
+type AdminRepository interface {
+	List(ctx context.Context, opts models.ListOptions) (models.Page[models.Admin], error)
+	FindByID(ctx context.Context, id int) (models.Admin, error)
+	// Update keeps the email of the admin's login account, if any, the same
+	Update(ctx context.Context, id int, admin models.AdminRequest) error
+	// Delete removes the admin together with their login account, like
+	// UserRepository.Delete
+	Delete(ctx context.Context, id int) error
+}
+
+type DestinationRepository interface {
+	List(ctx context.Context, opts models.ListOptions) (models.Page[models.Destination], error)
+	FindByID(ctx context.Context, id int) (models.Destination, error)
+	Create(ctx context.Context, destination models.Destination) (int, error)
+	Update(ctx context.Context, destination models.Destination) error
+	Delete(ctx context.Context, id int) error
+	// SearchDocuments returns every destination with its city name for the
+	// search index
+	SearchDocuments(ctx context.Context) ([]models.DestinationDocument, error)
+}
+
+type UserRepository interface {
+	FindByID(ctx context.Context, id int) (models.User, error)
+	FindByEmail(ctx context.Context, email string) (models.User, error)
+	// CreateCustomer creates a customer and its login account together, or
+	// neither when the email is taken
+	CreateCustomer(ctx context.Context, customer models.CustomerRequest, passwordHash string) (models.User, error)
+	// CreateAdmin creates a back office account with the given role together
+	// with its login account
+	CreateAdmin(ctx context.Context, admin models.AdminRequest, passwordHash string, role string) (models.User, error)
+	// MarkEmailVerified verifies the email of an account without a token,
+	// e.g. for seeded accounts
+	MarkEmailVerified(ctx context.Context, id int) error
+	// IssueToken stores the hash of a single-use token mailed to the owner
+	// of an account. Earlier unused tokens for the same purpose stop working.
+	IssueToken(ctx context.Context, id int, purpose string, tokenHash string, ttl time.Duration) error
+	// VerifyEmail uses up a verification token and marks the email of its
+	// account verified. Unknown, used and expired tokens give ErrNotFound.
+	VerifyEmail(ctx context.Context, tokenHash string) (models.User, error)
+	// ResetPassword uses up a password reset token and replaces the
+	// password of its account, verifying its email as the owner got the
+	// mail. Unknown, used and expired tokens give ErrNotFound.
+	ResetPassword(ctx context.Context, tokenHash string, passwordHash string) (models.User, error)
+	// FindProfile returns a login account with its customer or admin record
+	FindProfile(ctx context.Context, id int) (models.Profile, error)
+	// UpdateProfile changes the customer or admin record of a login account
+	// and its email together. A changed email has to be verified again.
+	UpdateProfile(ctx context.Context, id int, profile models.ProfileRequest) error
+	UpdatePassword(ctx context.Context, id int, passwordHash string) error
+	// Delete removes a login account together with its admin record, its
+	// pending and refresh tokens, and moves it on to the next token
+	// generation so its access tokens are refused. A customer record is
+	// anonymised instead, so the customer's bookings are kept for the books.
+	Delete(ctx context.Context, id int) error
+}
+
+type BookingRepository interface {
+	List(ctx context.Context, opts models.ListOptions) (models.Page[models.Booking], error)
+	FindByID(ctx context.Context, id int) (models.Booking, error)
+	ListByCustomer(ctx context.Context, customerID int) ([]models.Booking, error)
+	// Create takes the tickets out of the day's availability and records a
+	// pending booking priced at the destination's current price. It returns
+	// models.ErrDestinationNotFound or models.ErrSoldOut when it cannot.
+	Create(ctx context.Context, request models.BookingRequest, policy models.BookingPolicy) (int, error)
+	// Transition moves a booking to another status, returning
+	// ErrNotFound or models.ErrInvalidTransition when it cannot
+	Transition(ctx context.Context, id int, to string) error
+	// Expire expires up to limit pending bookings whose hold has run out
+	// and gives their tickets back. It returns how many were expired.
+	Expire(ctx context.Context, limit int) (int, error)
+}
+
+// AvailabilityRepository manages how many tickets destinations sell per day
+type AvailabilityRepository interface {
+	// Find returns the remaining tickets per day between from and to, both
+	// inclusive, or ErrNotFound when the destination does not exist
+	Find(ctx context.Context, destinationID int, from time.Time, to time.Time) ([]models.Availability, error)
+	// UpdateDailyQuota sets the default number of tickets a destination
+	// sells per day. A nil quota removes the limit.
+	UpdateDailyQuota(ctx context.Context, destinationID int, quota *int) error
+	// StoreOverride replaces the daily quota of a destination on one date,
+	// e.g. for holidays. A quota of 0 closes the destination for that day.
+	StoreOverride(ctx context.Context, destinationID int, date string, quota int, note string) error
+	DeleteOverride(ctx context.Context, destinationID int, date string) error
+}
+
+// PaymentRepository records the charges created at the payment gateway
+type PaymentRepository interface {
+	// Create records a charge and returns it as stored
+	Create(ctx context.Context, payment models.Payment, expiresAt time.Time) (models.Payment, error)
+	FindByOrderID(ctx context.Context, orderID string) (models.Payment, error)
+	// FindLatestByBooking returns the most recent payment attempt of a
+	// booking
+	FindLatestByBooking(ctx context.Context, bookingID int) (models.Payment, error)
+	// UpdateStatus moves a payment from one status to another. It reports
+	// whether the payment was still in the from status, which makes
+	// repeated webhook deliveries harmless.
+	UpdateStatus(ctx context.Context, orderID string, from string, to string) (bool, error)
+	// MarkPaid moves a pending payment to paid and confirms its booking. A
+	// booking that expired or was cancelled meanwhile stays as it is, and
+	// its payment can then be refunded.
+	MarkPaid(ctx context.Context, orderID string) error
+	// MarkRefunded moves a paid payment and its booking to refunded
+	// together when the gateway reports a refund made outside this API. A
+	// booking that was already checked in stays as it is.
+	MarkRefunded(ctx context.Context, orderID string) error
+	// ClaimRefund moves the latest payment of a booking from paid to
+	// refunding, so only one request sends its refund to the gateway. It
+	// returns ErrNotFound when the booking has no paid payment and
+	// models.ErrInvalidTransition when the booking cannot be refunded.
+	ClaimRefund(ctx context.Context, bookingID int) (models.Payment, error)
+	// CompleteRefund moves a claimed payment and its booking to refunded
+	// together once the gateway refunded the charge
+	CompleteRefund(ctx context.Context, orderID string, bookingID int) error
+	// ReleaseRefund puts a claimed payment back to paid after the gateway
+	// refused the refund
+	ReleaseRefund(ctx context.Context, orderID string) error
+}
+
+// CheckInRepository lets visitors in at the gate
+type CheckInRepository interface {
+	// CheckIn marks one seat of a booking as used. The booking is locked
+	// and each seat can only be used once, so a ticket scanned twice at the
+	// same time is only let in once. The booking becomes checked_in when all
+	// its seats are used. It returns ErrNotFound for unknown bookings and
+	// the errors of models.CanCheckIn.
+	CheckIn(ctx context.Context, bookingID int, seat int, destinationID int, staffID int) (models.CheckIn, error)
+}
+
+// TokenRepository stores refresh tokens and the revocations of access
+// tokens
+type TokenRepository interface {
+	StoreRefresh(ctx context.Context, userID int, tokenHash string, ttl time.Duration) error
+	// FindRefresh returns a refresh token that is neither expired nor
+	// revoked
+	FindRefresh(ctx context.Context, tokenHash string) (models.RefreshToken, error)
+	// UseRefresh revokes a refresh token. It reports whether this call
+	// revoked it, so a token can only ever be exchanged once.
+	UseRefresh(ctx context.Context, id int) (bool, error)
+	// RevokeRefresh revokes the refresh token of the given user, if it is
+	// still active
+	RevokeRefresh(ctx context.Context, userID int, tokenHash string) error
+	// Revoke records the jti of an access token that must no longer be
+	// accepted. It is only needed until the token would have expired anyway.
+	Revoke(ctx context.Context, jti string, userID int, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	// RevokeAll moves the user on to the next token generation, which
+	// invalidates every access token issued so far, and revokes all of
+	// their refresh tokens
+	RevokeAll(ctx context.Context, userID int) error
+	// Generation returns the token generation of the user, 0 when the user
+	// never logged out everywhere. Access tokens of an older generation are
+	// revoked.
+	Generation(ctx context.Context, userID int) (int, error)
+}
+
+// LoginRepository throttles and audits login attempts. Emails are counted
+// whether an account uses them or not.
+type LoginRepository interface {
+	// LockedUntil returns when the lock on an email ends, the zero time when
+	// it is not locked
+	LockedUntil(ctx context.Context, email string) (time.Time, error)
+	// RecordFailure audits a failed login and counts it against the email,
+	// returning when the lock it causes ends
+	RecordFailure(ctx context.Context, failure models.LoginFailure, policy models.LockoutPolicy) (time.Time, error)
+	// Audit records a failed login without counting it, e.g. an attempt on
+	// an email that is already locked
+	Audit(ctx context.Context, failure models.LoginFailure) error
+	// Reset forgets the failures of an email after a successful login
+	Reset(ctx context.Context, email string) error
+}
+
+// HealthRepository tells whether the storage can serve requests
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+}
+
+// Repositories is everything the HTTP handlers persist through
+type Repositories struct {
+	Cities       CityRepository
+	Customers    CustomerRepository
+	Admins       AdminRepository
+	Destinations DestinationRepository
+	Users        UserRepository
+	Bookings     BookingRepository
+	Availability AvailabilityRepository
+	Payments     PaymentRepository
+	CheckIns     CheckInRepository
+	Tokens       TokenRepository
+	Logins       LoginRepository
+	Health       HealthRepository
+}
+
+// NewMySQL returns the repositories backed by the given MySQL pool
+func NewMySQL(con *sql.DB) Repositories {
+	return Repositories{
+		Cities:       &MySQLCityRepository{con: con},
+		Customers:    &MySQLCustomerRepository{con: con},
+		Admins:       &MySQLAdminRepository{con: con},
+		Destinations: &MySQLDestinationRepository{con: con},
+		Users:        &MySQLUserRepository{con: con},
+		Bookings:     &MySQLBookingRepository{con: con},
+		Availability: &MySQLAvailabilityRepository{con: con},
+		Payments:     &MySQLPaymentRepository{con: con},
+		CheckIns:     &MySQLCheckInRepository{con: con},
+		Tokens:       &MySQLTokenRepository{con: con},
+		Logins:       &MySQLLoginRepository{con: con},
+		Health:       &MySQLHealthRepository{con: con},
+	}
+}
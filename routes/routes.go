@@ -1,69 +1,153 @@
 package routes
 
 import (
+	"fmt"
+	"log/slog"
+	"net"
+
+	"github.com/bryansamperura/ticket-booking/config"
 	"github.com/bryansamperura/ticket-booking/controllers"
 	_ "github.com/bryansamperura/ticket-booking/docs"
 	"github.com/bryansamperura/ticket-booking/middlewares"
+	"github.com/bryansamperura/ticket-booking/validation"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	echoSwagger "github.com/swaggo/echo-swagger"
 )
 
-func Init() *echo.Echo {
-	e := echo.New()
-
-	e.Static("/uploads", "uploads")
+func Init(h *controllers.Handler, logger *slog.Logger) *echo.Echo {
+	conf := config.GetConfig()
 
-	cors := middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"*"},
-		AllowHeaders: []string{"Content-Type", "Authorization"},
-		AllowMethods: []string{"GET", "POST", "PUT", "DELETE"},
-	})
-
-	e.Use(cors)
+	e := echo.New()
+	e.HTTPErrorHandler = controllers.ErrorHandler
+	e.Validator = validation.Default()
+	e.IPExtractor = ipExtractor(conf.Server.TrustedProxies)
+
+	e.Use(middlewares.RequestID)
+	e.Use(middlewares.RequestLogger(logger))
+	e.Use(middleware.RecoverWithConfig(middleware.RecoverConfig{
+		DisableStackAll: true,
+		// Hand the panic to RequestLogger instead of printing it on its own
+		DisableErrorHandler: true,
+		LogErrorFunc: func(c echo.Context, err error, stack []byte) error {
+			return fmt.Errorf("panic: %w\n%s", err, stack)
+		},
+	}))
+
+	e.Static("/uploads", conf.Uploads.Dir)
+
+	// Echo allows every origin when the list is empty, so cross-origin
+	// requests are only enabled once origins are configured
+	if len(conf.CORS.AllowOrigins) > 0 {
+		cors := middleware.CORSWithConfig(middleware.CORSConfig{
+			AllowOrigins:  conf.CORS.AllowOrigins,
+			AllowHeaders:  []string{"Content-Type", "Authorization", middlewares.RequestIDHeader},
+			ExposeHeaders: []string{middlewares.RequestIDHeader},
+			AllowMethods:  []string{"GET", "POST", "PUT", "DELETE"},
+		})
+
+		e.Use(cors)
+	}
 
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
+	e.GET("/healthz", h.Healthz)
+	e.GET("/readyz", h.Readyz)
+
 	e.GET("/", func(c echo.Context) error {
 		return c.JSON(200, map[string]string{"message": "Server Is Successfully Running"})
 	})
 
 	Authorization := middlewares.AuthMiddleware
+	Admin := middlewares.RequireRole(middlewares.RoleAdmin)
+	GateStaff := middlewares.RequireRole(middlewares.RoleAdmin, middlewares.RoleStaff)
+	CustomerOwner := middlewares.RequireOwnerOrRole("id", middlewares.RoleAdmin)
+	BookingOwner := middlewares.RequireOwnerOrRole("customer_id", middlewares.RoleAdmin)
+
+	e.POST("/register", h.Register)
+	e.POST("/login", h.Login, middlewares.RateLimit(conf.Auth.LoginRateLimit, conf.Auth.LoginBurst))
+	e.POST("/verify-email/request", h.RequestEmailVerification, middlewares.RateLimit(conf.Auth.LoginRateLimit, conf.Auth.LoginBurst))
+	e.GET("/verify-email/confirm", h.ConfirmEmail)
+	e.POST("/password/forgot", h.ForgotPassword, middlewares.RateLimit(conf.Auth.LoginRateLimit, conf.Auth.LoginBurst))
+	e.POST("/password/reset", h.ResetPassword, middlewares.RateLimit(conf.Auth.LoginRateLimit, conf.Auth.LoginBurst))
+	e.POST("/token/refresh", h.RefreshToken)
+	e.POST("/logout", h.Logout, Authorization)
+	e.POST("/logout-all", h.LogoutAll, Authorization)
+	e.GET("/account-info", h.GetAccountInfo, Authorization)
+	e.GET("/me", h.GetProfile, Authorization)
+	e.PUT("/me", h.UpdateProfile, Authorization)
+	e.PUT("/me/password", h.ChangePassword, Authorization)
+	e.DELETE("/me", h.DeleteAccount, Authorization)
+
+	e.POST("/test", h.Test, Authorization, Admin)
+
+	e.GET("/cities", h.FetchAllCities, Authorization)
+	e.POST("/city", h.StoreCity, Authorization, Admin)
+	e.GET("/city/:id", h.GetCityById, Authorization)
+	e.PUT("/city/:id", h.UpdateCity, Authorization, Admin)
+	e.DELETE("/city/:id", h.DeleteCity, Authorization, Admin)
+
+	e.GET("/customers", h.FetchAllCustomers, Authorization, Admin)
+	e.POST("/customer", h.StoreCustomer, Authorization, Admin)
+	e.GET("/customer/:id", h.GetCustomerById, Authorization, CustomerOwner)
+	e.PUT("/customer/:id", h.UpdateCustomer, Authorization, Admin)
+	e.DELETE("/customer/:id", h.DeleteCustomer, Authorization, Admin)
+
+	e.GET("/destination", h.FetchAllDestination)
+	e.POST("/destination", h.StoreDestination, Authorization, Admin)
+	e.GET("/destination/search", h.SearchDestination)
+	e.GET("/destination/:id", h.GetDestinationById)
+	e.GET("/destination/:id/availability", h.GetDestinationAvailability)
+	e.PUT("/destination/:id/quota", h.UpdateDailyQuota, Authorization, Admin)
+	e.PUT("/destination/:id/quota/:date", h.StoreQuotaOverride, Authorization, Admin)
+	e.DELETE("/destination/:id/quota/:date", h.DeleteQuotaOverride, Authorization, Admin)
+	e.PUT("/destination/:id", h.UpdateDestination, Authorization, Admin)
+	e.DELETE("/destination/:id", h.DeleteDestination, Authorization, Admin)
+
+	e.GET("/booking", h.FetchAllBooking, Authorization, Admin)
+	e.POST("/booking", h.StoreBooking, Authorization)
+	e.GET("/booking/:customer_id", h.GetBookingById, Authorization, BookingOwner)
+	e.POST("/booking/:id/cancel", h.CancelBooking, Authorization)
+	e.GET("/booking/:id/ticket", h.GetBookingTicket, Authorization)
+	e.GET("/booking/:id/ticket.pdf", h.GetBookingTicketPDF, Authorization)
+	e.GET("/booking/:id/invoice.pdf", h.GetBookingInvoicePDF, Authorization)
+	e.POST("/booking/:id/pay", h.PayBooking, Authorization)
+	e.GET("/booking/:id/payment", h.GetBookingPayment, Authorization)
+	e.POST("/booking/:id/refund", h.RefundBooking, Authorization, Admin)
+
+	e.POST("/checkin", h.CheckIn, Authorization, GateStaff)
+
+	e.POST("/payments/webhook/:provider", h.PaymentWebhook)
+
+	// Configuration validation only allows the mock provider in dev
+	if conf.Payment.Provider == "mock" {
+		e.POST("/payments/mock/:order_id/pay", h.SimulateMockPayment, Authorization)
+	}
+
+	e.GET("/admin", h.FetchAllAdmin, Authorization, Admin)
+	e.POST("/admin", h.StoreAdmin, Authorization, Admin)
+	e.GET("/admin/:id", h.GetAdminById, Authorization, Admin)
+	e.PUT("/admin/:id", h.UpdateAdmin, Authorization, Admin)
+	e.DELETE("/admin/:id", h.DeleteAdmin, Authorization, Admin)
 
-	e.POST("/register", controllers.Register)
-	e.POST("/login", controllers.Login)
-	e.GET("/account-info", controllers.GetAccountInfo, Authorization)
-
-	e.POST("/test", controllers.Test)
-
-	e.GET("/cities", controllers.FetchAllCities, Authorization)
-	e.POST("/city", controllers.StoreCity, Authorization)
-	e.GET("/city/:id", controllers.GetCityById, Authorization)
-	e.PUT("/city/:id", controllers.UpdateCity, Authorization)
-	e.DELETE("/city/:id", controllers.DeleteCity, Authorization)
-
-	e.GET("/customers", controllers.FetchAllCustomers, Authorization)
-	e.POST("/customer", controllers.StoreCustomer, Authorization)
-	e.GET("/customer/:id", controllers.GetCustomerById, Authorization)
-	e.PUT("/customer/:id", controllers.UpdateCustomer, Authorization)
-	e.DELETE("/customer/:id", controllers.DeleteCustomer, Authorization)
+	return e
+}
 
-	e.GET("/destination", controllers.FetchAllDestination)
-	e.POST("/destination", controllers.StoreDestination)
-	e.GET("/destination/:id", controllers.GetDestinationById)
-	e.PUT("/destination/:id", controllers.UpdateDestination)
-	e.DELETE("/destination/:id", controllers.DeleteDestination)
+// ipExtractor reads client IPs, used by rate limits and audits, from
+// X-Forwarded-For only when the request comes through a trusted proxy
+func ipExtractor(trustedProxies []string) echo.IPExtractor {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
 
-	e.GET("/booking", controllers.FetchAllBooking, Authorization)
-	e.POST("/booking", controllers.StoreBooking, Authorization)
-	e.GET("/booking/:customer_id", controllers.GetBookingById, Authorization)
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
 
-	e.GET("/admin", controllers.FetchAllCustomers)
-	e.POST("/admin", controllers.StoreAdmin)
-	e.GET("/admin/:id", controllers.GetAdminById)
-	e.PUT("/admin/:id", controllers.UpdateAdmin)
-	e.DELETE("/admin/:id", controllers.DeleteAdmin)
+	for _, proxy := range trustedProxies {
+		// The ranges were validated with the configuration
+		_, ipRange, _ := net.ParseCIDR(proxy)
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
 
-	return e
+	return echo.ExtractIPFromXFFHeader(options...)
 }
@@ -0,0 +1,251 @@
+// Package search keeps an in-memory full-text index of destinations, so
+// visitors can search by name, description and city without an external
+// search engine.
+package search
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bryansamperura/ticket-booking/models"
+)
+
+// Field boosts, a match in the name counts more than one in the description
+const (
+	nameBoost        = 3.0
+	cityBoost        = 2.0
+	descriptionBoost = 1.0
+)
+
+// BM25 parameters
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// How much a query word counts depending on how it matched a term
+const (
+	exactMatch  = 1.0
+	stemMatch   = 0.9
+	prefixMatch = 0.7
+	typoMatch   = 0.6
+)
+
+// Result is a destination found by a search
+type Result struct {
+	models.DestinationDocument
+	Score float64 `json:"score"`
+}
+
+type field struct {
+	boost float64
+	text  func(models.DestinationDocument) string
+}
+
+var fields = []field{
+	{nameBoost, func(doc models.DestinationDocument) string { return doc.DestinationName }},
+	{cityBoost, func(doc models.DestinationDocument) string { return doc.CityName }},
+	{descriptionBoost, func(doc models.DestinationDocument) string { return doc.Description }},
+}
+
+// Index is an immutable inverted index, Build makes a new one
+type Index struct {
+	docs     map[int]models.DestinationDocument
+	postings map[string]map[int]float64
+	idf      map[string]float64
+}
+
+// Build indexes a set of destinations
+func Build(docs []models.DestinationDocument) *Index {
+	idx := &Index{
+		docs:     make(map[int]models.DestinationDocument, len(docs)),
+		postings: make(map[string]map[int]float64),
+		idf:      make(map[string]float64),
+	}
+
+	// Average length of every field, for BM25 length normalisation
+	avgLength := make([]float64, len(fields))
+	tokens := make([][][]string, len(docs))
+
+	for i, doc := range docs {
+		idx.docs[doc.Id] = doc
+		tokens[i] = make([][]string, len(fields))
+
+		for f, fld := range fields {
+			tokens[i][f] = tokenize(fld.text(doc))
+			avgLength[f] += float64(len(tokens[i][f]))
+		}
+	}
+
+	for f := range avgLength {
+		if len(docs) > 0 {
+			avgLength[f] /= float64(len(docs))
+		}
+		if avgLength[f] == 0 {
+			avgLength[f] = 1
+		}
+	}
+
+	for i, doc := range docs {
+		for f, fld := range fields {
+			frequency := make(map[string]int)
+			for _, word := range tokens[i][f] {
+				for _, term := range terms(word) {
+					frequency[term]++
+				}
+			}
+
+			length := float64(len(tokens[i][f]))
+
+			for term, tf := range frequency {
+				weight := float64(tf) * (k1 + 1) / (float64(tf) + k1*(1-b+b*length/avgLength[f]))
+
+				if idx.postings[term] == nil {
+					idx.postings[term] = make(map[int]float64)
+				}
+				idx.postings[term][doc.Id] += fld.boost * weight
+			}
+		}
+	}
+
+	n := float64(len(docs))
+	for term, docs := range idx.postings {
+		df := float64(len(docs))
+		idx.idf[term] = math.Log(1 + (n-df+0.5)/(df+0.5))
+	}
+
+	return idx
+}
+
+// Search ranks the destinations matching q, best first. Every query word
+// contributes its best match: the word itself, one of its stems, a term it
+// is the beginning of, or a term within a couple of typos.
+func (idx *Index) Search(q string, limit int) []Result {
+	words := tokenize(q)
+	if len(words) == 0 {
+		// The query was only stopwords, search for them anyway
+		words = strings.Fields(strings.ToLower(q))
+	}
+
+	scores := make(map[int]float64)
+	matched := make(map[int]int)
+
+	for _, word := range words {
+		best := make(map[int]float64)
+
+		for term, factor := range idx.candidates(word) {
+			for id, weight := range idx.postings[term] {
+				if score := factor * idx.idf[term] * weight; score > best[id] {
+					best[id] = score
+				}
+			}
+		}
+
+		for id, score := range best {
+			scores[id] += score
+			matched[id]++
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+
+	for id, score := range scores {
+		// Prefer destinations matching every word of the query
+		if matched[id] == len(words) && len(words) > 1 {
+			score *= 1.5
+		}
+
+		results = append(results, Result{DestinationDocument: idx.docs[id], Score: math.Round(score*1000) / 1000})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Id < results[j].Id
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results
+}
+
+// candidates returns the index terms a query word matches with the factor
+// of the match
+func (idx *Index) candidates(word string) map[string]float64 {
+	candidates := make(map[string]float64)
+
+	add := func(term string, factor float64) {
+		if _, ok := idx.postings[term]; ok && factor > candidates[term] {
+			candidates[term] = factor
+		}
+	}
+
+	for i, term := range terms(word) {
+		if i == 0 {
+			add(term, exactMatch)
+		} else {
+			add(term, stemMatch)
+		}
+	}
+
+	edits := maxEdits(word)
+
+	for term := range idx.postings {
+		if len(word) >= 3 && strings.HasPrefix(term, word) {
+			add(term, prefixMatch)
+		}
+
+		if edits > 0 && distance(word, term, edits) <= edits {
+			add(term, typoMatch)
+		}
+	}
+
+	return candidates
+}
+
+var (
+	mu      sync.RWMutex
+	current = Build(nil)
+
+	rebuildMu sync.Mutex
+)
+
+// Source provides the destinations to index
+type Source interface {
+	SearchDocuments(ctx context.Context) ([]models.DestinationDocument, error)
+}
+
+// Rebuild reloads every destination of source into the index. The previous
+// index keeps serving searches while the new one is built.
+func Rebuild(ctx context.Context, source Source) error {
+	rebuildMu.Lock()
+	defer rebuildMu.Unlock()
+
+	docs, err := source.SearchDocuments(ctx)
+	if err != nil {
+		return err
+	}
+
+	idx := Build(docs)
+
+	mu.Lock()
+	current = idx
+	mu.Unlock()
+
+	return nil
+}
+
+// Search looks q up in the current index
+func Search(q string, limit int) []Result {
+	mu.RLock()
+	idx := current
+	mu.RUnlock()
+
+	return idx.Search(q, limit)
+}
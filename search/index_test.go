@@ -0,0 +1,70 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/bryansamperura/ticket-booking/models"
+)
+
+var testDocs = []models.DestinationDocument{
+	{Id: 1, DestinationName: "Pantai Kuta", CityName: "Badung", Description: "Pantai berpasir putih untuk berselancar dan melihat matahari terbenam."},
+	{Id: 2, DestinationName: "Raja Ampat", CityName: "Sorong", Description: "Kepulauan dengan terumbu karang terbaik, surga untuk menyelam dan snorkeling."},
+	{Id: 3, DestinationName: "Gunung Bromo", CityName: "Probolinggo", Description: "Gunung berapi aktif dengan lautan pasir dan pemandangan matahari terbit."},
+	{Id: 4, DestinationName: "Taman Nasional Komodo", CityName: "Manggarai Barat", Description: "Rumah komodo dengan pantai merah muda dan spot diving kelas dunia."},
+}
+
+func ids(results []Result) []int {
+	var result []int
+
+	for _, r := range results {
+		result = append(result, r.Id)
+	}
+
+	return result
+}
+
+func TestSearchRanking(t *testing.T) {
+	idx := Build(testDocs)
+
+	tests := []struct {
+		name  string
+		query string
+		first int
+	}{
+		{name: "name beats description", query: "pantai", first: 1},
+		{name: "city", query: "sorong", first: 2},
+		{name: "stemmed Indonesian", query: "selam", first: 2},
+		{name: "stemmed English", query: "dive", first: 4},
+		{name: "prefix", query: "komo", first: 4},
+		{name: "typo", query: "bormo", first: 3},
+		{name: "every word counts", query: "pantai komodo", first: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := idx.Search(tt.query, 0)
+
+			if len(results) == 0 || results[0].Id != tt.first {
+				t.Errorf("Search(%q) = %v, want %d first", tt.query, ids(results), tt.first)
+			}
+
+			for i := 1; i < len(results); i++ {
+				if results[i].Score > results[i-1].Score {
+					t.Errorf("Search(%q) scores are not descending: %v", tt.query, results)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchNoMatchAndLimit(t *testing.T) {
+	idx := Build(testDocs)
+
+	if results := idx.Search("salju", 0); len(results) != 0 {
+		t.Errorf("Search(salju) = %v, want nothing", ids(results))
+	}
+
+	if results := idx.Search("pantai dan gunung matahari", 2); len(results) != 2 {
+		t.Errorf("Search() with limit 2 returned %d results", len(results))
+	}
+}
@@ -0,0 +1,238 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+var stopwords = map[string]bool{
+	// Indonesian
+	"yang": true, "dan": true, "di": true, "ke": true, "dari": true, "untuk": true,
+	"dengan": true, "ini": true, "itu": true, "atau": true, "pada": true, "adalah": true,
+	"ada": true, "juga": true, "dalam": true, "akan": true, "bisa": true, "oleh": true,
+	"sebagai": true, "para": true, "karena": true, "tempat": true,
+	// English
+	"the": true, "a": true, "an": true, "and": true, "or": true, "of": true,
+	"in": true, "on": true, "to": true, "for": true, "with": true, "at": true,
+	"is": true, "are": true, "by": true, "from": true, "this": true, "that": true,
+}
+
+// tokenize lowercases text and splits it into words, dropping stopwords
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := words[:0]
+	for _, word := range words {
+		if !stopwords[word] {
+			tokens = append(tokens, word)
+		}
+	}
+
+	return tokens
+}
+
+// terms returns the index terms of a word: the word itself and its
+// Indonesian and English stems. Both stemmers run on every word because
+// descriptions freely mix the two languages.
+func terms(word string) []string {
+	result := []string{word}
+
+	for _, stem := range []string{stemIndonesian(word), stemEnglish(word)} {
+		if stem != word && len(stem) >= 3 && !contains(result, stem) {
+			result = append(result, stem)
+		}
+	}
+
+	return result
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isVowel(b byte) bool {
+	return strings.IndexByte("aeiou", b) >= 0
+}
+
+// stemIndonesian strips the common inflectional particles, possessive
+// pronouns, prefixes and suffixes, roughly following Nazief-Adriani without
+// a root word dictionary
+func stemIndonesian(word string) string {
+	if len(word) <= 4 {
+		return word
+	}
+
+	for _, particle := range []string{"lah", "kah", "tah", "pun"} {
+		if strings.HasSuffix(word, particle) && len(word)-len(particle) >= 4 {
+			word = strings.TrimSuffix(word, particle)
+			break
+		}
+	}
+
+	for _, possessive := range []string{"nya", "ku", "mu"} {
+		if strings.HasSuffix(word, possessive) && len(word)-len(possessive) >= 4 {
+			word = strings.TrimSuffix(word, possessive)
+			break
+		}
+	}
+
+	stem, prefixed := stripIndonesianPrefix(word)
+	if len(stem) >= 3 {
+		word = stem
+	}
+
+	for _, suffix := range []string{"kan", "an", "i"} {
+		// -i mostly appears in me-...-i and di-...-i words, removing it
+		// from bare words would turn "pantai" into "panta"
+		if suffix == "i" && !prefixed {
+			continue
+		}
+
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= 3 {
+			word = strings.TrimSuffix(word, suffix)
+			break
+		}
+	}
+
+	return word
+}
+
+func stripIndonesianPrefix(word string) (string, bool) {
+	switch {
+	case strings.HasPrefix(word, "meny"), strings.HasPrefix(word, "peny"):
+		return "s" + word[4:], true
+	case strings.HasPrefix(word, "meng"), strings.HasPrefix(word, "peng"):
+		if len(word) > 4 && isVowel(word[4]) {
+			return "k" + word[4:], true
+		}
+		return word[4:], true
+	case strings.HasPrefix(word, "mem"), strings.HasPrefix(word, "pem"):
+		if len(word) > 3 && isVowel(word[3]) {
+			return "p" + word[3:], true
+		}
+		return word[3:], true
+	case strings.HasPrefix(word, "men"), strings.HasPrefix(word, "pen"):
+		if len(word) > 3 && isVowel(word[3]) {
+			return "t" + word[3:], true
+		}
+		return word[3:], true
+	case strings.HasPrefix(word, "me"), strings.HasPrefix(word, "pe") && !strings.HasPrefix(word, "per"):
+		return word[2:], true
+	case strings.HasPrefix(word, "ber"), strings.HasPrefix(word, "per"):
+		// be-renang, pe-rahu: keep the r when a vowel follows
+		if len(word) > 3 && isVowel(word[3]) {
+			return word[2:], true
+		}
+		return word[3:], true
+	case strings.HasPrefix(word, "ter"):
+		return word[3:], true
+	case strings.HasPrefix(word, "di"), strings.HasPrefix(word, "ke"), strings.HasPrefix(word, "se"):
+		return word[2:], true
+	}
+
+	return word, false
+}
+
+// stemEnglish removes plural and common verb and adverb suffixes
+func stemEnglish(word string) string {
+	if len(word) <= 3 {
+		return word
+	}
+
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		word = strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "sses"):
+		word = strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"), strings.HasSuffix(word, "xes"):
+		word = strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us") && !strings.HasSuffix(word, "is"):
+		word = strings.TrimSuffix(word, "s")
+	}
+
+	for _, suffix := range []string{"ing", "ed", "ly"} {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= 3 {
+			word = strings.TrimSuffix(word, suffix)
+
+			// swimming -> swim
+			if n := len(word); n >= 2 && word[n-1] == word[n-2] && !isVowel(word[n-1]) && word[n-1] != 'l' && word[n-1] != 's' {
+				word = word[:n-1]
+			}
+			break
+		}
+	}
+
+	// dive and diving both become div
+	if len(word) > 3 && strings.HasSuffix(word, "e") {
+		word = strings.TrimSuffix(word, "e")
+	}
+
+	return word
+}
+
+// maxEdits is how many typos a query word of this length may contain
+func maxEdits(word string) int {
+	switch n := len([]rune(word)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// distance returns the edit distance between a and b, counting a swap of
+// two neighbouring letters as one typo, or limit+1 once it is known to be
+// larger than limit
+func distance(a string, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+
+	if diff := len(ra) - len(rb); diff > limit || -diff > limit {
+		return limit + 1
+	}
+
+	before := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		best := curr[0]
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], before[j-2]+1)
+			}
+
+			best = min(best, curr[j])
+		}
+
+		if best > limit {
+			return limit + 1
+		}
+
+		before, prev, curr = prev, curr, before
+	}
+
+	return prev[len(rb)]
+}
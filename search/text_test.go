@@ -0,0 +1,87 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	got := tokenize("Pantai Kuta, tempat yang indah di Bali & the best beach!")
+	want := []string{"pantai", "kuta", "indah", "bali", "best", "beach"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tokenize() = %v, want %v", got, want)
+	}
+}
+
+func TestStemIndonesian(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"pantai", "pantai"},
+		{"pantainya", "pantai"},
+		{"berenang", "renang"},
+		{"menyelam", "selam"},
+		{"pemandangan", "pandang"},
+		{"mendengar", "dengar"},
+		{"keindahan", "indah"},
+		{"perahu", "rahu"},
+		{"bukit", "bukit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			if got := stemIndonesian(tt.word); got != tt.want {
+				t.Errorf("stemIndonesian(%q) = %q, want %q", tt.word, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStemEnglish(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"beaches", "beach"},
+		{"islands", "island"},
+		{"activities", "activity"},
+		{"swimming", "swim"},
+		{"diving", "div"},
+		{"dive", "div"},
+		{"snorkeling", "snorkel"},
+		{"glass", "glass"},
+		{"sea", "sea"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			if got := stemEnglish(tt.word); got != tt.want {
+				t.Errorf("stemEnglish(%q) = %q, want %q", tt.word, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		limit int
+		want  int
+	}{
+		{"ampat", "ampat", 1, 0},
+		{"ampat", "ampta", 1, 1},
+		{"ampat", "amat", 1, 1},
+		{"ampat", "empat", 1, 1},
+		{"bromo", "brmoo", 2, 1},
+		{"komodo", "kmd", 2, 3},
+		{"kuta", "bali", 1, 2},
+	}
+
+	for _, tt := range tests {
+		if got := distance(tt.a, tt.b, tt.limit); got != tt.want {
+			t.Errorf("distance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.limit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/bryansamperura/ticket-booking/config"
+	"github.com/bryansamperura/ticket-booking/db"
+	"github.com/bryansamperura/ticket-booking/repository"
+	"github.com/bryansamperura/ticket-booking/seed"
+)
+
+// runSeed runs the seed sub-command and returns the exit code
+func runSeed(args []string) int {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	dir := flags.String("dir", "fixtures", "directory with the fixture files")
+	uploads := flags.String("uploads", config.GetConfig().Uploads.Dir, "directory destination images are copied into")
+	reset := flags.Bool("reset", false, "delete every row of the database before seeding")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	ctx := context.Background()
+
+	fixtures, err := seed.Load(*dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	con, err := db.Open()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	defer con.Close()
+
+	if err = db.CheckSchema(ctx, con); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if *reset {
+		if err = db.Truncate(ctx, con); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	report, err := seed.Run(ctx, repository.NewMySQL(con), fixtures, *uploads)
+
+	for _, kind := range []string{"cities", "destinations", "admins", "customers", "bookings"} {
+		fmt.Printf("%-13s %d created, %d already there\n", kind, report.Created[kind], report.Skipped[kind])
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
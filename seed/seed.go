@@ -0,0 +1,474 @@
+// Package seed loads the fixture files in fixtures/ into a database so a
+// fresh install has cities, destinations, accounts and bookings to work
+// with. Seeding is idempotent: rows that already exist are left alone.
+package seed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bryansamperura/ticket-booking/middlewares"
+	"github.com/bryansamperura/ticket-booking/models"
+	"github.com/bryansamperura/ticket-booking/repository"
+	"github.com/bryansamperura/ticket-booking/validation"
+	"github.com/ghodss/yaml"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type City struct {
+	CityName string `json:"city"`
+}
+
+type Destination struct {
+	DestinationName string `json:"destination_name"`
+	City            string `json:"city"`
+	Description     string `json:"description"`
+	Price           int    `json:"price"`
+	DailyQuota      *int   `json:"daily_quota"`
+	// Image is a file in the images directory next to the fixtures
+	Image string `json:"image"`
+}
+
+type Account struct {
+	FullName string `json:"fullname"`
+	Email    string `json:"email"`
+	Phone    string `json:"phone"`
+	Password string `json:"password"`
+	// Role is admin or staff for back office accounts, ignored for customers
+	Role string `json:"role"`
+}
+
+type Booking struct {
+	// Customer and Destination are the customer's email and the
+	// destination's name
+	Customer      string `json:"customer"`
+	Destination   string `json:"destination"`
+	Qty           int    `json:"qty"`
+	DaysFromToday int    `json:"days_from_today"`
+	Status        string `json:"status"`
+}
+
+// Fixtures is the content of a fixtures directory
+type Fixtures struct {
+	Cities       []City
+	Destinations []Destination
+	Admins       []Account
+	Customers    []Account
+	Bookings     []Booking
+
+	dir string
+}
+
+// Report counts the rows a seed created and the ones that already existed
+type Report struct {
+	Created map[string]int
+	Skipped map[string]int
+}
+
+// Load reads the fixtures in dir. Every kind lives in its own file, e.g.
+// cities.yaml, cities.yml or cities.json, and may be left out.
+func Load(dir string) (Fixtures, error) {
+	fixtures := Fixtures{dir: dir}
+
+	files := []struct {
+		name   string
+		target interface{}
+	}{
+		{"cities", &fixtures.Cities},
+		{"destinations", &fixtures.Destinations},
+		{"admins", &fixtures.Admins},
+		{"customers", &fixtures.Customers},
+		{"bookings", &fixtures.Bookings},
+	}
+
+	for _, file := range files {
+		if err := loadFile(dir, file.name, file.target); err != nil {
+			return fixtures, err
+		}
+	}
+
+	return fixtures, nil
+}
+
+func loadFile(dir string, name string, target interface{}) error {
+	for _, ext := range []string{".yaml", ".yml", ".json"} {
+		path := filepath.Join(dir, name+ext)
+
+		content, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		// YAML is converted to JSON first so both formats use the json tags
+		if ext != ".json" {
+			content, err = yaml.YAMLToJSON(content)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+		}
+
+		if err = json.Unmarshal(content, target); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		return nil
+	}
+
+	return nil
+}
+
+// Run inserts the fixtures missing from repos. Destination images are copied
+// into uploadsDir.
+func Run(ctx context.Context, repos repository.Repositories, fixtures Fixtures, uploadsDir string) (Report, error) {
+	s := &seeder{
+		repos:        repos,
+		fixtures:     fixtures,
+		uploadsDir:   uploadsDir,
+		cities:       make(map[string]int),
+		destinations: make(map[string]int),
+		report:       Report{Created: make(map[string]int), Skipped: make(map[string]int)},
+	}
+
+	steps := []func(context.Context) error{
+		s.seedCities,
+		s.seedDestinations,
+		s.seedAdmins,
+		s.seedCustomers,
+		s.seedBookings,
+	}
+
+	for _, step := range steps {
+		if err := step(ctx); err != nil {
+			return s.report, err
+		}
+	}
+
+	return s.report, nil
+}
+
+type seeder struct {
+	repos      repository.Repositories
+	fixtures   Fixtures
+	uploadsDir string
+
+	// ids of the seeded rows by name
+	cities       map[string]int
+	destinations map[string]int
+
+	report Report
+}
+
+func (s *seeder) seedCities(ctx context.Context) error {
+	for _, city := range s.fixtures.Cities {
+		id, err := findCity(ctx, s.repos.Cities, city.CityName)
+		if err != nil {
+			return err
+		}
+
+		if id != 0 {
+			s.report.Skipped["cities"]++
+		} else {
+			id, err = s.repos.Cities.Create(ctx, city.CityName)
+			if err != nil {
+				return fmt.Errorf("city %s: %w", city.CityName, err)
+			}
+
+			s.report.Created["cities"]++
+		}
+
+		s.cities[city.CityName] = id
+	}
+
+	return nil
+}
+
+func (s *seeder) seedDestinations(ctx context.Context) error {
+	for _, destination := range s.fixtures.Destinations {
+		id, err := findDestination(ctx, s.repos.Destinations, destination.DestinationName)
+		if err != nil {
+			return err
+		}
+
+		if id != 0 {
+			s.destinations[destination.DestinationName] = id
+			s.report.Skipped["destinations"]++
+			continue
+		}
+
+		cityID, ok := s.cities[destination.City]
+		if !ok {
+			return fmt.Errorf("destination %s: city %s is not in the city fixtures", destination.DestinationName, destination.City)
+		}
+
+		image := ""
+
+		if destination.Image != "" {
+			image, err = s.copyImage(destination.Image)
+			if err != nil {
+				return fmt.Errorf("destination %s: %w", destination.DestinationName, err)
+			}
+		}
+
+		id, err = s.repos.Destinations.Create(ctx, models.Destination{
+			DestinationName: destination.DestinationName,
+			Image:           image,
+			City:            strconv.Itoa(cityID),
+			Description:     destination.Description,
+			Price:           destination.Price,
+			DailyQuota:      destination.DailyQuota,
+		})
+		if err != nil {
+			return fmt.Errorf("destination %s: %w", destination.DestinationName, err)
+		}
+
+		s.destinations[destination.DestinationName] = id
+		s.report.Created["destinations"]++
+	}
+
+	return nil
+}
+
+// copyImage copies a fixture image into the uploads directory and returns
+// its path as stored on destinations. Seeded images keep a fixed name so
+// seeding again does not pile up copies.
+func (s *seeder) copyImage(name string) (string, error) {
+	src, err := os.Open(filepath.Join(s.fixtures.dir, "images", name))
+	if err != nil {
+		return "", err
+	}
+
+	defer src.Close()
+
+	if err = os.MkdirAll(s.uploadsDir, 0755); err != nil {
+		return "", err
+	}
+
+	dst, err := os.Create(filepath.Join(s.uploadsDir, "seed-"+name))
+	if err != nil {
+		return "", err
+	}
+
+	if _, err = io.Copy(dst, src); err != nil {
+		dst.Close()
+		return "", err
+	}
+
+	return "uploads/seed-" + name, dst.Close()
+}
+
+func (s *seeder) seedAdmins(ctx context.Context) error {
+	for _, admin := range s.fixtures.Admins {
+		role := admin.Role
+		if role != middlewares.RoleStaff {
+			role = middlewares.RoleAdmin
+		}
+
+		err := s.createAccount(ctx, "admins", admin, func(passwordHash string) error {
+			request := models.AdminRequest{FullName: admin.FullName, Email: admin.Email, Phone: admin.Phone}
+
+			user, err := s.repos.Users.CreateAdmin(ctx, request, passwordHash, role)
+			if err != nil {
+				return err
+			}
+
+			// Fixture emails are not real, they could never be verified
+			return s.repos.Users.MarkEmailVerified(ctx, user.Id)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *seeder) seedCustomers(ctx context.Context) error {
+	for _, customer := range s.fixtures.Customers {
+		err := s.createAccount(ctx, "customers", customer, func(passwordHash string) error {
+			request := models.CustomerRequest{FullName: customer.FullName, Email: customer.Email, Phone: customer.Phone}
+
+			user, err := s.repos.Users.CreateCustomer(ctx, request, passwordHash)
+			if err != nil {
+				return err
+			}
+
+			return s.repos.Users.MarkEmailVerified(ctx, user.Id)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createAccount calls create with the hashed password unless a login account
+// with the email already exists
+func (s *seeder) createAccount(ctx context.Context, kind string, account Account, create func(passwordHash string) error) error {
+	_, err := s.repos.Users.FindByEmail(ctx, account.Email)
+	if err == nil {
+		s.report.Skipped[kind]++
+		return nil
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+
+	if account.Password == "" {
+		return fmt.Errorf("account %s has no password", account.Email)
+	}
+
+	hashPassword, err := bcrypt.GenerateFromPassword([]byte(account.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if err = create(string(hashPassword)); err != nil {
+		return fmt.Errorf("account %s: %w", account.Email, err)
+	}
+
+	s.report.Created[kind]++
+
+	return nil
+}
+
+// bookingSteps are the transitions taking a new booking to each state
+var bookingSteps = map[string][]string{
+	models.BookingPending:   nil,
+	models.BookingConfirmed: {models.BookingConfirmed},
+	models.BookingCheckedIn: {models.BookingConfirmed, models.BookingCheckedIn},
+	models.BookingCancelled: {models.BookingCancelled},
+	models.BookingExpired:   {models.BookingExpired},
+	models.BookingRefunded:  {models.BookingConfirmed, models.BookingRefunded},
+}
+
+func (s *seeder) seedBookings(ctx context.Context) error {
+	policy := models.DefaultBookingPolicy()
+
+	for _, booking := range s.fixtures.Bookings {
+		status := booking.Status
+		if status == "" {
+			status = models.BookingPending
+		}
+
+		steps, ok := bookingSteps[status]
+		if !ok {
+			return fmt.Errorf("booking of %s at %s: unknown status %s", booking.Customer, booking.Destination, status)
+		}
+
+		user, err := s.repos.Users.FindByEmail(ctx, booking.Customer)
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("booking of %s: customer is not in the customer fixtures", booking.Customer)
+		} else if err != nil {
+			return err
+		}
+
+		customerID, err := strconv.Atoi(user.AccountID)
+		if err != nil {
+			return err
+		}
+
+		destinationID, ok := s.destinations[booking.Destination]
+		if !ok {
+			return fmt.Errorf("booking of %s: destination %s is not in the destination fixtures", booking.Customer, booking.Destination)
+		}
+
+		existing, err := s.repos.Bookings.ListByCustomer(ctx, customerID)
+		if err != nil {
+			return err
+		}
+
+		if hasBooking(existing, destinationID) {
+			s.report.Skipped["bookings"]++
+			continue
+		}
+
+		request := models.BookingRequest{
+			CustomerID:     customerID,
+			Qty:            booking.Qty,
+			DestinationID:  destinationID,
+			TanggalBooking: models.Today().AddDate(0, 0, booking.DaysFromToday).Format(models.DateLayout),
+		}
+
+		if err = validation.Default().Validate(request); err != nil {
+			return fmt.Errorf("booking of %s at %s: %w", booking.Customer, booking.Destination, err)
+		}
+
+		id, err := s.repos.Bookings.Create(ctx, request, policy)
+		if err != nil {
+			return fmt.Errorf("booking of %s at %s: %w", booking.Customer, booking.Destination, err)
+		}
+
+		for _, step := range steps {
+			if err = s.repos.Bookings.Transition(ctx, id, step); err != nil {
+				return fmt.Errorf("booking %d: %w", id, err)
+			}
+		}
+
+		s.report.Created["bookings"]++
+	}
+
+	return nil
+}
+
+func hasBooking(bookings []models.Booking, destinationID int) bool {
+	for _, booking := range bookings {
+		if booking.DestinationID == destinationID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findCity returns the id of the city with exactly the given name, or 0
+func findCity(ctx context.Context, cities repository.CityRepository, name string) (int, error) {
+	opts, err := models.ParseListOptions(url.Values{"city": {name}, "per_page": {strconv.Itoa(models.MaxPerPage)}}, models.CityListSpec)
+	if err != nil {
+		return 0, err
+	}
+
+	page, err := cities.List(ctx, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, city := range page.Items {
+		if strings.EqualFold(city.CityName, name) {
+			return city.Id, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// findDestination returns the id of the destination with exactly the given
+// name, or 0
+func findDestination(ctx context.Context, destinations repository.DestinationRepository, name string) (int, error) {
+	opts, err := models.ParseListOptions(url.Values{"destination_name": {name}, "per_page": {strconv.Itoa(models.MaxPerPage)}}, models.DestinationListSpec)
+	if err != nil {
+		return 0, err
+	}
+
+	page, err := destinations.List(ctx, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, destination := range page.Items {
+		if strings.EqualFold(destination.DestinationName, name) {
+			return destination.Id, nil
+		}
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,85 @@
+// Package tickets issues and verifies the codes printed on e-tickets
+package tickets
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bryansamperura/ticket-booking/apperror"
+	"github.com/bryansamperura/ticket-booking/config"
+	"github.com/bryansamperura/ticket-booking/helper"
+	"github.com/skip2/go-qrcode"
+)
+
+// codePrefix versions the code format so it can change without confusing
+// scanners reading older tickets
+const codePrefix = "PW1"
+
+// qrSize is the width and height of ticket QR codes in pixels
+const qrSize = 512
+
+var ErrInvalidCode = apperror.Validation("invalid_ticket_code", "invalid ticket code")
+
+var signingKey []byte
+
+func Init() {
+	conf := config.GetConfig()
+
+	if len(conf.Tickets.SigningKey) < 32 {
+		helper.PanicIfError(errors.New("tickets.signing_key must be at least 32 bytes"))
+	}
+
+	signingKey = []byte(conf.Tickets.SigningKey)
+}
+
+// Code returns the tamper-proof code of one seat of a booking. Seats are
+// numbered from 1 to the booking's qty.
+func Code(bookingID int, seat int) string {
+	payload := fmt.Sprintf("%s.%d.%d", codePrefix, bookingID, seat)
+
+	return payload + "." + sign(payload)
+}
+
+// Verify checks the signature of a scanned code and returns the booking and
+// seat it was issued for
+func Verify(code string) (int, int, error) {
+	parts := strings.Split(strings.TrimSpace(code), ".")
+	if len(parts) != 4 || parts[0] != codePrefix {
+		return 0, 0, ErrInvalidCode
+	}
+
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(sign(payload)), []byte(parts[3])) {
+		return 0, 0, ErrInvalidCode
+	}
+
+	bookingID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, ErrInvalidCode
+	}
+
+	seat, err := strconv.Atoi(parts[2])
+	if err != nil || seat < 1 {
+		return 0, 0, ErrInvalidCode
+	}
+
+	return bookingID, seat, nil
+}
+
+// QRCode renders the code of a seat as a PNG image
+func QRCode(bookingID int, seat int) ([]byte, error) {
+	return qrcode.Encode(Code(bookingID, seat), qrcode.Medium, qrSize)
+}
+
+func sign(payload string) string {
+	mac := hmac.New(sha256.New, signingKey)
+	mac.Write([]byte(payload))
+
+	// 16 bytes of HMAC keep the QR code small while staying unguessable
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
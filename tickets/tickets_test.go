@@ -0,0 +1,54 @@
+package tickets
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCodeVerify(t *testing.T) {
+	signingKey = []byte("0123456789abcdef0123456789abcdef")
+
+	valid := Code(42, 3)
+
+	tests := []struct {
+		name        string
+		code        string
+		wantBooking int
+		wantSeat    int
+		wantErr     error
+	}{
+		{name: "issued code", code: valid, wantBooking: 42, wantSeat: 3},
+		{name: "surrounding whitespace", code: " " + valid + "\n", wantBooking: 42, wantSeat: 3},
+		{name: "other seat", code: strings.Replace(valid, ".42.3.", ".42.4.", 1), wantErr: ErrInvalidCode},
+		{name: "other booking", code: strings.Replace(valid, ".42.3.", ".43.3.", 1), wantErr: ErrInvalidCode},
+		{name: "unknown version", code: strings.Replace(valid, codePrefix, "PW2", 1), wantErr: ErrInvalidCode},
+		{name: "missing signature", code: "PW1.42.3", wantErr: ErrInvalidCode},
+		{name: "seat 0", code: Code(42, 0), wantErr: ErrInvalidCode},
+		{name: "empty", code: "", wantErr: ErrInvalidCode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bookingID, seat, err := Verify(tt.code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if bookingID != tt.wantBooking || seat != tt.wantSeat {
+				t.Errorf("Verify() = %d, %d, want %d, %d", bookingID, seat, tt.wantBooking, tt.wantSeat)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsCodesOfAnotherKey(t *testing.T) {
+	signingKey = []byte("0123456789abcdef0123456789abcdef")
+	code := Code(42, 1)
+
+	signingKey = []byte("fedcba9876543210fedcba9876543210")
+
+	if _, _, err := Verify(code); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("Verify() error = %v, want %v", err, ErrInvalidCode)
+	}
+}
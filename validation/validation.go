@@ -0,0 +1,227 @@
+// Package validation checks request DTOs against their `validate` struct
+// tags. One shared Validator is registered as the echo.Validator, handlers
+// call c.Validate after binding, and the field errors it reports are
+// translated into Indonesian or English when the response is written.
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/apperror"
+	"github.com/bryansamperura/ticket-booking/auth"
+	"github.com/bryansamperura/ticket-booking/models"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	id_translations "github.com/go-playground/validator/v10/translations/id"
+)
+
+// Languages error messages are available in, the first one is the default
+const (
+	English    = "en"
+	Indonesian = "id"
+)
+
+// ErrInvalid is returned, wrapping the field errors, when a request does not
+// pass validation
+var ErrInvalid = apperror.Validation("validation_failed", "The request has invalid fields")
+
+// phonePattern is an Indonesian number in E.164 form, e.g. +6281234567890
+var phonePattern = regexp.MustCompile(`^\+62[1-9][0-9]{7,12}$`)
+
+// Validator is the echo.Validator of the service
+type Validator struct {
+	validate    *validator.Validate
+	translators map[string]ut.Translator
+}
+
+var shared = newValidator()
+
+// Default returns the shared validator
+func Default() *Validator {
+	return shared
+}
+
+func newValidator() *Validator {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+
+	// Report fields by the names clients send
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form", "query"} {
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			if name != "" && name != "-" {
+				return name
+			}
+		}
+
+		return field.Name
+	})
+
+	validate.RegisterValidation("phone_id", func(fl validator.FieldLevel) bool {
+		return phonePattern.MatchString(fl.Field().String())
+	})
+
+	validate.RegisterValidation("date", func(fl validator.FieldLevel) bool {
+		_, err := time.Parse(models.DateLayout, fl.Field().String())
+		return err == nil
+	})
+
+	// not_past accepts today and later, malformed dates are left to date
+	validate.RegisterValidation("not_past", func(fl validator.FieldLevel) bool {
+		date, err := time.Parse(models.DateLayout, fl.Field().String())
+		return err != nil || !date.Before(models.Today())
+	})
+
+	validate.RegisterValidation("not_breached", func(fl validator.FieldLevel) bool {
+		return !auth.IsBreachedPassword(fl.Field().String())
+	})
+
+	universal := ut.New(en.New(), en.New(), id.New())
+
+	english, _ := universal.GetTranslator(English)
+	indonesian, _ := universal.GetTranslator(Indonesian)
+
+	en_translations.RegisterDefaultTranslations(validate, english)
+	id_translations.RegisterDefaultTranslations(validate, indonesian)
+
+	messages := map[string]map[ut.Translator]string{
+		"phone_id": {
+			english:    "{0} must be an Indonesian phone number in international format, e.g. +6281234567890",
+			indonesian: "{0} harus berupa nomor telepon Indonesia dalam format internasional, mis. +6281234567890",
+		},
+		"date": {
+			english:    "{0} must be a date in the YYYY-MM-DD format",
+			indonesian: "{0} harus berupa tanggal dengan format YYYY-MM-DD",
+		},
+		"not_breached": {
+			english:    "{0} is too common or has appeared in a data breach, choose another one",
+			indonesian: "{0} terlalu umum atau pernah bocor dalam pembobolan data, pilih yang lain",
+		},
+		"not_past": {
+			english:    "{0} must not be in the past",
+			indonesian: "{0} tidak boleh tanggal yang sudah lewat",
+		},
+	}
+
+	for tag, translations := range messages {
+		for translator, message := range translations {
+			validate.RegisterTranslation(tag, translator, registerMessage(tag, message), translateField)
+		}
+	}
+
+	return &Validator{
+		validate:    validate,
+		translators: map[string]ut.Translator{English: english, Indonesian: indonesian},
+	}
+}
+
+func registerMessage(tag string, message string) validator.RegisterTranslationsFunc {
+	return func(translator ut.Translator) error {
+		return translator.Add(tag, message, true)
+	}
+}
+
+func translateField(translator ut.Translator, fieldErr validator.FieldError) string {
+	message, err := translator.T(fieldErr.Tag(), fieldErr.Field())
+	if err != nil {
+		return fieldErr.Error()
+	}
+
+	return message
+}
+
+// Validate implements echo.Validator. The returned error wraps ErrInvalid,
+// its fields are in English until the response is translated by Localize.
+func (v *Validator) Validate(i interface{}) error {
+	return v.invalid(v.validate.Struct(i))
+}
+
+// ValidateExcept validates i without the named struct fields, e.g. when an
+// update request leaves out the password
+func (v *Validator) ValidateExcept(i interface{}, fields ...string) error {
+	return v.invalid(v.validate.StructExcept(i, fields...))
+}
+
+func (v *Validator) invalid(err error) error {
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return err
+	}
+
+	invalid := ErrInvalid.Wrap(fieldErrs)
+	invalid.Fields = v.fields(fieldErrs, English)
+
+	return invalid
+}
+
+// Localize returns the field errors of err in the language preferred by an
+// Accept-Language header, or false when err is not a validation error
+func (v *Validator) Localize(err error, acceptLanguage string) (map[string]string, bool) {
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return nil, false
+	}
+
+	return v.fields(fieldErrs, Language(acceptLanguage)), true
+}
+
+func (v *Validator) fields(fieldErrs validator.ValidationErrors, language string) map[string]string {
+	translator := v.translators[language]
+	fields := make(map[string]string, len(fieldErrs))
+
+	for _, fieldErr := range fieldErrs {
+		message := fieldErr.Translate(translator)
+
+		// Tags without a translation come back as the raw error
+		if strings.HasPrefix(message, "Key: ") {
+			message = fallback(fieldErr, language)
+		}
+
+		fields[fieldPath(fieldErr)] = message
+	}
+
+	return fields
+}
+
+// fieldPath is the dotted path of a field without the struct name, e.g.
+// "booking_date"
+func fieldPath(fieldErr validator.FieldError) string {
+	_, path, found := strings.Cut(fieldErr.Namespace(), ".")
+	if !found {
+		return fieldErr.Field()
+	}
+
+	return path
+}
+
+func fallback(fieldErr validator.FieldError, language string) string {
+	if language == Indonesian {
+		return fieldErr.Field() + " tidak valid"
+	}
+
+	return fieldErr.Field() + " is invalid"
+}
+
+// Language picks Indonesian or English from an Accept-Language header,
+// English when neither is asked for
+func Language(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		base, _, _ := strings.Cut(strings.ToLower(tag), "-")
+
+		switch base {
+		case Indonesian, "in":
+			return Indonesian
+		case English:
+			return English
+		}
+	}
+
+	return English
+}
@@ -0,0 +1,194 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/apperror"
+	"github.com/bryansamperura/ticket-booking/models"
+)
+
+type testVisitor struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type testRequest struct {
+	Email       string        `json:"email" validate:"required,email"`
+	Phone       string        `json:"phone" validate:"phone_id"`
+	Password    string        `json:"password" validate:"not_breached"`
+	BookingDate string        `json:"booking_date" validate:"date,not_past"`
+	Code        string        `query:"code" validate:"uuid4"`
+	Visitors    []testVisitor `json:"visitors" validate:"dive"`
+}
+
+func validRequest() testRequest {
+	return testRequest{
+		Email:       "budi@example.com",
+		Phone:       "+6281234567890",
+		Password:    "kopi-tubruk-di-jayapura",
+		BookingDate: models.Today().AddDate(0, 0, 1).Format(models.DateLayout),
+		Code:        "0b0f3a8e-8d1c-4a4b-9a57-7b1f0f7d2c11",
+		Visitors:    []testVisitor{{Name: "Budi"}},
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		want           string
+	}{
+		{acceptLanguage: "", want: English},
+		{acceptLanguage: "id", want: Indonesian},
+		{acceptLanguage: "id-ID,id;q=0.9,en;q=0.8", want: Indonesian},
+		{acceptLanguage: "in", want: Indonesian},
+		{acceptLanguage: "en-US,en;q=0.9", want: English},
+		{acceptLanguage: "fr-FR, ID;q=0.5", want: Indonesian},
+		{acceptLanguage: "fr-FR,de", want: English},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			if got := Language(tt.acceptLanguage); got != tt.want {
+				t.Errorf("Language(%q) = %q, want %q", tt.acceptLanguage, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Default().Validate(validRequest()); err != nil {
+		t.Fatalf("Validate() = %v, want nil", err)
+	}
+
+	request := validRequest()
+	request.Email = ""
+	request.Phone = "081234567890"
+	request.Password = "password"
+	request.BookingDate = models.Today().AddDate(0, 0, -1).Format(models.DateLayout)
+	request.Code = "not-a-uuid"
+	request.Visitors = []testVisitor{{Name: "Budi"}, {}}
+
+	err := Default().Validate(request)
+	if !errors.Is(err, ErrInvalid) {
+		t.Fatalf("Validate() error = %v, want %v", err, ErrInvalid)
+	}
+
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) {
+		t.Fatalf("Validate() error = %T, want *apperror.Error", err)
+	}
+
+	want := map[string]string{
+		"email":            "email is a required field",
+		"phone":            "phone must be an Indonesian phone number in international format, e.g. +6281234567890",
+		"password":         "password is too common or has appeared in a data breach, choose another one",
+		"booking_date":     "booking_date must not be in the past",
+		"code":             "code must be a valid version 4 UUID",
+		"visitors[1].name": "name is a required field",
+	}
+
+	if len(appErr.Fields) != len(want) {
+		t.Errorf("Fields = %v, want %v", appErr.Fields, want)
+	}
+
+	for field, message := range want {
+		if appErr.Fields[field] != message {
+			t.Errorf("Fields[%q] = %q, want %q", field, appErr.Fields[field], message)
+		}
+	}
+}
+
+func TestValidateExcept(t *testing.T) {
+	request := validRequest()
+	request.Password = "password"
+
+	if err := Default().ValidateExcept(request, "Password"); err != nil {
+		t.Errorf("ValidateExcept() = %v, want nil", err)
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	request := validRequest()
+	request.Phone = "081234567890"
+	request.BookingDate = "24-12-2026"
+	request.Visitors = []testVisitor{{}}
+
+	err := Default().Validate(request)
+
+	tests := []struct {
+		acceptLanguage string
+		want           map[string]string
+	}{
+		{
+			acceptLanguage: "id-ID",
+			want: map[string]string{
+				"phone":            "phone harus berupa nomor telepon Indonesia dalam format internasional, mis. +6281234567890",
+				"booking_date":     "booking_date harus berupa tanggal dengan format YYYY-MM-DD",
+				"visitors[0].name": "name wajib diisi",
+			},
+		},
+		{
+			acceptLanguage: "en",
+			want: map[string]string{
+				"phone":            "phone must be an Indonesian phone number in international format, e.g. +6281234567890",
+				"booking_date":     "booking_date must be a date in the YYYY-MM-DD format",
+				"visitors[0].name": "name is a required field",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			fields, ok := Default().Localize(err, tt.acceptLanguage)
+			if !ok {
+				t.Fatalf("Localize(%v) is not a validation error", err)
+			}
+
+			if len(fields) != len(tt.want) {
+				t.Errorf("Localize() = %v, want %v", fields, tt.want)
+			}
+
+			for field, message := range tt.want {
+				if fields[field] != message {
+					t.Errorf("Localize()[%q] = %q, want %q", field, fields[field], message)
+				}
+			}
+		})
+	}
+
+	if _, ok := Default().Localize(errors.New("boom"), "id"); ok {
+		t.Error("Localize() of a plain error = true, want false")
+	}
+}
+
+func TestNotPastAcceptsToday(t *testing.T) {
+	request := validRequest()
+	request.BookingDate = models.Today().Format(models.DateLayout)
+
+	if err := Default().Validate(request); err != nil {
+		t.Errorf("Validate() with today = %v, want nil", err)
+	}
+
+	request.BookingDate = models.Today().Add(-time.Nanosecond).Format(models.DateLayout)
+
+	if err := Default().Validate(request); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Validate() with yesterday = %v, want %v", err, ErrInvalid)
+	}
+}
+
+func TestUntranslatedTagFallsBack(t *testing.T) {
+	type proxyRequest struct {
+		Upstream string `json:"upstream" validate:"hostname_port"`
+	}
+
+	err := Default().Validate(proxyRequest{Upstream: "not a host"})
+
+	for language, want := range map[string]string{"en": "upstream is invalid", "id": "upstream tidak valid"} {
+		fields, _ := Default().Localize(err, language)
+
+		if fields["upstream"] != want {
+			t.Errorf("Localize(%q)[upstream] = %q, want %q", language, fields["upstream"], want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Package workers contains background jobs that run next to the HTTP server
+package workers
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/bryansamperura/ticket-booking/repository"
+)
+
+// expiryBatchSize is the number of bookings expired per query, so a large
+// backlog is worked off without holding one long transaction
+const expiryBatchSize = 100
+
+// StartBookingExpiry expires unpaid booking holds every interval until ctx
+// is cancelled. The returned channel is closed once the worker has stopped.
+func StartBookingExpiry(ctx context.Context, bookings repository.BookingRepository, interval time.Duration) <-chan struct{} {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				expireBookings(ctx, bookings)
+			}
+		}
+	}()
+
+	return done
+}
+
+func expireBookings(ctx context.Context, bookings repository.BookingRepository) {
+	for ctx.Err() == nil {
+		expired, err := bookings.Expire(ctx, expiryBatchSize)
+		if err != nil {
+			slog.Error("booking expiry failed", slog.Any("error", err))
+			return
+		}
+
+		if expired > 0 {
+			slog.Info("booking expiry", slog.Int("expired", expired))
+		}
+
+		if expired < expiryBatchSize {
+			return
+		}
+	}
+}